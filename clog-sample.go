@@ -32,16 +32,24 @@ func main() {
 	// create a list of embedded file systems to init the system
 	configEmbedFsList := &[]embed.FS{CoreFs, AppFs}
 
-	//Overlay all configs by searching the `embed` File Systems
-	config.New(configEmbedFsList, nil)
+	//choose a profile for this clog and any clog that it runs
+	if profile := cmd.ProfileFlag(os.Args[1:]); profile != nil {
+		os.Setenv(config.ProfileEnv, *profile)
+	}
+
+	//Overlay all configs by searching the `embed` File Systems and the
+	//search paths then the --config file (flags are parsed later by cobra)
+	config.New(configEmbedFsList, cmd.ConfigFlag(os.Args[1:]))
 
 	// BootStrap clog with the RootCommand
 	// load commands, snippets, scripts & update config as needed
 	err := cmd.BootStrap(cmd.RootCommand)
 	if err != nil {
 		slog.Error(err.Error())
-		os.Exit(1)
+		slogger.Exit(1)
 	}
+	// flush the log sinks (e.g. a partial otlp batch) before clog ends
+	slogger.CloseSinks()
 }
 
 // if you want to log the `init()` order for this application then set the
@@ -12,11 +12,13 @@ package cmd
 import (
 	"embed"
 	"log/slog"
+	"os"
 	"runtime"
 
 	"github.com/mrmxf/clog/cmd/aws"
 	"github.com/mrmxf/clog/cmd/cat"
 	"github.com/mrmxf/clog/cmd/check"
+	"github.com/mrmxf/clog/cmd/configcmd"
 	"github.com/mrmxf/clog/cmd/copy"
 	"github.com/mrmxf/clog/cmd/crayon"
 	"github.com/mrmxf/clog/cmd/inc"
@@ -24,6 +26,9 @@ import (
 	"github.com/mrmxf/clog/cmd/jumbo"
 	"github.com/mrmxf/clog/cmd/list"
 	"github.com/mrmxf/clog/cmd/logcmd"
+	"github.com/mrmxf/clog/cmd/notify"
+	"github.com/mrmxf/clog/cmd/progress"
+	"github.com/mrmxf/clog/cmd/secret"
 	"github.com/mrmxf/clog/cmd/should"
 	"github.com/mrmxf/clog/cmd/snippets"
 	"github.com/mrmxf/clog/cmd/source"
@@ -31,15 +36,37 @@ import (
 	"github.com/mrmxf/clog/config"
 	"github.com/mrmxf/clog/scripts"
 	"github.com/mrmxf/clog/semver"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/mrmxf/clog/ux"
 	"github.com/spf13/cobra"
 )
 
 const clogEmbeddedReleasesFile = "releases.yaml"
 
+// BootStrap configures clog, adds the commands to bootCmd and executes it.
+// When it returns, call slogger.CloseSinks (or slogger.Exit) so that buffered
+// log records are not lost.
 func BootStrap(bootCmd *cobra.Command) error {
 	cfg := config.Cfg()
 
+	// join the run of a parent clog (or start one) so that the records of
+	// nested clog processes can be correlated
+	slogger.StartRun(bootCmd.CommandPath())
+	slogger.SetRunProfile(config.Profile())
+	// tell nested clogs if their output ends up on a terminal
+	slogger.ExportTTY()
+
+	// mask secrets in all output, create the notifier for notify sinks then
+	// use the logger level & style from the config
+	configureRedaction(cfg)
+	configureNotify(cfg)
+	configureLogger(cfg)
+	config.Subscribe("clog.log", reloadLevels)
+	cobra.OnInitialize(applyLogLevelFlag, validateStrict)
+	if cfg.GetString(LogCIAnnotationsKey) != "false" {
+		slogger.UseCIAnnotations()
+	}
+
 	// find the embedded release history file in the embedded file systems
 	// last one found wins - this is usually the project's embedded fs
 	eFs, paths, err := config.FindEmbedded(clogEmbeddedReleasesFile)
@@ -51,12 +78,13 @@ func BootStrap(bootCmd *cobra.Command) error {
 	}
 
 	// prepend cobra usage strings with build information
-	bootCmd.SetUsageTemplate(cfg.GetString("clog.version.long") + bootCmd.UsageTemplate())
+	bootCmd.SetUsageTemplate(cfg.GetString("clog.version.long") + config.ProfileTag() + bootCmd.UsageTemplate())
 
 	// load all the public builtin commands first
 	bootCmd.AddCommand(aws.Command)        // aws day-to-day management commands
 	bootCmd.AddCommand(cat.Command)        // script helper include command
 	bootCmd.AddCommand(check.Command)      // copy an embedded file to a destination
+	bootCmd.AddCommand(configcmd.Command)  // show the config & where values came from
 	bootCmd.AddCommand(copy.Command)       // copy an embedded file to a destination
 	bootCmd.AddCommand(crayon.Command)     // colored terminal commands
 	bootCmd.AddCommand(inc.Command)        // script helper include command
@@ -64,6 +92,9 @@ func BootStrap(bootCmd *cobra.Command) error {
 	bootCmd.AddCommand(jumbo.Command)      // Jumbo text output
 	bootCmd.AddCommand(list.Command)       // list embedded files text output
 	bootCmd.AddCommand(logcmd.Command)     // list embedded files text output
+	bootCmd.AddCommand(notify.Command)     // chat, webhook & email notifications
+	bootCmd.AddCommand(progress.Command)   // progress bars & spinners for scripts
+	bootCmd.AddCommand(secret.Command)     // age encrypted secrets file
 	bootCmd.AddCommand(should.Command)     // logic helper for bash scripts
 	bootCmd.AddCommand(source.Command)     // source a script or snippet
 	bootCmd.AddCommand(version.Command)    // version reporting
@@ -86,6 +117,11 @@ func BootStrap(bootCmd *cobra.Command) error {
 	// build the UX menus in case we're running interactively
 	ux.BuildMenus(bootCmd)
 
+	// every record carries the path of the command that will run
+	if c, _, err := bootCmd.Find(os.Args[1:]); err == nil {
+		slogger.SetRunCommand(c.CommandPath())
+	}
+
 	// Finally, Execute the cobra command parser on the configured hierarchy
 	// the return value of the command is returned to the shell
 	return bootCmd.Execute()
@@ -0,0 +1,242 @@
+//  Copyright ©2017-2025    Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License    https://opensource.org/license/bsd-3-clause/
+//
+// package cmd contains the default commands in a form that can be individually
+// loaded by a fork of clog.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+)
+
+// config keys for the default logger
+var LogLevelKey = "clog.log.level"
+var LogStyleKey = "clog.log.style"
+var LogSinksKey = "clog.log.sinks"
+var LogRotateKey = "clog.log.rotate"
+var LogCIAnnotationsKey = "clog.log.ci-annotations"
+var LogColorKey = "clog.log.color"
+var LogThemeKey = "clog.log.theme"
+var LogSyslogKey = "clog.log.syslog"
+var LogLevelsKey = "clog.log.levels"
+
+// configureLogger sets the default logger from the clog.log config. If any
+// sinks are declared then a tee logger is used whatever the style. Bad values
+// are reported and the current logger is kept.
+func configureLogger(cfg *config.Config) {
+	if cfg == nil || !cfg.IsSet("clog.log") {
+		return
+	}
+	level, err := slogger.ParseLevel(cfg.GetString(LogLevelKey))
+	if err != nil {
+		slog.Warn(LogLevelKey + ": " + err.Error())
+		return
+	}
+	style, err := slogger.ParseStyle(cfg.GetString(LogStyleKey))
+	if err != nil {
+		slog.Warn(LogStyleKey + ": " + err.Error())
+		return
+	}
+	configureColor(cfg)
+	configureLevels(cfg)
+	if style == slogger.StyleTee || cfg.IsSet(LogSinksKey) {
+		sinks, err := configSinks(cfg)
+		if err != nil {
+			slog.Warn(LogSinksKey + ": " + err.Error())
+			return
+		}
+		if err := slogger.UseTeeLogger(level, sinks); err != nil {
+			slog.Warn(LogSinksKey + ": " + err.Error())
+		}
+		return
+	}
+	slogger.SetLogger(level, style)
+}
+
+// configureLevels sets the component levels of the default logger from
+// clog.log.levels e.g. {gommi: warn, kfg: debug}. Bad levels are reported and
+// skipped.
+func configureLevels(cfg *config.Config) {
+	if !cfg.IsSet(LogLevelsKey) {
+		slogger.LogLevels().SetComponents(nil)
+		return
+	}
+	names := map[string]string{}
+	if err := configJson(cfg, LogLevelsKey, &names); err != nil {
+		slog.Warn(LogLevelsKey + ": " + err.Error())
+		return
+	}
+	levels := map[string]slog.Level{}
+	for component, name := range names {
+		level, err := slogger.ParseLevel(name)
+		if err != nil {
+			slog.Warn(LogLevelsKey + "." + component + ": " + err.Error())
+			continue
+		}
+		levels[component] = level
+	}
+	slogger.LogLevels().SetComponents(levels)
+}
+
+// reloadLevels applies the log levels from a reloaded config. --loglevel
+// still wins. The other clog.log settings are used after a restart.
+func reloadLevels(cfg *config.Config, changed []string) {
+	for _, key := range changed {
+		if key != LogLevelKey && !strings.HasPrefix(key, LogLevelsKey+".") {
+			slog.Warn(key + " changed - restart clog to use it")
+		}
+	}
+	level, err := slogger.ParseLevel(cfg.GetString(LogLevelKey))
+	if err != nil {
+		slog.Warn(LogLevelKey + ": " + err.Error())
+	} else {
+		slogger.LogLevels().SetBase(level)
+	}
+	configureLevels(cfg)
+	applyLogLevelFlag()
+}
+
+// the levels of the old numeric --loglevel flag
+var legacyLogLevels = map[string]slog.Level{
+	"0": slogger.LevelEmergency + 1,
+	"1": slogger.LevelDebug,
+	"2": slogger.LevelInfo,
+	"3": slogger.LevelWarn,
+	"4": slogger.LevelError,
+}
+
+// parseLevelFlag converts the --loglevel flag into a base level (nil if it is
+// not set) and component levels. The flag is a comma separated list of
+// levels & component=level pairs e.g. debug, kfg=trace,warn or off.
+func parseLevelFlag(flag string) (*slog.Level, map[string]slog.Level, error) {
+	var base *slog.Level
+	components := map[string]slog.Level{}
+	for _, item := range strings.Split(flag, ",") {
+		component, name, isComponent := strings.Cut(strings.TrimSpace(item), "=")
+		if !isComponent {
+			name = component
+		}
+		level, legacy := legacyLogLevels[name]
+		if strings.EqualFold(name, "off") {
+			level, legacy = legacyLogLevels["0"], true
+		}
+		if !legacy {
+			var err error
+			if level, err = slogger.ParseLevel(name); err != nil || len(name) == 0 {
+				return nil, nil, fmt.Errorf("bad level (%s)", item)
+			}
+		}
+		if isComponent {
+			components[component] = level
+		} else {
+			base = &level
+		}
+	}
+	return base, components, nil
+}
+
+// applyLogLevelFlag overrides the config levels with --loglevel once the
+// command line has been parsed
+func applyLogLevelFlag() {
+	if len(LogLevel) == 0 {
+		return
+	}
+	base, components, err := parseLevelFlag(LogLevel)
+	if err != nil {
+		slog.Warn("--loglevel: " + err.Error())
+		return
+	}
+	levels := slogger.LogLevels()
+	if base != nil {
+		levels.SetBase(*base)
+	}
+	for component, level := range components {
+		levels.Set(component, level)
+	}
+}
+
+// configureColor sets the colour policy & the theme of the pretty loggers.
+// Bad values are reported and the defaults are kept.
+func configureColor(cfg *config.Config) {
+	mode, err := slogger.ParseColorMode(cfg.GetString(LogColorKey))
+	if err != nil {
+		slog.Warn(LogColorKey + ": " + err.Error())
+	}
+	slogger.SetColorMode(mode)
+	slogger.InheritColor()
+	if !cfg.IsSet(LogThemeKey) {
+		return
+	}
+	themeCfg := slogger.ThemeConfig{}
+	if err := configJson(cfg, LogThemeKey, &themeCfg); err != nil {
+		slog.Warn(LogThemeKey + ": " + err.Error())
+		return
+	}
+	theme, err := slogger.NewConfigTheme(themeCfg)
+	if err != nil {
+		slog.Warn(LogThemeKey + ": " + err.Error())
+		return
+	}
+	slogger.SetTheme(theme)
+}
+
+// configSinks reads the sinks via json so that the yaml keys match the json
+// tags of slogger.Sink. File sinks without their own rotation use the
+// clog.log.rotate default & syslog sinks without their own facility or
+// app-name use the clog.log.syslog default.
+func configSinks(cfg *config.Config) ([]slogger.Sink, error) {
+	sinks := []slogger.Sink{}
+	if err := configJson(cfg, LogSinksKey, &sinks); err != nil {
+		return nil, err
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("style tee needs at least one sink")
+	}
+	if cfg.IsSet(LogSyslogKey) {
+		syslogCfg := slogger.SyslogConfig{}
+		if err := configJson(cfg, LogSyslogKey, &syslogCfg); err != nil {
+			return nil, errors.New(LogSyslogKey + ": " + err.Error())
+		}
+		for i := range sinks {
+			if sinks[i].Syslog == nil {
+				sinks[i].Syslog = &syslogCfg
+			}
+		}
+	}
+	if !cfg.IsSet(LogRotateKey) {
+		return sinks, nil
+	}
+	rotate := slogger.Rotation{}
+	if err := configJson(cfg, LogRotateKey, &rotate); err != nil {
+		return nil, errors.New(LogRotateKey + ": " + err.Error())
+	}
+	for i := range sinks {
+		if sinks[i].IsFile() && sinks[i].Rotate == nil {
+			sinks[i].Rotate = &rotate
+		}
+	}
+	return sinks, nil
+}
+
+// configJson unmarshals a config branch into v using its json tags
+func configJson(cfg *config.Config, key string, v any) error {
+	jsonBody, err := json.Marshal(cfg.Get(key))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBody, v)
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
@@ -0,0 +1,56 @@
+//  Copyright ©2017-2025    Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License    https://opensource.org/license/bsd-3-clause/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"runtime"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+)
+
+// config key of the notification targets & routes
+var NotifyKey = "clog.notify"
+
+// configureNotify creates the notifier used by clog Notify and notify sinks
+// from clog.notify. The env of a target is a clog.env key (e.g. slack.webhook)
+// or an env var name and its value becomes the secret of the target. Bad
+// targets & routes are reported and skipped.
+func configureNotify(cfg *config.Config) {
+	if cfg == nil || !cfg.IsSet(NotifyKey) {
+		return
+	}
+	notifyCfg := slogger.NotifyConfig{}
+	if err := configJson(cfg, NotifyKey, &notifyCfg); err != nil {
+		slog.Warn(NotifyKey + ": " + err.Error())
+		return
+	}
+	for name, t := range notifyCfg.Targets {
+		if len(t.Env) == 0 {
+			continue
+		}
+		envVar := cfg.GetString(EnvKey + "." + t.Env)
+		if len(envVar) == 0 {
+			envVar = t.Env
+		}
+		t.Secret = os.Getenv(envVar)
+		if len(t.Secret) == 0 {
+			slog.Warn(NotifyKey + ".targets." + name + ": env var " + envVar + " is not set")
+		}
+		slogger.MarkSecret(t.Secret)
+		notifyCfg.Targets[name] = t
+	}
+	n, err := slogger.NewNotifier(notifyCfg)
+	if err != nil {
+		slog.Warn(NotifyKey + ": " + err.Error())
+	}
+	slogger.SetNotifier(n)
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
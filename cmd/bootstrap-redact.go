@@ -0,0 +1,93 @@
+//  Copyright ©2017-2025    Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License    https://opensource.org/license/bsd-3-clause/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+)
+
+// config keys for secret redaction
+var EnvKey = "clog.env"
+var RedactKey = "clog.redact"
+
+// RedactConfig is the clog.redact config
+//
+//	clog:
+//	  redact:
+//	    mask: "[REDACTED]"
+//	    min-length: 4
+//	    env: [NPM_TOKEN]              # secret env vars not named in clog.env
+//	    patterns: [aws-access-key, jwt, pem, "sk_live_[0-9a-zA-Z]{24}"]
+type RedactConfig struct {
+	Mask      string   `json:"mask"`
+	MinLength int      `json:"min-length"`
+	Env       []string `json:"env"`
+	Patterns  []string `json:"patterns"`
+}
+
+// configureRedaction masks the values of the env vars named in clog.env &
+// clog.redact.env and the clog.redact.patterns in all log output and in the
+// output of scripts & snippets. Bad patterns are reported and skipped.
+func configureRedaction(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	redactCfg := RedactConfig{}
+	if cfg.IsSet(RedactKey) {
+		if err := configJson(cfg, RedactKey, &redactCfg); err != nil {
+			slog.Warn(RedactKey + ": " + err.Error())
+		}
+	}
+	r := slogger.DefaultRedactor()
+	mask := redactCfg.Mask
+	if len(mask) == 0 {
+		mask = slogger.DefaultRedactMask
+	}
+	minLength := redactCfg.MinLength
+	if minLength == 0 {
+		minLength = slogger.DefaultRedactMinLength
+	}
+	r.SetMask(mask, minLength)
+
+	names := append(envVarNames(cfg.Get(EnvKey)), redactCfg.Env...)
+	for _, name := range names {
+		r.AddSecrets(os.Getenv(name))
+	}
+	for _, p := range redactCfg.Patterns {
+		if err := r.AddPatterns(p); err != nil {
+			slog.Warn(RedactKey + ".patterns: " + err.Error())
+		}
+	}
+}
+
+// envVarNames returns the env var names that are the leaves of the clog.env
+// tree e.g. clog.env.aws.secret: AWS_SECRET_ACCESS_KEY
+func envVarNames(branch any) []string {
+	names := []string{}
+	switch v := branch.(type) {
+	case string:
+		names = append(names, v)
+	case map[string]any:
+		for _, leaf := range v {
+			names = append(names, envVarNames(leaf)...)
+		}
+	case []any:
+		for _, leaf := range v {
+			names = append(names, envVarNames(leaf)...)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
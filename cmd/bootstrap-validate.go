@@ -0,0 +1,44 @@
+//  Copyright ©2017-2025    Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License    https://opensource.org/license/bsd-3-clause/
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+)
+
+// config key that turns on schema validation at startup (like --strict)
+var StrictKey = "clog.clogrc.strict"
+
+// validateStrict stops clog if --strict or clog.clogrc.strict is set and any
+// config file does not match the embedded schema. It runs once the flags
+// have been parsed.
+func validateStrict() {
+	cfg := config.Cfg()
+	if cfg == nil || !(Strict || cfg.GetBool(StrictKey)) {
+		return
+	}
+	errs, err := cfg.Validate()
+	if err != nil {
+		slog.Error("--strict: " + err.Error())
+		slogger.Exit(1)
+	}
+	if len(errs) == 0 {
+		return
+	}
+	for _, e := range errs {
+		slogger.Error(e.Error())
+	}
+	slogger.Error(fmt.Sprintf("--strict: %d config errors - see clog Config validate", len(errs)))
+	slogger.Exit(1)
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
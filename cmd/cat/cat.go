@@ -15,6 +15,7 @@ import (
 
 	"github.com/mrmxf/clog/config"
 	"github.com/mrmxf/clog/core"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -52,7 +53,7 @@ var Command = &cobra.Command{
 		src, err := clogFs.Open(core.Clean(srcPath))
 		if err != nil {
 			slog.Error(fmt.Sprintf("cannot open embedded file %s", srcPath), "err", err)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 		defer src.Close()
 
@@ -61,7 +62,7 @@ var Command = &cobra.Command{
 		nBytes, err := io.Copy(dst, src)
 		if err != nil {
 			slog.Error(fmt.Sprintf("cannot read embedded file %s (%d bytes copied)", srcPath, nBytes), "err", err)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 	},
 }
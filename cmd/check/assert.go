@@ -0,0 +1,283 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+// package check creates a try-catch-finally block of scripts
+
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/semver"
+)
+
+// timeout for network assertions (tcp & http)
+var AssertTimeout = 5 * time.Second
+
+// timeout for each run of a tool-version command
+var ToolTimeout = 30 * time.Second
+
+// the keys of an assert: block
+var assertKeys = []string{"file-exists", "env-set", "tool-version", "tcp", "http", "config"}
+
+// CheckAssert is a set of native golang assertions that can be used instead
+// of (or as well as) a try: shell snippet. All assertions present must pass.
+//
+//	assert: {file-exists: go.mod}
+//	assert: {env-set: GHAT}
+//	assert: {tool-version: {cmd: go, constraint: ">=1.22"}}
+//	assert: {tcp: localhost:11998}
+//	assert: {http: {url: "http://localhost:11998/", status: 200}}
+//	assert: {config: {key: clog.log.style, equals: pretty}}
+type CheckAssert struct {
+	FileExists  string             `json:"file-exists"`
+	EnvSet      string             `json:"env-set"`
+	ToolVersion *ToolVersionAssert `json:"tool-version"`
+	Tcp         string             `json:"tcp"`
+	Http        *HttpAssert        `json:"http"`
+	Config      *ConfigAssert      `json:"config"`
+	Unknown     []string           `json:"-"` // keys that are not assertions
+}
+
+// UnmarshalJSON keeps the unknown keys so that each one is reported
+func (a *CheckAssert) UnmarshalJSON(data []byte) error {
+	type plain CheckAssert
+	if err := json.Unmarshal(data, (*plain)(a)); err != nil {
+		return err
+	}
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	a.Unknown = nil
+	for key := range keys {
+		if !slices.Contains(assertKeys, key) {
+			a.Unknown = append(a.Unknown, key)
+		}
+	}
+	slices.Sort(a.Unknown)
+	return nil
+}
+
+// ToolVersionAssert runs Cmd (with Args) and compares the first version found
+// in the output with Constraint e.g. ">=1.22, <2". If Args is empty then
+// `--version` and `version` are tried in turn.
+type ToolVersionAssert struct {
+	Cmd        string   `json:"cmd"`
+	Args       []string `json:"args"`
+	Constraint string   `json:"constraint"`
+}
+
+// HttpAssert does a GET of Url and checks that the status code is Status
+// (default 200)
+type HttpAssert struct {
+	Url    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// ConfigAssert checks that Key has a value in the merged config. If Equals is
+// not nil then the value must also match Equals.
+type ConfigAssert struct {
+	Key    string `json:"key"`
+	Equals any    `json:"equals"`
+}
+
+// evaluate all the assertions and return a list of passes and an error that
+// describes every failure
+func (a *CheckAssert) evaluate() (string, error) {
+	passes := []string{}
+	fails := []string{}
+	check := func(name string, msg string, err error) {
+		if err != nil {
+			fails = append(fails, name+": "+err.Error())
+			return
+		}
+		passes = append(passes, name+": "+msg)
+	}
+	count := 0
+	if len(a.FileExists) > 0 {
+		count++
+		msg, err := assertFileExists(a.FileExists)
+		check("file-exists", msg, err)
+	}
+	if len(a.EnvSet) > 0 {
+		count++
+		msg, err := assertEnvSet(a.EnvSet)
+		check("env-set", msg, err)
+	}
+	if a.ToolVersion != nil {
+		count++
+		msg, err := assertToolVersion(a.ToolVersion)
+		check("tool-version", msg, err)
+	}
+	if len(a.Tcp) > 0 {
+		count++
+		msg, err := assertTcp(a.Tcp)
+		check("tcp", msg, err)
+	}
+	if a.Http != nil {
+		count++
+		msg, err := assertHttp(a.Http)
+		check("http", msg, err)
+	}
+	if a.Config != nil {
+		count++
+		msg, err := assertConfig(a.Config)
+		check("config", msg, err)
+	}
+	for _, key := range a.Unknown {
+		check(key, "", errors.New("unknown assertion - use "+strings.Join(assertKeys, ", ")))
+	}
+	if count == 0 && len(a.Unknown) == 0 {
+		return "", errors.New("assert has no known assertion types")
+	}
+	if len(fails) > 0 {
+		return strings.Join(append(fails, passes...), "\n"), errors.New(strings.Join(fails, "; "))
+	}
+	return strings.Join(passes, "\n"), nil
+}
+
+// expand ~ and $ENV_VARS in a path
+func expandPath(rawPath string) string {
+	p := strings.Replace(rawPath, "~", "$HOME", 1)
+	p, _ = config.ExpandEnvVars(p)
+	return filepath.Clean(p)
+}
+
+func assertFileExists(rawPath string) (string, error) {
+	path := expandPath(rawPath)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%s not found", path)
+		}
+		return "", fmt.Errorf("%s cannot be read (%s)", path, err.Error())
+	}
+	return path + " exists", nil
+}
+
+func assertEnvSet(name string) (string, error) {
+	name = strings.TrimPrefix(name, "$")
+	value, exists := os.LookupEnv(name)
+	if !exists {
+		return "", fmt.Errorf("$%s is not set", name)
+	}
+	if len(value) == 0 {
+		return "", fmt.Errorf("$%s is set but empty", name)
+	}
+	return "$" + name + " is set", nil
+}
+
+func assertToolVersion(tv *ToolVersionAssert) (string, error) {
+	if len(tv.Cmd) == 0 {
+		return "", errors.New("cmd is missing")
+	}
+	path, err := exec.LookPath(tv.Cmd)
+	if err != nil {
+		return "", fmt.Errorf("%s not found in $PATH", tv.Cmd)
+	}
+	argSets := [][]string{tv.Args}
+	if len(tv.Args) == 0 {
+		argSets = [][]string{{"--version"}, {"version"}}
+	}
+	version := ""
+	for _, args := range argSets {
+		out, err := runTool(path, args)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s %s did not finish in %s", tv.Cmd, strings.Join(args, " "), ToolTimeout)
+		}
+		if err == nil {
+			version = semver.FindVersion(string(out))
+		}
+		if len(version) > 0 {
+			break
+		}
+	}
+	if len(version) == 0 {
+		return "", fmt.Errorf("cannot find a version number in the output of %s", tv.Cmd)
+	}
+	ok, err := semver.Satisfies(version, tv.Constraint)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("%s version %s does not satisfy (%s)", tv.Cmd, version, tv.Constraint)
+	}
+	return fmt.Sprintf("%s version %s satisfies (%s)", tv.Cmd, version, tv.Constraint), nil
+}
+
+// runTool runs a tool for its version and stops it after ToolTimeout
+func runTool(path string, args []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ToolTimeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, path, args...)
+	// do not wait for children of the tool that hold its output open
+	c.WaitDelay = time.Second
+	out, err := c.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return out, err
+}
+
+func assertTcp(address string) (string, error) {
+	conn, err := net.DialTimeout("tcp", address, AssertTimeout)
+	if err != nil {
+		return "", fmt.Errorf("nothing listening on %s (%s)", address, err.Error())
+	}
+	conn.Close()
+	return address + " is listening", nil
+}
+
+func assertHttp(h *HttpAssert) (string, error) {
+	want := h.Status
+	if want == 0 {
+		want = http.StatusOK
+	}
+	client := http.Client{Timeout: AssertTimeout}
+	res, err := client.Get(h.Url)
+	if err != nil {
+		return "", fmt.Errorf("GET %s failed (%s)", h.Url, err.Error())
+	}
+	res.Body.Close()
+	if res.StatusCode != want {
+		return "", fmt.Errorf("GET %s returned %d, expected %d", h.Url, res.StatusCode, want)
+	}
+	return fmt.Sprintf("GET %s returned %d", h.Url, res.StatusCode), nil
+}
+
+func assertConfig(c *ConfigAssert) (string, error) {
+	if len(c.Key) == 0 {
+		return "", errors.New("key is missing")
+	}
+	cfg := config.Cfg()
+	if cfg == nil || !cfg.IsSet(c.Key) {
+		return "", fmt.Errorf("%s is not set in config", c.Key)
+	}
+	got, err := cfg.GetStringE(c.Key)
+	if err != nil {
+		return "", err
+	}
+	if c.Equals == nil {
+		if len(got) == 0 {
+			return "", fmt.Errorf("%s is empty in config", c.Key)
+		}
+		return fmt.Sprintf("%s is set", c.Key), nil
+	}
+	want := fmt.Sprint(c.Equals)
+	if got != want {
+		return "", fmt.Errorf("%s is (%s), expected (%s)", c.Key, got, want)
+	}
+	return fmt.Sprintf("%s == %s", c.Key, want), nil
+}
@@ -0,0 +1,96 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+
+package check
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAssert(t *testing.T) {
+	dir := t.TempDir()
+
+	Convey("file-exists should find files & expand env vars", t, func() {
+		t.Setenv("CLOG_TEST_DIR", dir)
+		os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n"), 0644)
+		msg, err := (&CheckAssert{FileExists: "$CLOG_TEST_DIR/go.mod"}).evaluate()
+		So(err, ShouldBeNil)
+		So(msg, ShouldContainSubstring, "go.mod exists")
+		_, err = (&CheckAssert{FileExists: "$CLOG_TEST_DIR/nope"}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "nope not found")
+	})
+
+	Convey("env-set should fail for unset & empty variables", t, func() {
+		t.Setenv("CLOG_TEST_SET", "yes")
+		t.Setenv("CLOG_TEST_EMPTY", "")
+		_, err := (&CheckAssert{EnvSet: "$CLOG_TEST_SET"}).evaluate()
+		So(err, ShouldBeNil)
+		_, err = (&CheckAssert{EnvSet: "CLOG_TEST_EMPTY"}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "set but empty")
+		_, err = (&CheckAssert{EnvSet: "CLOG_TEST_UNSET_VARIABLE"}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "is not set")
+	})
+
+	Convey("tcp should pass only if something is listening", t, func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		address := l.Addr().String()
+		_, err = (&CheckAssert{Tcp: address}).evaluate()
+		So(err, ShouldBeNil)
+		l.Close()
+		_, err = (&CheckAssert{Tcp: address}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "nothing listening")
+	})
+
+	Convey("http should check the status code (default 200)", t, func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				http.NotFound(w, r)
+			}
+		}))
+		defer srv.Close()
+		_, err := (&CheckAssert{Http: &HttpAssert{Url: srv.URL + "/"}}).evaluate()
+		So(err, ShouldBeNil)
+		_, err = (&CheckAssert{Http: &HttpAssert{Url: srv.URL + "/missing", Status: 404}}).evaluate()
+		So(err, ShouldBeNil)
+		_, err = (&CheckAssert{Http: &HttpAssert{Url: srv.URL + "/missing"}}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "returned 404, expected 200")
+	})
+
+	Convey("tool-version should check the version & stop a tool that hangs", t, func() {
+		tool := filepath.Join(dir, "tool")
+		os.WriteFile(tool, []byte("#!/bin/sh\n[ \"$1\" = hang ] && sleep 10\necho tool v1.4.2\n"), 0755)
+		_, err := (&CheckAssert{ToolVersion: &ToolVersionAssert{Cmd: tool, Constraint: ">=1.4"}}).evaluate()
+		So(err, ShouldBeNil)
+		_, err = (&CheckAssert{ToolVersion: &ToolVersionAssert{Cmd: tool, Constraint: ">=2"}}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "does not satisfy")
+
+		defer func(timeout time.Duration) { ToolTimeout = timeout }(ToolTimeout)
+		ToolTimeout = 100 * time.Millisecond
+		start := time.Now()
+		_, err = (&CheckAssert{ToolVersion: &ToolVersionAssert{Cmd: tool, Args: []string{"hang"}, Constraint: ">=1"}}).evaluate()
+		So(err.Error(), ShouldContainSubstring, "did not finish")
+		So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+	})
+
+	Convey("every unknown key should be reported", t, func() {
+		a := CheckAssert{}
+		So(json.Unmarshal([]byte(`{"env-set": "HOME", "file-exist": "go.mod", "tcpp": "x"}`), &a), ShouldBeNil)
+		So(a.Unknown, ShouldResemble, []string{"file-exist", "tcpp"})
+		msg, err := a.evaluate()
+		So(err, ShouldNotBeNil)
+		So(msg, ShouldContainSubstring, "file-exist: unknown assertion")
+		So(msg, ShouldContainSubstring, "tcpp: unknown assertion")
+		So(msg, ShouldContainSubstring, "env-set: $HOME is set")
+	})
+}
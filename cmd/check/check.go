@@ -6,16 +6,21 @@
 package check
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/mrmxf/clog/config"
 	"github.com/mrmxf/clog/scripts"
 	"github.com/mrmxf/clog/shell"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -23,35 +28,62 @@ var YamlKey = "check"
 
 // define the try-catch-finally block keys:
 type CheckBlock struct {
-	Try              string `json:"try"`
+	Name             string       `json:"name"`
+	Assert           *CheckAssert `json:"assert"`
+	Try              string       `json:"try"`
+	Expect           *CheckExpect `json:"expect"`
 	TryStdOutErr     string
 	TryExitCode      int
+	TryOk            bool
+	ExpectFailure    string
 	Ok               string `json:"ok"`
 	Catch            string `json:"catch"`
 	CatchStdOutErr   string
 	Finally          string `json:"finally"`
 	FinallyStdOutErr string
 	FinallyExitCode  int
+	Fix              string `json:"fix"`
+	FixExitCode      int
+	Flaky            bool `json:"flaky"`
+	Xfail            bool `json:"xfail"`
 }
 
 // validRequiredKeys is a reference map to check if the keys in the config
 // are weird or valid. None of the keys are currently required
 var validRequiredKeys = map[string]bool{
-	"Try":     false,
-	"Pass":    false,
-	"Catch":   false,
-	"Finally": false,
+	"name":    false,
+	"assert":  false,
+	"try":     false,
+	"expect":  false,
+	"ok":      false,
+	"catch":   false,
+	"finally": false,
+	"fix":     false,
+	"flaky":   false,
+	"xfail":   false,
 }
 
 // a Check Group is a collection of Check Blocks, potentially with a log level
+// and log file. If ArtifactsDir is set, the stdout & stderr of each block are
+// saved there. If Section is set the group is a collapsible CI log section.
 type CheckGroup struct {
-	Name     string
-	LogLevel slog.Level
-	LogFile  *os.File
-	Before   string `json:"before"`
-	Blocks   []CheckBlock
+	Name         string
+	Key          string
+	LogLevel     slog.Level
+	LogFile      *os.File
+	LogPath      string `json:"log-file"`
+	ArtifactsDir string `json:"artifacts-dir"`
+	Section      string `json:"section"`
+	Before       string `json:"before"`
+	Blocks       []CheckBlock
 }
 
+// CLI flag to run the fix script of a block when its try fails
+var FixMode bool
+
+// CLI flag to run fix scripts without asking for confirmation
+var AssumeYes bool
+
 var Command = &cobra.Command{
 	Use:   "Check",
 	Short: "run all blocks in a check group defined in config",
@@ -62,27 +94,28 @@ var Command = &cobra.Command{
 
 		if cfg.Get(YamlKey) == nil {
 			slog.Error("cannot run Check - no " + YamlKey + " key found in clog.yaml")
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 
 		// check a group was specified
 		if len(args) == 0 {
 			slog.Error("cannot run Check - you must supply a check group e.g. clog Check pre-build")
 			cmd.Help()
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 
 		// check which group we are running
 		YamlKey = YamlKey + "." + args[0]
 		if cfg.Get(YamlKey) == nil {
 			slog.Error(fmt.Sprintf("cannot run Check - check group (%s) not found in clog.yaml", YamlKey))
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 
 		// parse the check2 key into a CheckGroup struct
 		blocks := []CheckBlock{}
 		group := CheckGroup{
 			Name:     args[0],
+			Key:      args[0],
 			LogLevel: slog.LevelInfo,
 			LogFile:  nil,
 			Blocks:   blocks,
@@ -90,7 +123,7 @@ var Command = &cobra.Command{
 		err := parseBlocks(cmd, YamlKey, &group, cfg.Get(YamlKey+".blocks"))
 		if err != nil {
 			slog.Error(fmt.Sprintf("fix config %s to continue", YamlKey))
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 		// set the group level keys
 		// group.Before = cfg.GetString(key + ".before")
@@ -98,9 +131,36 @@ var Command = &cobra.Command{
 		if len(group.Name) == 0 {
 			group.Name = YamlKey
 		}
+		group.LogPath = cfg.GetString(YamlKey + ".log-file")
+		group.ArtifactsDir = cfg.GetString(YamlKey + ".artifacts-dir")
+		if levelName := cfg.GetString(YamlKey + ".log-level"); len(levelName) > 0 {
+			group.LogLevel, err = slogger.ParseLevel(levelName)
+			if err != nil {
+				slog.Warn(fmt.Sprintf("%s.log-level: %s", YamlKey, err.Error()))
+			}
+		}
+		group.Section = cfg.GetString(YamlKey + ".section")
+		if group.Section == "true" {
+			group.Section = group.Name
+		}
+		closeLog, err := openGroupLog(&group)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("cannot open %s.log-file (%s)", YamlKey, err.Error()))
+		}
+		// sections only help a CI log - locally they are just noise
+		var section *slogger.Section
+		ci := slogger.DetectCI()
+		if len(group.Section) > 0 && group.Section != "false" && ci != slogger.CINone {
+			section = slogger.NewSection(group.Section, 0)
+			section.Begin(os.Stdout, ci)
+		}
 		err = runBlocks(cmd, YamlKey, group)
+		if section != nil {
+			section.End(os.Stdout, ci)
+		}
+		closeLog()
 		if err != nil {
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 	},
 }
@@ -114,54 +174,91 @@ func splice(before string, stepStr string) string {
 	return cmdStr + stepStr
 }
 
-// exec a command with custom environment
-func capture(before string, stepStr string, i int, stepName string, env map[string]string) (string, int, error) {
+// exec a command with custom environment. It returns stdout & stderr
+// combined and stdout on its own. A copy of the output goes to the log file &
+// artifacts in out (if any)
+func capture(before string, stepStr string, i int, stepName string, env map[string]string, out *blockOutput) (string, string, int, error) {
 	cmdStr := splice(before, stepStr)
-	outErr, exitCode, err := shell.CaptureShellSnippet(cmdStr, env)
+	stdout, stderr := out.writers(false)
+	stdoutOnly := &bytes.Buffer{}
+	if stdout == nil {
+		stdout = stdoutOnly
+	} else {
+		stdout = io.MultiWriter(stdoutOnly, stdout)
+	}
+	outErr, exitCode, err := shell.CaptureShellSnippetTee(cmdStr, env, stdout, stderr)
 	if err != nil {
 		slog.Debug(fmt.Sprintf("            - %d (%s) failed", i, stepName), "err", err)
 	}
-	return outErr, exitCode, err
+	return outErr, stdoutOnly.String(), exitCode, err
 }
 
-// stream a command with custom environment
-func stream(before string, stepStr string, i int, stepName string, env map[string]string) (int, error) {
+// stream a command with custom environment to the console and to the log
+// file & artifacts in out (if any)
+func stream(before string, stepStr string, i int, stepName string, env map[string]string, out *blockOutput) (int, error) {
 	cmdStr := splice(before, stepStr)
-	exitStatus, err := scripts.AwaitShellSnippet(cmdStr, env, []string{})
+	stdout, stderr := out.writers(true)
+	exitStatus, err := scripts.AwaitShellSnippetTee(cmdStr, env, []string{}, stdout, stderr)
 	return exitStatus, err
 }
 
 // run all the blocks in the group
 func runBlocks(cmd *cobra.Command, key string, group CheckGroup) error {
 	fail := 0
+	fixes := fixTally{}
+	tolerated := toleratedTally{}
+	results := []BlockResult{}
+	commit := gitCommit()
 	var env map[string]string
 	var err error
 	for i, b := range group.Blocks {
-		//step 1: try
-		if len(b.Try) > 0 {
-			b.TryStdOutErr, b.TryExitCode, err = capture(group.Before, b.Try, i, "try", nil)
-
-			//preserve the output of try for the next steps
-			env = map[string]string{
-				"STDOUTERR": b.TryStdOutErr,
-				"EXITCODE":  fmt.Sprintf("%d", b.TryExitCode),
+		out := newBlockOutput(&group, i, &b)
+		start := time.Now()
+		//step 1: assert and/or try
+		if len(b.Try) > 0 || b.Assert != nil {
+			err = runTry(group, &b, i, out)
+			status := StatusPass
+			if !b.TryOk {
+				status = StatusFail
 			}
-			if err != nil {
-				env["ERR"] = err.Error()
+
+			//step 1a. fix (only in --fix mode) then re-try
+			if !b.TryOk && FixMode {
+				err = fixBlock(group, &b, i, &fixes, err, out)
+				if b.TryOk {
+					status = StatusFixed
+				}
 			}
+			results = append(results, BlockResult{
+				Time:     start,
+				Group:    group.Key,
+				Block:    blockName(i, &b),
+				Index:    i,
+				Commit:   commit,
+				Status:   status,
+				ExitCode: b.TryExitCode,
+				Duration: time.Since(start).Milliseconds(),
+				Flaky:    b.Flaky,
+				Xfail:    b.Xfail,
+			})
+			tolerated.add(i, &b)
+
+			//preserve the output of try for the next steps
+			env = tryEnv(&b, err)
 
 			//step 2. ok or catch
-			if b.TryExitCode == 0 {
+			if b.TryOk {
 				if len(b.Ok) > 0 {
 					//step 2. ok command exists
-					stream(group.Before, b.Ok, i, "ok", env)
+					stream(group.Before, b.Ok, i, "ok", env, out)
 				}
 			} else {
 				if len(b.Catch) > 0 {
 					//step 2. catch exists
-					exit, _ := stream(group.Before, b.Catch, i, "catch", env)
+					exit, _ := stream(group.Before, b.Catch, i, "catch", env, out)
 					// fail is only incremented if a catch returns an error
-					if exit > 0 {
+					// flaky & xfail blocks never break the group
+					if exit > 0 && !b.Flaky && !b.Xfail {
 						fail++
 					}
 				}
@@ -170,8 +267,16 @@ func runBlocks(cmd *cobra.Command, key string, group CheckGroup) error {
 		//step 3. finally
 		if len(b.Finally) > 0 {
 			//step 3. finally exists
-			stream(group.Before, b.Finally, i, "catch", env)
+			stream(group.Before, b.Finally, i, "finally", env, out)
 		}
+		out.close()
+	}
+	if FixMode {
+		fixes.report(group.Name)
+	}
+	tolerated.report(group.Name)
+	if err := appendHistory(results); err != nil {
+		slog.Warn("cannot save check history", "err", err)
 	}
 	if fail == 0 {
 		slog.Info(fmt.Sprintf("Check %s passed (%d blocks)", group.Name, len(group.Blocks)))
@@ -182,12 +287,121 @@ func runBlocks(cmd *cobra.Command, key string, group CheckGroup) error {
 	return msg
 }
 
+// runTry evaluates the native assertions of a block followed by the try
+// snippet. The try snippet is only run if the assertions pass. A failed
+// assertion behaves like a try with exit code 1 and the failure message in
+// $STDOUTERR. The try passes (TryOk) if its exit code is 0 or, when there is
+// an expect: section, if all the expectations are met.
+func runTry(group CheckGroup, b *CheckBlock, i int, blockOut *blockOutput) error {
+	b.ExpectFailure = ""
+	if b.Assert != nil {
+		msg, err := b.Assert.evaluate()
+		b.TryStdOutErr = msg
+		if err != nil {
+			b.TryExitCode = 1
+			b.TryOk = false
+			if len(b.Catch) == 0 {
+				slog.Warn(fmt.Sprintf("   assert failed for %s: %s", blockName(i, b), err.Error()))
+			}
+			return err
+		}
+		b.TryExitCode = 0
+		b.TryOk = true
+	}
+	if len(b.Try) == 0 {
+		return nil
+	}
+	var err error
+	var stdout string
+	b.TryStdOutErr, stdout, b.TryExitCode, err = capture(group.Before, b.Try, i, "try", nil, blockOut)
+	b.TryOk = b.TryExitCode == 0
+	if b.Expect == nil {
+		return err
+	}
+
+	// the stdout of another command to compare with for stdout-equals
+	equalsFn := func(cmdStr string) (string, error) {
+		_, out, exitCode, _ := capture(group.Before, cmdStr, i, "expect", nil, nil)
+		if exitCode != 0 {
+			return out, fmt.Errorf("exit code %d", exitCode)
+		}
+		return out, nil
+	}
+	msg, ok := b.Expect.evaluate(strings.TrimSpace(stdout), b.TryExitCode, equalsFn)
+	b.TryOk = ok
+	if ok {
+		// an expected non-zero exit code is not an error
+		return nil
+	}
+	b.ExpectFailure = msg
+	slog.Warn(fmt.Sprintf("   expect failed for %s\n%s", blockName(i, b), msg))
+	if err == nil {
+		err = errors.New("expect failed")
+	}
+	return err
+}
+
+// toleratedTally collects the flaky & xfail blocks so that they can be
+// reported separately from the group result
+type toleratedTally struct {
+	FlakyFailed []string
+	Xfailed     []string
+	Xpassed     []string
+}
+
+func (t *toleratedTally) add(i int, b *CheckBlock) {
+	name := blockName(i, b)
+	switch {
+	case b.Xfail && b.TryOk:
+		t.Xpassed = append(t.Xpassed, name)
+	case b.Xfail:
+		t.Xfailed = append(t.Xfailed, name)
+	case b.Flaky && !b.TryOk:
+		t.FlakyFailed = append(t.FlakyFailed, name)
+	}
+}
+
+func (t *toleratedTally) report(groupName string) {
+	if len(t.FlakyFailed) > 0 {
+		slog.Warn(fmt.Sprintf("Check %s: %d flaky blocks failed (ignored)", groupName, len(t.FlakyFailed)), "blocks", t.FlakyFailed)
+	}
+	if len(t.Xfailed) > 0 {
+		slog.Info(fmt.Sprintf("Check %s: %d blocks failed as expected (xfail)", groupName, len(t.Xfailed)), "blocks", t.Xfailed)
+	}
+	if len(t.Xpassed) > 0 {
+		slog.Warn(fmt.Sprintf("Check %s: %d xfail blocks passed unexpectedly", groupName, len(t.Xpassed)), "blocks", t.Xpassed)
+	}
+}
+
+// the environment passed to ok, catch & finally from the (last) try
+func tryEnv(b *CheckBlock, err error) map[string]string {
+	env := map[string]string{
+		"STDOUTERR": b.TryStdOutErr,
+		"EXITCODE":  fmt.Sprintf("%d", b.TryExitCode),
+	}
+	if err != nil {
+		env["ERR"] = err.Error()
+	}
+	if len(b.ExpectFailure) > 0 {
+		env["EXPECTFAIL"] = b.ExpectFailure
+	}
+	return env
+}
+
+// a name for a block in log messages
+func blockName(i int, b *CheckBlock) string {
+	if len(b.Name) > 0 {
+		return b.Name
+	}
+	return fmt.Sprintf("block #%d", i)
+}
+
 func validateRawBlockKeys(key string, iBlk int, block map[string]interface{}) (*CheckBlock, bool) {
 	errCount := 0
 	newBlock := CheckBlock{}
 	// check all the keys from clog.yaml against reference keys
 	for k := range block {
-		if _, isValid := validRequiredKeys[k]; isValid {
+		if _, isValid := validRequiredKeys[k]; !isValid {
 			errCount++
 			slog.Warn((fmt.Sprintf("%s block #%d has foreign key (%s)", key, iBlk, k)))
 		}
@@ -209,13 +423,14 @@ func validateRawBlockKeys(key string, iBlk int, block map[string]interface{}) (*
 	return nil, false
 }
 
-// load the blocks into the CheckBlock struct
+// load the blocks into the CheckBlock struct. The keys of a block are the
+// validRequiredKeys - before: is a group key that runs ahead of every try.
 // typical block structure is:
 //   - name: check origin hash
-//     before: clog Log -I "? HEAD hash == origin hash"
 //     try: [[ "$(clog tag hash head)" == "$(clog tag hash origin)" ]]
+//     ok: clog Log -I "HEAD hash == origin hash"
 //     catch: clog Log -W "  HEAD hash != origin hash"
-//     finally
+//     finally: git status --short
 func parseBlocks(parentCmd *cobra.Command, key string, group *CheckGroup, rawBlocksArray any) error {
 	slog.Debug((fmt.Sprintf("%s raw blocks of type %T", key, rawBlocksArray)))
 	allBlocks := []CheckBlock{}
@@ -245,4 +460,7 @@ func parseBlocks(parentCmd *cobra.Command, key string, group *CheckGroup, rawBlo
 func init() {
 	_, file, _, _ := runtime.Caller(0)
 	slog.Debug("init " + file)
+
+	Command.PersistentFlags().BoolVar(&FixMode, "fix", false, "clog Check my-group --fix   # run the fix: script when a try: fails")
+	Command.PersistentFlags().BoolVarP(&AssumeYes, "yes", "y", false, "clog Check my-group --fix -y  # do not ask before running fix: scripts")
 }
@@ -44,6 +44,17 @@ func TestSpec(t *testing.T) {
 			Convey("YamlKey should be \"heck\"", func() {
 				So(check.YamlKey, ShouldEqual, "check")
 			})
+			//FixMode & AssumeYes
+			Convey("FixMode should default to false", func() {
+				So(check.FixMode, ShouldBeFalse)
+			})
+			Convey("AssumeYes should default to false", func() {
+				So(check.AssumeYes, ShouldBeFalse)
+			})
+			Convey("Command should have --fix and --yes flags", func() {
+				So(check.Command.PersistentFlags().Lookup("fix"), ShouldNotBeNil)
+				So(check.Command.PersistentFlags().Lookup("yes"), ShouldNotBeNil)
+			})
 
 		})
 
@@ -0,0 +1,251 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+// package check creates a try-catch-finally block of scripts
+
+package check
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CheckExpect replaces the default "exit code must be 0" test of a try: with
+// a set of expectations on the exit code and the captured stdout (stderr is
+// ignored). All of the expectations must be met for the try to pass.
+//
+//	expect:
+//	  exit-codes: [0, 1]
+//	  stdout-matches: '^v[0-9]+\.'
+//	  stdout-not-matches: 'dirty'
+//	  stdout-equals: clog git tag ref
+//	  json:
+//	    - {path: ".items[0].name", equals: clog}
+//	  yaml:
+//	    - {path: clog.log.style, matches: '^(plain|pretty|json)$'}
+type CheckExpect struct {
+	ExitCodes        ExitCodes    `json:"exit-codes"`
+	StdoutMatches    string       `json:"stdout-matches"`
+	StdoutNotMatches string       `json:"stdout-not-matches"`
+	StdoutEquals     string       `json:"stdout-equals"`
+	Json             []PathExpect `json:"json"`
+	Yaml             []PathExpect `json:"yaml"`
+}
+
+// ExitCodes is a set of acceptable exit codes. In yaml it can be written as a
+// single integer or a list of integers.
+type ExitCodes []int
+
+// PathExpect is an assertion on a value found at Path in the parsed output of
+// a try: e.g. `.items[0].name`. If neither Equals nor Matches is given then
+// the path must simply exist.
+type PathExpect struct {
+	Path    string `json:"path"`
+	Equals  any    `json:"equals"`
+	Matches string `json:"matches"`
+}
+
+// UnmarshalJSON accepts `exit-codes: 1` as well as `exit-codes: [0, 1]`
+func (e *ExitCodes) UnmarshalJSON(data []byte) error {
+	var single int
+	if err := json.Unmarshal(data, &single); err == nil {
+		*e = ExitCodes{single}
+		return nil
+	}
+	var list []int
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("exit-codes must be an integer or a list of integers")
+	}
+	*e = ExitCodes(list)
+	return nil
+}
+
+// check the captured output & exit code of a try against the expectations.
+// equalsFn runs the stdout-equals command and returns its output. The returned
+// string is a readable description of every failure, including diffs.
+func (x *CheckExpect) evaluate(out string, exitCode int, equalsFn func(string) (string, error)) (string, bool) {
+	fails := []string{}
+
+	codes := x.ExitCodes
+	if len(codes) == 0 {
+		codes = ExitCodes{0}
+	}
+	codeOk := false
+	for _, c := range codes {
+		codeOk = codeOk || c == exitCode
+	}
+	if !codeOk {
+		fails = append(fails, fmt.Sprintf("exit-codes: got %d, expected one of %v", exitCode, []int(codes)))
+	}
+
+	if len(x.StdoutMatches) > 0 {
+		re, err := regexp.Compile(x.StdoutMatches)
+		if err != nil {
+			fails = append(fails, fmt.Sprintf("stdout-matches: bad regex (%s)", err.Error()))
+		} else if !re.MatchString(out) {
+			fails = append(fails, fmt.Sprintf("stdout-matches: output does not match /%s/\n%s",
+				x.StdoutMatches, indent(out)))
+		}
+	}
+
+	if len(x.StdoutNotMatches) > 0 {
+		re, err := regexp.Compile(x.StdoutNotMatches)
+		if err != nil {
+			fails = append(fails, fmt.Sprintf("stdout-not-matches: bad regex (%s)", err.Error()))
+		} else if loc := re.FindStringIndex(out); loc != nil {
+			fails = append(fails, fmt.Sprintf("stdout-not-matches: output matches /%s/ at (%s)",
+				x.StdoutNotMatches, out[loc[0]:loc[1]]))
+		}
+	}
+
+	if len(x.StdoutEquals) > 0 {
+		want, err := equalsFn(x.StdoutEquals)
+		if err != nil {
+			fails = append(fails, fmt.Sprintf("stdout-equals: (%s) failed: %s", x.StdoutEquals, err.Error()))
+		} else if strings.TrimSpace(want) != strings.TrimSpace(out) {
+			fails = append(fails, "stdout-equals: output differs from ("+x.StdoutEquals+")\n"+
+				lineDiff(strings.TrimSpace(want), strings.TrimSpace(out)))
+		}
+	}
+
+	if len(x.Json) > 0 {
+		var doc any
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			fails = append(fails, "json: output is not valid JSON ("+err.Error()+")")
+		} else {
+			fails = append(fails, checkPaths("json", doc, x.Json)...)
+		}
+	}
+
+	if len(x.Yaml) > 0 {
+		var doc any
+		if err := yaml.Unmarshal([]byte(out), &doc); err != nil {
+			fails = append(fails, "yaml: output is not valid YAML ("+err.Error()+")")
+		} else {
+			fails = append(fails, checkPaths("yaml", doc, x.Yaml)...)
+		}
+	}
+
+	return strings.Join(fails, "\n"), len(fails) == 0
+}
+
+// check every path expectation against a parsed document
+func checkPaths(kind string, doc any, paths []PathExpect) []string {
+	fails := []string{}
+	for _, p := range paths {
+		got, err := lookupPath(doc, p.Path)
+		if err != nil {
+			fails = append(fails, fmt.Sprintf("%s: %s", kind, err.Error()))
+			continue
+		}
+		gotStr := fmt.Sprint(got)
+		if p.Equals != nil {
+			wantStr := fmt.Sprint(p.Equals)
+			if gotStr != wantStr {
+				fails = append(fails, fmt.Sprintf("%s: %s differs\n%s", kind, p.Path, lineDiff(wantStr, gotStr)))
+			}
+		}
+		if len(p.Matches) > 0 {
+			re, err := regexp.Compile(p.Matches)
+			if err != nil {
+				fails = append(fails, fmt.Sprintf("%s: %s bad regex (%s)", kind, p.Path, err.Error()))
+			} else if !re.MatchString(gotStr) {
+				fails = append(fails, fmt.Sprintf("%s: %s is (%s), does not match /%s/", kind, p.Path, gotStr, p.Matches))
+			}
+		}
+	}
+	return fails
+}
+
+// split a path like `.items[0].name` into ["items", "[0]", "name"]
+var rePathSegment = regexp.MustCompile(`[^.\[\]]+|\[[0-9]+\]`)
+
+// lookupPath walks a parsed JSON or YAML document using a dotted path with
+// optional [n] array indices
+func lookupPath(doc any, path string) (any, error) {
+	node := doc
+	walked := ""
+	for _, seg := range rePathSegment.FindAllString(path, -1) {
+		if strings.HasPrefix(seg, "[") {
+			idx, _ := strconv.Atoi(seg[1 : len(seg)-1])
+			list, ok := node.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a list at (%s)", path, walked)
+			}
+			if idx >= len(list) {
+				return nil, fmt.Errorf("%s index %d out of range at (%s)", path, idx, walked)
+			}
+			node = list[idx]
+		} else {
+			switch m := node.(type) {
+			case map[string]any:
+				v, ok := m[seg]
+				if !ok {
+					return nil, fmt.Errorf("%s not found at (%s)", path, walked+"."+seg)
+				}
+				node = v
+			case map[any]any:
+				v, ok := m[seg]
+				if !ok {
+					return nil, fmt.Errorf("%s not found at (%s)", path, walked+"."+seg)
+				}
+				node = v
+			default:
+				return nil, fmt.Errorf("%s is not a map at (%s)", path, walked)
+			}
+		}
+		walked += "." + strings.TrimPrefix(seg, ".")
+	}
+	return node, nil
+}
+
+// indent multi-line output for log messages
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    ")
+}
+
+// lineDiff returns a readable line-by-line diff of expected vs actual using
+// the longest common subsequence of lines.
+func lineDiff(expected string, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := []string{"    --- expected", "    +++ actual"}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "      "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "    - "+a[i])
+			i++
+		default:
+			lines = append(lines, "    + "+b[j])
+			j++
+		}
+	}
+	return strings.Join(lines, "\n")
+}
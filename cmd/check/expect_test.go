@@ -0,0 +1,70 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+
+package check
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExpect(t *testing.T) {
+	Convey("exit-codes should be an integer or a list", t, func() {
+		codes := ExitCodes{}
+		So(json.Unmarshal([]byte(`2`), &codes), ShouldBeNil)
+		So(codes, ShouldResemble, ExitCodes{2})
+		So(json.Unmarshal([]byte(`[0, 1]`), &codes), ShouldBeNil)
+		So(codes, ShouldResemble, ExitCodes{0, 1})
+		So(json.Unmarshal([]byte(`"zero"`), &codes), ShouldNotBeNil)
+	})
+
+	Convey("lookupPath should walk maps & lists", t, func() {
+		doc := map[string]any{"items": []any{map[string]any{"name": "clog"}}}
+		got, err := lookupPath(doc, ".items[0].name")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "clog")
+		_, err = lookupPath(doc, ".items[1].name")
+		So(err.Error(), ShouldContainSubstring, "out of range")
+		_, err = lookupPath(doc, ".items[0].version")
+		So(err.Error(), ShouldContainSubstring, "not found at (.items.[0].version)")
+		_, err = lookupPath(doc, ".items.name")
+		So(err.Error(), ShouldContainSubstring, "is not a map")
+
+		yamlDoc := map[any]any{"clog": map[any]any{"style": "pretty"}}
+		got, err = lookupPath(yamlDoc, "clog.style")
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "pretty")
+	})
+
+	Convey("lineDiff should mark the removed & added lines", t, func() {
+		diff := lineDiff("a\nb\nc", "a\nB\nc")
+		So(diff, ShouldEqual, "    --- expected\n    +++ actual\n      a\n    - b\n    + B\n      c")
+	})
+
+	Convey("evaluate should report every failed expectation", t, func() {
+		x := CheckExpect{ExitCodes: ExitCodes{0, 1}, StdoutMatches: "^v[0-9]", StdoutNotMatches: "dirty"}
+		_, ok := x.evaluate("v1.2.3", 1, nil)
+		So(ok, ShouldBeTrue)
+		msg, ok := x.evaluate("1.2.3-dirty", 2, nil)
+		So(ok, ShouldBeFalse)
+		So(msg, ShouldContainSubstring, "exit-codes: got 2")
+		So(msg, ShouldContainSubstring, "stdout-matches")
+		So(msg, ShouldContainSubstring, "stdout-not-matches: output matches /dirty/ at (dirty)")
+	})
+
+	Convey("expectations should ignore warnings on stderr", t, func() {
+		b := &CheckBlock{
+			Try: `echo "warning: deprecated" >&2; echo '{"version": "1.2.3"}'`,
+			Expect: &CheckExpect{
+				StdoutEquals: `echo "noise" >&2; echo '{"version": "1.2.3"}'`,
+				Json:         []PathExpect{{Path: ".version", Equals: "1.2.3"}},
+			},
+		}
+		So(runTry(CheckGroup{}, b, 0, nil), ShouldBeNil)
+		So(b.TryOk, ShouldBeTrue)
+		So(b.TryStdOutErr, ShouldContainSubstring, "warning: deprecated")
+	})
+}
@@ -0,0 +1,104 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+// package check creates a try-catch-finally block of scripts
+
+package check
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/mrmxf/clog/ux"
+	"github.com/mrmxf/clog/ux/ui"
+)
+
+// fixTally counts the outcome of each fix attempted in a group
+type fixTally struct {
+	Fixed   []string
+	Failing []string
+	Skipped []string
+}
+
+// interactive is true if the user can be asked to confirm a fix i.e. stdin
+// is a terminal
+var interactive = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmFix asks the user before running a fix script unless --yes is set.
+// Without a terminal (e.g. in CI) the fix is skipped.
+func confirmFix(i int, b *CheckBlock) bool {
+	if AssumeYes {
+		return true
+	}
+	if !interactive() {
+		slog.Debug("not interactive - use --yes to run fix scripts")
+		return false
+	}
+	formData := ux.ActionFormData{
+		Title: fmt.Sprintf("Run fix for %s?", blockName(i, b)),
+		Description: []string{
+			"try failed with exit code " + fmt.Sprintf("%d", b.TryExitCode),
+			"fix: " + b.Fix,
+		},
+	}
+	chosen, err := ui.ActionForm(&formData)
+	if err != nil {
+		slog.Debug("fix confirmation failed", "err", err)
+		return false
+	}
+	// options are No(0) and Yes(1)
+	return chosen == 1
+}
+
+// fixBlock runs the fix script of a block whose try has failed and then
+// re-runs the try to see if the fix worked. The block is updated with the
+// result of the re-try so that ok/catch behave as though it was the first try.
+// The returned error is the error from the last try that ran (tryErr if the
+// fix was not run).
+func fixBlock(group CheckGroup, b *CheckBlock, i int, tally *fixTally, tryErr error, out *blockOutput) error {
+	name := blockName(i, b)
+	if len(b.Fix) == 0 {
+		return tryErr
+	}
+	if !confirmFix(i, b) {
+		slog.Warn(fmt.Sprintf("   fix skipped for %s", name))
+		tally.Skipped = append(tally.Skipped, name)
+		return tryErr
+	}
+
+	env := tryEnv(b, tryErr)
+	b.FixExitCode, _ = stream(group.Before, b.Fix, i, "fix", env, out)
+	if b.FixExitCode != 0 {
+		slog.Debug(fmt.Sprintf("   fix for %s exited with %d", name, b.FixExitCode))
+	}
+
+	// re-run the try to see if the fix worked
+	err := runTry(group, b, i, out)
+	if b.TryOk {
+		slog.Info(fmt.Sprintf("Ok fixed %s", name))
+		tally.Fixed = append(tally.Fixed, name)
+		return nil
+	}
+	slog.Warn(fmt.Sprintf("   still failing after fix %s", name))
+	tally.Failing = append(tally.Failing, name)
+	return err
+}
+
+// report a summary of all fixes attempted in the group
+func (t *fixTally) report(groupName string) {
+	total := len(t.Fixed) + len(t.Failing) + len(t.Skipped)
+	if total == 0 {
+		return
+	}
+	msg := fmt.Sprintf("Check %s fix: %d fixed, %d still failing, %d skipped",
+		groupName, len(t.Fixed), len(t.Failing), len(t.Skipped))
+	if len(t.Failing) > 0 {
+		slog.Warn(msg, "failing", t.Failing)
+		return
+	}
+	slog.Info(msg)
+}
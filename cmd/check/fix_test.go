@@ -0,0 +1,76 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFixBlock(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "fixed")
+	newBlock := func(fix string) *CheckBlock {
+		return &CheckBlock{Name: "needs-fix", Try: "test -f " + marker, Fix: fix}
+	}
+	defer func(yes bool, i func() bool) { AssumeYes, interactive = yes, i }(AssumeYes, interactive)
+
+	Convey("a fix should be run, the try re-run & the result tallied", t, func() {
+		os.Remove(marker)
+		AssumeYes = true
+		tally := fixTally{}
+		b := newBlock("touch " + marker)
+		tryErr := runTry(CheckGroup{}, b, 0, nil)
+		So(b.TryOk, ShouldBeFalse)
+
+		err := fixBlock(CheckGroup{}, b, 0, &tally, tryErr, nil)
+		So(err, ShouldBeNil)
+		So(b.TryOk, ShouldBeTrue)
+		So(b.TryExitCode, ShouldEqual, 0)
+		So(tally.Fixed, ShouldResemble, []string{"needs-fix"})
+		So(tally.Failing, ShouldBeEmpty)
+	})
+
+	Convey("a fix that does not work should be tallied as still failing", t, func() {
+		os.Remove(marker)
+		AssumeYes = true
+		tally := fixTally{}
+		b := newBlock("exit 3")
+		tryErr := runTry(CheckGroup{}, b, 0, nil)
+
+		err := fixBlock(CheckGroup{}, b, 0, &tally, tryErr, nil)
+		So(err, ShouldNotBeNil)
+		So(b.TryOk, ShouldBeFalse)
+		So(b.FixExitCode, ShouldEqual, 3)
+		So(tally.Failing, ShouldResemble, []string{"needs-fix"})
+	})
+
+	Convey("without --yes or a terminal the fix should be skipped", t, func() {
+		os.Remove(marker)
+		AssumeYes = false
+		interactive = func() bool { return false }
+		tally := fixTally{}
+		b := newBlock("touch " + marker)
+		tryErr := runTry(CheckGroup{}, b, 0, nil)
+
+		err := fixBlock(CheckGroup{}, b, 0, &tally, tryErr, nil)
+		So(err, ShouldEqual, tryErr)
+		So(tally.Skipped, ShouldResemble, []string{"needs-fix"})
+		_, statErr := os.Stat(marker)
+		So(os.IsNotExist(statErr), ShouldBeTrue)
+	})
+
+	Convey("a block without a fix should not be tallied", t, func() {
+		tally := fixTally{}
+		b := newBlock("")
+		tryErr := runTry(CheckGroup{}, b, 0, nil)
+		So(fixBlock(CheckGroup{}, b, 0, &tally, tryErr, nil), ShouldEqual, tryErr)
+		So(tally.Fixed, ShouldBeEmpty)
+		So(tally.Skipped, ShouldBeEmpty)
+	})
+}
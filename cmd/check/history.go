@@ -0,0 +1,342 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+// package check creates a try-catch-finally block of scripts
+
+package check
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cobra"
+)
+
+// config key for the history file. An empty value disables history.
+var HistoryKey = "clog.check-history"
+
+// the default history file if the config key is not set
+var defaultHistoryPath = ".clog/check-history.jsonl"
+
+// block status values stored in the history
+const (
+	StatusPass  = "pass"
+	StatusFail  = "fail"
+	StatusFixed = "fixed"
+)
+
+// BlockResult is one line in the check history file. Only blocks with a try
+// or an assert are recorded.
+type BlockResult struct {
+	Time     time.Time `json:"time"`
+	Group    string    `json:"group"`
+	Block    string    `json:"block"`
+	Index    int       `json:"index"`
+	Commit   string    `json:"commit"`
+	Status   string    `json:"status"`
+	ExitCode int       `json:"exit-code"`
+	Duration int64     `json:"duration-ms"`
+	Flaky    bool      `json:"flaky,omitempty"`
+	Xfail    bool      `json:"xfail,omitempty"`
+}
+
+// Passed is true for passes and fixes
+func (r BlockResult) Passed() bool {
+	return r.Status == StatusPass || r.Status == StatusFixed
+}
+
+// the current git commit or an empty string if we are not in a git repo
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// the user state dir e.g. ~/.local/state/clog
+func userStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "clog"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "clog"), nil
+}
+
+// HistoryPath returns the path of the history file from config. A relative
+// path (like the default) is relative to the folder clog runs in, usually the
+// root of the repo. If its folder cannot be created then the user state dir
+// is used. An empty string means history is disabled.
+func HistoryPath() string {
+	path := defaultHistoryPath
+	if cfg := config.Cfg(); cfg != nil && cfg.IsSet(HistoryKey) {
+		path = cfg.GetString(HistoryKey)
+	}
+	if len(path) == 0 {
+		return ""
+	}
+	path = expandPath(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		return path
+	}
+	stateDir, err := userStateDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(stateDir, filepath.Base(path))
+}
+
+// appendHistory adds the results of a run to the history file
+func appendHistory(results []BlockResult) error {
+	path := HistoryPath()
+	if len(path) == 0 || len(results) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// write all lines in one go so that parallel runs do not interleave
+	lines := []byte{}
+	for _, r := range results {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	_, err = f.Write(lines)
+	return err
+}
+
+// readHistory returns all the results for a group in the order they were run
+func readHistory(path string, group string) ([]BlockResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	results := []BlockResult{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		r := BlockResult{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			slog.Debug(fmt.Sprintf("%s:%d skipping bad history line", path, lineNo), "err", err)
+			continue
+		}
+		if r.Group == group {
+			results = append(results, r)
+		}
+	}
+	return results, scanner.Err()
+}
+
+// BlockTrend summarises the history of one block in a group
+type BlockTrend struct {
+	Block           string  `json:"block"`
+	Runs            int     `json:"runs"`
+	PassRate        float64 `json:"pass-rate"`
+	RecentPassRate  float64 `json:"recent-pass-rate"`
+	LastStatus      string  `json:"last-status"`
+	LastFailCommit  string  `json:"last-fail-commit,omitempty"`
+	LastFailTime    string  `json:"last-fail-time,omitempty"`
+	LastDuration    int64   `json:"last-duration-ms"`
+	MedianDuration  int64   `json:"median-duration-ms"`
+	DurationRegress bool    `json:"duration-regression"`
+	Flaky           bool    `json:"flaky,omitempty"`
+	Xfail           bool    `json:"xfail,omitempty"`
+}
+
+// a duration is a regression if it is this much slower than the median of
+// the previous runs (and at least minRegressMs slower)
+const regressFactor = 1.5
+const minRegressMs = 100
+
+// summarise the history of each block, keeping the order blocks were seen
+func trends(results []BlockResult, recent int) []BlockTrend {
+	order := []string{}
+	byBlock := map[string][]BlockResult{}
+	for _, r := range results {
+		if _, seen := byBlock[r.Block]; !seen {
+			order = append(order, r.Block)
+		}
+		byBlock[r.Block] = append(byBlock[r.Block], r)
+	}
+
+	all := []BlockTrend{}
+	for _, name := range order {
+		runs := byBlock[name]
+		last := runs[len(runs)-1]
+		t := BlockTrend{
+			Block:        name,
+			Runs:         len(runs),
+			PassRate:     passRate(runs),
+			LastStatus:   last.Status,
+			LastDuration: last.Duration,
+			Flaky:        last.Flaky,
+			Xfail:        last.Xfail,
+		}
+		start := max(0, len(runs)-recent)
+		t.RecentPassRate = passRate(runs[start:])
+		for i := len(runs) - 1; i >= 0; i-- {
+			if !runs[i].Passed() {
+				t.LastFailCommit = runs[i].Commit
+				t.LastFailTime = runs[i].Time.Format(time.DateTime)
+				break
+			}
+		}
+		if len(runs) > 1 {
+			t.MedianDuration = medianDuration(runs[:len(runs)-1])
+			slower := t.LastDuration - t.MedianDuration
+			t.DurationRegress = slower >= minRegressMs &&
+				float64(t.LastDuration) > regressFactor*float64(t.MedianDuration)
+		} else {
+			t.MedianDuration = last.Duration
+		}
+		all = append(all, t)
+	}
+	return all
+}
+
+func passRate(runs []BlockResult) float64 {
+	if len(runs) == 0 {
+		return 0
+	}
+	pass := 0
+	for _, r := range runs {
+		if r.Passed() {
+			pass++
+		}
+	}
+	return float64(pass) / float64(len(runs))
+}
+
+func medianDuration(runs []BlockResult) int64 {
+	d := make([]int64, 0, len(runs))
+	for _, r := range runs {
+		d = append(d, r.Duration)
+	}
+	slices.Sort(d)
+	return d[len(d)/2]
+}
+
+// print the trends as a table
+func printTrends(group string, all []BlockTrend, path string) {
+	fmt.Printf("check history for %s (%s)\n", group, path)
+	fmt.Printf("%-30s %5s %6s %7s %-7s %-10s %8s %8s\n",
+		"block", "runs", "pass%", "recent%", "last", "last-fail", "last-ms", "median")
+	for _, tag := range []string{"", "flaky", "xfail"} {
+		for _, t := range all {
+			if trendTag(t) != tag {
+				continue
+			}
+			name := t.Block
+			if len(tag) > 0 {
+				name = name + " (" + tag + ")"
+			}
+			regress := ""
+			if t.DurationRegress {
+				regress = " slower"
+			}
+			lastFail := t.LastFailCommit
+			if len(lastFail) == 0 && len(t.LastFailTime) > 0 {
+				lastFail = "(no git)"
+			}
+			fmt.Printf("%-30s %5d %5.0f%% %6.0f%% %-7s %-10s %8d %8d%s\n",
+				name, t.Runs, 100*t.PassRate, 100*t.RecentPassRate, t.LastStatus,
+				lastFail, t.LastDuration, t.MedianDuration, regress)
+		}
+	}
+}
+
+// flaky & xfail blocks are reported separately
+func trendTag(t BlockTrend) string {
+	switch {
+	case t.Xfail:
+		return "xfail"
+	case t.Flaky:
+		return "flaky"
+	}
+	return ""
+}
+
+// show the history of a group
+func showHistory(group string, recent int, asJson bool) error {
+	path := HistoryPath()
+	if len(path) == 0 {
+		return errors.New("check history is disabled (" + HistoryKey + " is empty)")
+	}
+	results, err := readHistory(path, group)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no history for check group %s in %s", group, path)
+	}
+	all := trends(results, recent)
+	if asJson {
+		out, err := json.MarshalIndent(all, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printTrends(group, all, path)
+	return nil
+}
+
+// CLI flags for the history command
+var historyJson bool
+var historyRecent int
+
+// HistoryCommand shows the pass-rate trends of a check group. The history of
+// a group is kept after it is removed from config. A check group cannot be
+// called history.
+var HistoryCommand = &cobra.Command{
+	Use:   "history",
+	Short: "show pass-rate trends, last failing commit & slow blocks of a check group",
+	Example: `
+	clog Check history pre-build              # table of trends
+	clog Check history pre-build --recent 5   # recent pass rate over 5 runs
+	clog Check history pre-build --json       # machine readable`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			slog.Error("cannot show Check history - you must supply a check group e.g. clog Check history pre-build")
+			cmd.Help()
+			slogger.Exit(1)
+		}
+		if err := showHistory(args[0], historyRecent, historyJson); err != nil {
+			slog.Error(err.Error())
+			slogger.Exit(1)
+		}
+	},
+}
+
+func init() {
+	HistoryCommand.Flags().BoolVar(&historyJson, "json", false, "clog Check history my-group --json")
+	HistoryCommand.Flags().IntVar(&historyRecent, "recent", 10, "clog Check history my-group --recent 10  # runs in the recent pass rate")
+	Command.AddCommand(HistoryCommand)
+}
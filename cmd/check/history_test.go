@@ -0,0 +1,81 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHistory(t *testing.T) {
+	run := func(block string, status string, ms int64, commit string) BlockResult {
+		return BlockResult{Group: "pre-build", Block: block, Status: status, Duration: ms, Commit: commit, Time: time.Now()}
+	}
+
+	Convey("pass rates should count fixes as passes", t, func() {
+		So(passRate(nil), ShouldEqual, 0)
+		So(passRate([]BlockResult{run("a", StatusPass, 1, ""), run("a", StatusFixed, 1, ""),
+			run("a", StatusFail, 1, ""), run("a", StatusFail, 1, "")}), ShouldEqual, 0.5)
+	})
+
+	Convey("the median duration should be the middle of the sorted runs", t, func() {
+		So(medianDuration([]BlockResult{run("a", StatusPass, 30, "")}), ShouldEqual, 30)
+		So(medianDuration([]BlockResult{run("a", StatusPass, 90, ""), run("a", StatusPass, 10, ""),
+			run("a", StatusPass, 40, "")}), ShouldEqual, 40)
+	})
+
+	Convey("trends should summarise each block in the order it was seen", t, func() {
+		results := []BlockResult{
+			run("lint", StatusPass, 100, "aaa"),
+			run("test", StatusFail, 1000, "aaa"),
+			run("lint", StatusFail, 100, "bbb"),
+			run("test", StatusPass, 1000, "bbb"),
+			run("lint", StatusPass, 100, "ccc"),
+			run("test", StatusPass, 2000, "ccc"),
+		}
+		all := trends(results, 2)
+		So(all, ShouldHaveLength, 2)
+		lint, test := all[0], all[1]
+		So(lint.Block, ShouldEqual, "lint")
+		So(lint.Runs, ShouldEqual, 3)
+		So(lint.PassRate, ShouldAlmostEqual, 2.0/3.0)
+		So(lint.RecentPassRate, ShouldEqual, 0.5)
+		So(lint.LastStatus, ShouldEqual, StatusPass)
+		So(lint.LastFailCommit, ShouldEqual, "bbb")
+		So(lint.DurationRegress, ShouldBeFalse)
+		So(test.LastFailCommit, ShouldEqual, "aaa")
+		So(test.MedianDuration, ShouldEqual, 1000)
+		So(test.DurationRegress, ShouldBeTrue)
+	})
+
+	Convey("readHistory should return a group's results & skip bad lines", t, func() {
+		path := filepath.Join(t.TempDir(), "check-history.jsonl")
+		os.WriteFile(path, []byte(`{"group": "pre-build", "block": "lint", "status": "pass"}
+not json
+{"group": "release", "block": "tag", "status": "fail"}
+{"group": "pre-build", "block": "test", "status": "fail", "exit-code": 2}
+`), 0644)
+		results, err := readHistory(path, "pre-build")
+		So(err, ShouldBeNil)
+		So(results, ShouldHaveLength, 2)
+		So(results[0].Block, ShouldEqual, "lint")
+		So(results[1].ExitCode, ShouldEqual, 2)
+		_, err = readHistory(filepath.Join(t.TempDir(), "missing.jsonl"), "pre-build")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("history should be a subcommand of Check with its own flags", t, func() {
+		found, args, err := Command.Find([]string{"history", "pre-build"})
+		So(err, ShouldBeNil)
+		So(found, ShouldEqual, HistoryCommand)
+		So(args, ShouldResemble, []string{"pre-build"})
+		So(HistoryCommand.Flags().Lookup("json"), ShouldNotBeNil)
+		So(HistoryCommand.Flags().Lookup("recent"), ShouldNotBeNil)
+	})
+}
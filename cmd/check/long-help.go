@@ -21,8 +21,111 @@ For consistent checks:
  - use clog Log -E "message"   when a catch block returns error
  - use clog Log -W "message"   when a catch block returns success
  - the output of the try command is available in ok/catch as $STDOUTERR
+   (with the secrets of clog.env & clog.redact masked). stdout & stderr are
+   read separately so lines written to both at the same time may be swapped
  - the exit status of the try command is available in ok/catch as $EXITCODE
 
+Native assertions
+=================
+
+A block may use assert: instead of (or as well as) try:. Assertions are
+evaluated inside clog without spawning a shell. Every assertion listed must
+pass. If a try: is also given, it only runs when the assertions pass. A failed
+assertion behaves like a try with exit code 1 and the reason in $STDOUTERR.
+
+  assert: {file-exists: go.mod}                              # ~ and $VARS expanded
+  assert: {env-set: GHAT}                                    # set and not empty
+  assert: {tool-version: {cmd: go, constraint: ">=1.22"}}    # >= > <= < = !=
+  assert: {tcp: localhost:11998}                             # something listening
+  assert: {http: {url: "http://localhost:11998/", status: 200}}
+  assert: {config: {key: clog.log.style, equals: pretty}}    # omit equals for "is set"
+
+Expectations on try output
+==========================
+
+By default a try passes when its exit code is 0. An expect: section replaces
+that test. All the expectations given must be met. The stdout-*, json and
+yaml expectations use stdout only, trimmed of whitespace, so warnings on
+stderr do not break them ($STDOUTERR still has both). Failures are logged with a
+diff of expected against actual, which is also available in ok/catch as
+$EXPECTFAIL.
+
+  - try: clog git tag head
+    expect:
+      exit-codes: [0]                          # a single code or a list
+      stdout-matches: '^v[0-9]+'               # regex must match
+      stdout-not-matches: 'dirty'              # regex must not match
+      stdout-equals: clog git tag ref          # output of another command
+  - try: curl -s localhost:11998/api/version
+    expect:
+      json: [{path: .version, matches: '^[0-9]'}]
+  - try: clog Cat core.clog.yaml
+    expect:
+      yaml: [{path: clog.log.style, equals: pretty}]
+
+Fixing failed checks
+====================
+
+A block may have a fix script. It is only run with clog Check my-group --fix
+and only when the try script fails. After the fix is run, the try script is
+run again and the block is reported as fixed, still failing or skipped. The
+ok or catch script then runs using the result of the second try.
+
+Each fix is confirmed interactively unless --yes (-y) is given. Without a
+terminal (e.g. in CI) fixes are skipped unless --yes is given. The output
+and exit status of the failed try are available in fix as $STDOUTERR and
+$EXITCODE.
+
+Log files and artifacts
+=======================
+
+A group may set log-file, log-level and artifacts-dir:
+
+check:
+  my-group:
+    log-file: tmp/logs/my-group.log    # written as tmp/logs/my-group-<yyyymmdd-hhmmss>.log
+    log-level: debug                   # trace|debug|info|success|warn|error
+    artifacts-dir: tmp/artifacts       # tmp/artifacts/my-group/<nn>-<block>.stdout|.stderr
+
+The log file receives the log messages of the run at log-level plus all the
+output of every block, with ANSI colors stripped. The console output is
+unchanged. The stdout & stderr of each block are saved separately in the
+artifacts folder so that CI can upload them when a check fails.
+
+CI log sections
+===============
+
+In CI, a group with a section key is wrapped in a collapsible log section
+with its duration: ::group:: in GitHub Actions and section_start in GitLab CI.
+Nothing is added when clog runs locally. Warnings & errors become GitHub
+annotations.
+
+check:
+  my-group:
+    section: Pre-build checks     # or "true" to use the group name
+
+History, flaky & xfail blocks
+=============================
+
+Every run appends one line per block to the history file set by the
+clog.check-history config key (default .clog/check-history.jsonl). A relative
+path is relative to the folder clog runs in, usually the root of the repo, so
+add .clog/ to .gitignore. Set the key to an empty string to disable history.
+Show the trends of a group with (so a group cannot be called history):
+
+  clog Check history my-group              # pass rate, last failing commit, slow blocks
+  clog Check history my-group --json       # machine readable
+
+A block can be marked as tolerated so that its failure does not fail the
+group. Tolerated blocks are reported separately:
+
+      - name:  network-mirror
+        flaky: true           # known to be intermittent
+        try:   curl -sf https://mirror.example.com
+      - name:  known-bug
+        xfail: true           # expected to fail until the bug is fixed
+        try:   ./repro.sh
+
 Sample clog.yaml
 ================
 
@@ -34,6 +137,10 @@ check:
         ok:      clog Log -I "Ok working tree clean"
         catch:   clog Log -W "   working tree NOT clean"
 				finally: echo "git tree cleanliness complete"
+      - name: yq
+        try:   which yq 2>/dev/null
+        catch: clog Log -E "yq not present"; exit 1
+        fix:   sudo snap install yq
       - name: golang
         try: |
           vv="$(go version|cat go.mod|grep '^go '|grep -oE '[0-9]\.[0-9]+\.[0-9]+')" 
@@ -0,0 +1,150 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+// package check creates a try-catch-finally block of scripts
+
+package check
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+)
+
+// layout of the timestamp added to log file names
+const logFileTimeFormat = "20060102-150405"
+
+// blockOutput holds the writers that receive a copy of a block's output in
+// addition to the console. Any of the writers may be nil.
+type blockOutput struct {
+	LogStdout io.Writer // ANSI stripped copy to the group log file
+	LogStderr io.Writer // ANSI stripped copy to the group log file
+	Stdout    *os.File  // artifact file for stdout
+	Stderr    *os.File  // artifact file for stderr
+}
+
+// timestampedPath inserts a timestamp before the extension of a log file path
+// e.g. tmp/pre-build.log -> tmp/pre-build-20250321-145021.log
+func timestampedPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.Format(logFileTimeFormat) + ext
+}
+
+// openGroupLog creates the group's timestamped log file and tees the default
+// logger into it at the group's log level. The returned function restores the
+// default logger and closes the file.
+func openGroupLog(group *CheckGroup) (func(), error) {
+	if len(group.LogPath) == 0 {
+		return func() {}, nil
+	}
+	path := timestampedPath(expandPath(group.LogPath), time.Now())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return func() {}, err
+	}
+	fileLogger, file, err := slogger.NewTeeLogger(path, group.LogLevel)
+	if err != nil {
+		return func() {}, err
+	}
+	group.LogFile = file
+
+	console := slog.Default()
+	slog.SetDefault(slog.New(slogger.NewMultiHandler(console.Handler(), fileLogger.Handler())))
+	slog.Debug(fmt.Sprintf("Check %s logging to %s", group.Name, path))
+
+	return func() {
+		slog.SetDefault(console)
+		group.LogFile = nil
+		file.Close()
+	}, nil
+}
+
+// a file name friendly version of a string
+var reUnsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// newBlockOutput creates the writers for a block. Artifacts are saved in
+// <artifacts-dir>/<group>/<nn>-<block>.stdout and .stderr and are truncated
+// at the start of every run.
+func newBlockOutput(group *CheckGroup, i int, b *CheckBlock) *blockOutput {
+	out := &blockOutput{}
+	if group.LogFile != nil {
+		out.LogStdout = slogger.NewAnsiStripWriter(group.LogFile)
+		out.LogStderr = slogger.NewAnsiStripWriter(group.LogFile)
+		fmt.Fprintf(group.LogFile, "---------- %s ----------\n", blockName(i, b))
+	}
+	if len(group.ArtifactsDir) == 0 {
+		return out
+	}
+	groupDir := reUnsafeFileChars.ReplaceAllString(group.Key, "-")
+	dir := filepath.Join(expandPath(group.ArtifactsDir), groupDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		slog.Warn("cannot create check artifacts folder", "path", dir, "err", err)
+		return out
+	}
+	base := fmt.Sprintf("%02d-%s", i, reUnsafeFileChars.ReplaceAllString(blockName(i, b), "-"))
+	var err error
+	if out.Stdout, err = os.Create(filepath.Join(dir, base+".stdout")); err != nil {
+		slog.Warn("cannot create check artifact", "err", err)
+	}
+	if out.Stderr, err = os.Create(filepath.Join(dir, base+".stderr")); err != nil {
+		slog.Warn("cannot create check artifact", "err", err)
+	}
+	return out
+}
+
+// close the artifact files
+func (o *blockOutput) close() {
+	if o == nil {
+		return
+	}
+	if o.Stdout != nil {
+		o.Stdout.Close()
+	}
+	if o.Stderr != nil {
+		o.Stderr.Close()
+	}
+}
+
+// writers returns the stdout & stderr writers for a step. Captured steps (try)
+// are not shown on the console. nil is returned if there is nothing to write.
+func (o *blockOutput) writers(console bool) (io.Writer, io.Writer) {
+	if o == nil {
+		if console {
+			return os.Stdout, os.Stderr
+		}
+		return nil, nil
+	}
+	stdout := []io.Writer{}
+	stderr := []io.Writer{}
+	if console {
+		stdout = append(stdout, os.Stdout)
+		stderr = append(stderr, os.Stderr)
+	}
+	if o.LogStdout != nil {
+		stdout = append(stdout, o.LogStdout)
+		stderr = append(stderr, o.LogStderr)
+	}
+	if o.Stdout != nil {
+		stdout = append(stdout, o.Stdout)
+	}
+	if o.Stderr != nil {
+		stderr = append(stderr, o.Stderr)
+	}
+	return multi(stdout), multi(stderr)
+}
+
+func multi(writers []io.Writer) io.Writer {
+	switch len(writers) {
+	case 0:
+		return nil
+	case 1:
+		return writers[0]
+	}
+	return io.MultiWriter(writers...)
+}
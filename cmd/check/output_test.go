@@ -0,0 +1,86 @@
+//  Copyright ©2017-2025  Mr MXF  info@mrmxf.com
+//  BSD-3-Clause License          https://opensource.org/license/bsd-3-clause/
+//
+
+package check
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	Convey("log file names should be timestamped before the extension", t, func() {
+		at := time.Date(2025, 3, 21, 14, 50, 21, 0, time.UTC)
+		So(timestampedPath("tmp/pre-build.log", at), ShouldEqual, "tmp/pre-build-20250321-145021.log")
+		So(timestampedPath("tmp/pre-build", at), ShouldEqual, "tmp/pre-build-20250321-145021")
+	})
+
+	Convey("the group log should get the log records & block output without ANSI", t, func() {
+		group := CheckGroup{Name: "pre-build", LogPath: filepath.Join(dir, "logs", "pre-build.log"), LogLevel: slog.LevelInfo}
+		console := slog.Default()
+		restore, err := openGroupLog(&group)
+		So(err, ShouldBeNil)
+		So(group.LogFile, ShouldNotBeNil)
+		path := group.LogFile.Name()
+		slog.Info("logged to the group")
+		out := newBlockOutput(&group, 0, &CheckBlock{Name: "lint"})
+		stdout, stderr := out.writers(false)
+		stdout.Write([]byte("\x1b[32mgreen\x1b[0m\n"))
+		stderr.Write([]byte("warning\n"))
+		restore()
+		So(slog.Default(), ShouldEqual, console)
+		So(group.LogFile, ShouldBeNil)
+
+		log, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(log), ShouldContainSubstring, "logged to the group")
+		So(string(log), ShouldContainSubstring, "---------- lint ----------\ngreen\nwarning\n")
+	})
+
+	Convey("a group without a log file should not change the logger", t, func() {
+		console := slog.Default()
+		restore, err := openGroupLog(&CheckGroup{})
+		So(err, ShouldBeNil)
+		So(slog.Default(), ShouldEqual, console)
+		restore()
+	})
+
+	Convey("artifacts should be saved per block & truncated for every run", t, func() {
+		group := CheckGroup{Key: "check.pre build", ArtifactsDir: filepath.Join(dir, "artifacts")}
+		artifact := filepath.Join(dir, "artifacts", "check.pre-build", "01-go-vet")
+		for _, text := range []string{"first run\n", "second\n"} {
+			out := newBlockOutput(&group, 1, &CheckBlock{Name: "go vet"})
+			stdout, stderr := out.writers(false)
+			stdout.Write([]byte(text))
+			stderr.Write([]byte("err " + text))
+			out.close()
+		}
+		got, err := os.ReadFile(artifact + ".stdout")
+		So(err, ShouldBeNil)
+		So(string(got), ShouldEqual, "second\n")
+		got, err = os.ReadFile(artifact + ".stderr")
+		So(err, ShouldBeNil)
+		So(string(got), ShouldEqual, "err second\n")
+	})
+
+	Convey("writers should only return what there is to write to", t, func() {
+		var none *blockOutput
+		stdout, stderr := none.writers(false)
+		So(stdout, ShouldBeNil)
+		So(stderr, ShouldBeNil)
+		stdout, stderr = none.writers(true)
+		So(stdout, ShouldEqual, os.Stdout)
+		So(stderr, ShouldEqual, os.Stderr)
+		stdout, stderr = (&blockOutput{}).writers(false)
+		So(stdout, ShouldBeNil)
+		So(stderr, ShouldBeNil)
+	})
+}
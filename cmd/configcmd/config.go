@@ -0,0 +1,243 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package configcmd adds a Config command to the clog command line tool
+
+package configcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// CLI flags
+var output string
+var prefix string
+
+// Command define the cobra settings for this command
+var Command = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "Config",
+	Short:         "show the effective config & where each value came from",
+	Long: `Show the config after the embedded core.clog.yaml has been overlaid with every
+file in clog.clogrc.search-paths (and --config). explain shows every file that
+defines a key in merge order with the line of the key and which one won.
+validate checks every file against the embedded JSON Schema - use --strict
+(or clog.clogrc.strict: true) to validate at startup. watch reloads the config
+like a long running service does and rejects a change that does not validate.
+Secrets are masked and secret:// references are shown, not resolved.`,
+	Example: `
+	clog Config get clog.log.level
+	clog Config list --prefix clog.log
+	clog Config explain clog.log.style -o json
+	clog Config profiles                       # * marks the active profile
+	clog Config validate                       # file:line:column: key: error
+	clog Config validate clogrc/clog.yaml
+	clog Config watch                          # reload on change & show the changes
+	clog Config schema > clog.schema.json      # for editor completion e.g.
+	# yaml-language-server: $schema=./clog.schema.json
+	`,
+}
+
+var getCommand = &cobra.Command{
+	Use:   "get <key>",
+	Short: "show the effective value of a key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		if !cfg.IsSet(args[0]) {
+			slogger.Error("clog Config get: " + args[0] + " is not set")
+			slogger.Exit(1)
+		}
+		show(cfg.Viper.Get(args[0]))
+	},
+}
+
+var listCommand = &cobra.Command{
+	Use:   "list",
+	Short: "show every key & its effective value",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		values := map[string]any{}
+		for _, key := range cfg.AllKeys() {
+			if strings.HasPrefix(key, strings.ToLower(prefix)) {
+				values[key] = cfg.Viper.Get(key)
+			}
+		}
+		show(values)
+	},
+}
+
+// explanation is the output of explain
+type explanation struct {
+	Key    string              `json:"key" yaml:"key"`
+	Value  any                 `json:"value" yaml:"value"`
+	Layers []config.Definition `json:"layers" yaml:"layers"`
+}
+
+var explainCommand = &cobra.Command{
+	Use:   "explain <key>",
+	Short: "show every layer that defines a key & which one won",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		definitions := cfg.Explain(args[0])
+		if len(definitions) == 0 {
+			slogger.Error("clog Config explain: " + args[0] + " is not defined in any layer")
+			for _, layer := range config.Layers() {
+				slogger.Info("searched " + layer.Source)
+			}
+			slogger.Exit(1)
+		}
+		show(explanation{Key: strings.ToLower(args[0]), Value: cfg.Viper.Get(args[0]), Layers: definitions})
+	},
+}
+
+var profilesCommand = &cobra.Command{
+	Use:   "profiles",
+	Short: "list the profiles & the profiles they extend",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		for _, name := range cfg.Profiles() {
+			mark := " "
+			if name == config.Profile() {
+				mark = "*"
+			}
+			chain, err := cfg.ProfileChain(name)
+			if err != nil {
+				fmt.Printf("%s %s (%s)\n", mark, name, err.Error())
+				continue
+			}
+			fmt.Printf("%s %s\n", mark, strings.Join(chain, " ⇒ "))
+		}
+	},
+}
+
+var validateCommand = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "check the config files against the schema",
+	Run: func(cmd *cobra.Command, args []string) {
+		errs, err := validate(args)
+		if err != nil {
+			slogger.Error("clog Config validate: " + err.Error())
+			slogger.Exit(1)
+		}
+		if cmd.Flags().Changed("output") {
+			show(errs)
+		} else {
+			for _, e := range errs {
+				fmt.Println(slogger.Redact(e.Error()))
+			}
+		}
+		if len(errs) > 0 {
+			slogger.Error(fmt.Sprintf("clog Config validate: %d errors", len(errs)))
+			slogger.Exit(1)
+		}
+		if !cmd.Flags().Changed("output") {
+			slogger.Success("clog Config validate: no errors")
+		}
+	},
+}
+
+var watchCommand = &cobra.Command{
+	Use:   "watch",
+	Short: "reload the config when a file changes & show the keys that changed",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		config.Subscribe("", func(cfg *config.Config, changed []string) {
+			values := map[string]any{}
+			for _, key := range changed {
+				values[key] = cfg.Viper.Get(key)
+			}
+			show(values)
+		})
+		for _, file := range config.ConfigFiles() {
+			slogger.Debug("watching " + file)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := config.Watch(ctx); err != nil {
+			slogger.Error("clog Config watch: " + err.Error())
+			slogger.Exit(1)
+		}
+	},
+}
+
+var schemaCommand = &cobra.Command{
+	Use:   "schema",
+	Short: "print the JSON Schema of clog.yaml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		body, err := config.SchemaJSON()
+		if err != nil {
+			slogger.Error("clog Config schema: " + err.Error())
+			slogger.Exit(1)
+		}
+		fmt.Print(string(body))
+	},
+}
+
+// validate checks the files or, if there are none, the layers of the config
+func validate(files []string) ([]config.SchemaError, error) {
+	if len(files) == 0 {
+		return config.Cfg().Validate()
+	}
+	schema, err := config.ClogSchema()
+	if err != nil {
+		return nil, err
+	}
+	errs := []config.SchemaError{}
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		layer, _ := config.NewLayer(file, body)
+		errs = append(errs, schema.Validate(layer)...)
+	}
+	return errs, nil
+}
+
+// show writes v as yaml or json with the secrets masked
+func show(v any) {
+	var body []byte
+	var err error
+	switch output {
+	case "json":
+		body, err = json.MarshalIndent(v, "", "  ")
+		body = append(body, '\n')
+	case "yaml":
+		body, err = yaml.Marshal(v)
+	default:
+		err = fmt.Errorf("bad --output (%s) use yaml or json", output)
+	}
+	if err != nil {
+		slogger.Error("clog Config: " + err.Error())
+		slogger.Exit(1)
+	}
+	fmt.Print(slogger.Redact(string(body)))
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+
+	Command.PersistentFlags().StringVarP(&output, "output", "o", "yaml", "yaml | json")
+	listCommand.Flags().StringVar(&prefix, "prefix", "", "clog Config list --prefix clog.log")
+	Command.AddCommand(getCommand, listCommand, explainCommand, profilesCommand, validateCommand, watchCommand, schemaCommand)
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/mrmxf/clog/config"
 	"github.com/mrmxf/clog/core"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -31,21 +32,21 @@ var Command = &cobra.Command{
 		src, err := clogFs.Open(core.Clean(srcPath))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 		defer src.Close()
 
 		dst, err := os.Create(dstPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 		defer dst.Close()
 
 		nBytes, err := io.Copy(dst, src)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s (%d bytes copied)\n", err, nBytes)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 	},
 }
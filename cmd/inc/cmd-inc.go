@@ -14,6 +14,7 @@ import (
 	"runtime"
 
 	"github.com/mrmxf/clog/crayon"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -38,18 +39,21 @@ var Command = &cobra.Command{
 		src, err := IncFs.Open("inc.sh")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 		defer src.Close()
 
-		// start with the color helpers
+		// start with the color helpers - the same dark mode as the log theme
+		if !cmd.Flags().Changed("darkmode") {
+			DarkMode = crayon.DetectDarkMode()
+		}
 		fmt.Println(crayon.GetBashString(DarkMode))
 
 		dst := os.Stdout
 		nBytes, err := io.Copy(dst, src)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%s (%d bytes copied)\n", err, nBytes)
-			os.Exit(126)
+			slogger.Exit(126)
 		}
 	},
 }
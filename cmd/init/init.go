@@ -17,6 +17,7 @@ import (
 	"runtime"
 
 	"github.com/mrmxf/clog/cmd/copy"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -38,7 +39,7 @@ var Command = &cobra.Command{
 			slog.Warn("creating folder " + dstFolder)
 			if err = os.MkdirAll(dstFolder, 0755); err != nil {
 				slog.Error("failed to create folder " + dstFolder)
-				os.Exit(1)
+				slogger.Exit(1)
 			}
 		}
 
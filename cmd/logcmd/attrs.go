@@ -0,0 +1,36 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package log adds a log command to the clog command line tool
+
+package logcmd
+
+import (
+	"strings"
+
+	slog "github.com/mrmxf/clog/slogger"
+)
+
+// CLI flags for structured attributes
+var attrsJson string
+var attrsGroup string
+
+// logArgs splits the args into the message and the attributes from trailing
+// key=value pairs and --attrs-json. Bad json is logged and clog exits with 1.
+func logArgs(args []string) (string, []any) {
+	words, attrs := slog.ParseAttrArgs(args)
+	if len(attrsJson) > 0 {
+		jsonAttrs, err := slog.ParseAttrsJSON(attrsJson)
+		if err != nil {
+			slog.Error("clog Log --attrs-json: " + err.Error())
+			slog.Exit(1)
+		}
+		attrs = append(attrs, jsonAttrs...)
+	}
+	return strings.Join(words, " "), slog.AttrArgs(attrsGroup, attrs)
+}
+
+func init() {
+	Command.PersistentFlags().StringVar(&attrsJson, "attrs-json", "", "clog Log -I \"deployed\" --attrs-json '{\"env\":\"prod\",\"replicas\":3}'")
+	Command.PersistentFlags().StringVar(&attrsGroup, "group", "", "clog Log -I \"deployed\" --group deploy env=prod  # deploy.env=prod")
+}
@@ -0,0 +1,106 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package log adds a log command to the clog command line tool
+
+package logcmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	slog "github.com/mrmxf/clog/slogger"
+	media "github.com/mrmxf/clog/slogger-media"
+)
+
+// CLI flags for SMPTE ST 2126 job events
+var jobStart bool
+var jobUpdate bool
+var jobEnd bool
+var functionStart bool
+var functionEnd bool
+var jobId string
+var jobType string
+var jobProfile string
+var jobStatus string
+var jobError string
+var started string
+
+// isJobEvent is true if any of the job event flags are set
+func isJobEvent() bool {
+	return jobStart || jobUpdate || jobEnd || functionStart || functionEnd
+}
+
+// parseStarted accepts ISO 8601 dates (e.g. `date -Iseconds`) or unix seconds
+func parseStarted(s string) (time.Time, bool) {
+	for _, layout := range []string{media.DateFormat, time.RFC3339Nano, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), true
+	}
+	return time.Time{}, false
+}
+
+// logJobEvent emits a job event. Each clog Log is a new process so the start
+// of a job or function must be passed with --started to get a duration.
+// Bad flags are logged and clog exits with 1.
+func logJobEvent(msg string, attrs []any) {
+	if len(jobId) == 0 {
+		slog.Error("clog Log job events need --job-id")
+		slog.Exit(1)
+	}
+	job := &media.JobInfo{
+		Id:          media.URL(jobId),
+		Type:        jobType,
+		ProfileName: jobProfile,
+		Status:      media.JobStatusEnum(strings.ToUpper(jobStatus)),
+	}
+	if len(jobError) > 0 {
+		job.Error = media.NewErrorInfo(jobError, 500)
+	}
+	var t0 time.Time
+	if len(started) > 0 {
+		var ok bool
+		if t0, ok = parseStarted(started); !ok {
+			slog.Error(fmt.Sprintf("clog Log cannot parse --started %s (use ISO 8601 or unix seconds)", started))
+			slog.Exit(1)
+		}
+		job.ActualStartDate = t0.Format(media.DateFormat)
+	}
+
+	logger := slog.Default()
+	switch {
+	case jobStart:
+		job.Start(logger, msg, attrs...)
+	case jobUpdate:
+		job.Update(logger, job.Status, msg, attrs...)
+	case jobEnd:
+		job.End(logger, msg, attrs...)
+	case functionStart:
+		job.FunctionStart(logger, msg)
+	case functionEnd:
+		if t0.IsZero() {
+			t0 = time.Now()
+		}
+		job.FunctionEnd(logger, msg, t0)
+	}
+}
+
+func init() {
+	Command.PersistentFlags().BoolVar(&jobStart, "job-start", false, "clog Log --job-start --job-id job0001 \"transcode\"")
+	Command.PersistentFlags().BoolVar(&jobUpdate, "job-update", false, "clog Log --job-update --job-id job0001 --job-status RUNNING \"50%\"")
+	Command.PersistentFlags().BoolVar(&jobEnd, "job-end", false, "clog Log --job-end --job-id job0001 --started \"$t0\" \"done\"")
+	Command.PersistentFlags().BoolVar(&functionStart, "function-start", false, "clog Log --function-start --job-id job0001 \"encode\"")
+	Command.PersistentFlags().BoolVar(&functionEnd, "function-end", false, "clog Log --function-end --job-id job0001 --started \"$t1\" \"encode\"")
+	Command.PersistentFlags().StringVar(&jobId, "job-id", "", "id (URL) of the job for job events")
+	Command.PersistentFlags().StringVar(&jobType, "job-type", "", "type of the job e.g. TranscodeJob")
+	Command.PersistentFlags().StringVar(&jobProfile, "job-profile", "", "name of the job profile")
+	Command.PersistentFlags().StringVar(&jobStatus, "job-status", "", "NEW|QUEUED|RUNNING|COMPLETED|FAILED|CANCELLED")
+	Command.PersistentFlags().StringVar(&jobError, "job-error", "", "error detail - a job-end with an error is FAILED")
+	Command.PersistentFlags().StringVar(&started, "started", "", "ISO 8601 start time of the job or function e.g. \"$(date -Iseconds)\"")
+}
@@ -0,0 +1,52 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package logcmd_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/cmd/logcmd"
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/cobra"
+)
+
+func TestJobEvents(t *testing.T) {
+	defer func(def *slog.Logger, logger *slog.Logger, base slog.Level, stderr *os.File) {
+		slog.SetDefault(def)
+		slogger.Logger = logger
+		slogger.LogLevels().SetBase(base)
+		os.Stderr = stderr
+	}(slog.Default(), slogger.Logger, slogger.LogLevels().Base(), os.Stderr)
+
+	Convey("function events should be logged at the default info level", t, func() {
+		path := filepath.Join(t.TempDir(), "stderr")
+		stderr, err := os.Create(path)
+		So(err, ShouldBeNil)
+		os.Stderr = stderr
+		slogger.UseJobLogger(slogger.LevelInfo)
+
+		// run as clog does - a root with subcommands would reject the message
+		root := &cobra.Command{Use: "clog"}
+		root.AddCommand(logcmd.Command)
+		for _, args := range [][]string{
+			{"--function-start", "--job-id", "job0001", "encode"},
+			// the flags are package vars so the first event must be cleared
+			{"--function-start=false", "--function-end", "--job-id", "job0001", "--started", "2025-03-21T14:50:21Z", "encode"},
+		} {
+			root.SetArgs(append([]string{"Log"}, args...))
+			So(root.Execute(), ShouldBeNil)
+		}
+		stderr.Close()
+
+		out, err := os.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(out), ShouldContainSubstring, `"level":"FUNCTION_START","levelCode":450`)
+		So(string(out), ShouldContainSubstring, `"level":"FUNCTION_END","levelCode":450`)
+		So(string(out), ShouldContainSubstring, `"function":"encode"`)
+	})
+}
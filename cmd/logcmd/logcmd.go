@@ -7,9 +7,7 @@ package logcmd
 
 import (
 	"fmt"
-	"os"
 	"runtime"
-	"strings"
 
 	slog "github.com/mrmxf/clog/slogger"
 
@@ -42,9 +40,39 @@ var Command = &cobra.Command{
 	clog Log -X  "emergency message"
 	clog Log -UI "up one line (overprint) an info message"
 	clog Log -B "$errCount" "$isProduction" "Base-Message"
+
+	# trailing key=value pairs become typed attributes
+	clog Log -I "deployed" env=prod version=$V replicas=3 duration=3.2s
+	clog Log -I "deployed" --group deploy --attrs-json '{"env":"prod","tags":{"canary":true}}'
+
+	# collapsible sections (GitHub ::group::, GitLab section_start, indented locally)
+	clog Log --begin "Build"
+	clog Log -I "compiling"
+	clog Log --end                                     # logs "Build took 3.2s"
+	clog Log -W "deprecated call" --file main.go --line 12   # ::warning in GitHub Actions
+
+	# SMPTE ST 2126 job events (use clog.log.style: job for job records)
+	t0="$(date -Iseconds)"
+	clog Log --job-start  --job-id job0001 --job-type Transcode "start transcode"
+	clog Log --job-update --job-id job0001 --job-status RUNNING "50% done"
+	clog Log --job-end    --job-id job0001 --started "$t0" "transcode complete"
+	clog Log --job-end    --job-id job0001 --started "$t0" --job-error "disk full" "transcode failed"
+
+	# pretty print & filter json or job style log files
+	clog Log view tmp/clog.json --min warn --where env=prod --since 1h
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
-		logMsg := strings.Join(args, " ")
+		if len(beginSection) > 0 || endSection {
+			logSection()
+			return
+		}
+		logMsg, attrs := logArgs(args)
+		logMsg = sectionIndent(logMsg)
+		attrs = annotationAttrs(attrs)
+		if isJobEvent() {
+			logJobEvent(logMsg, attrs)
+			return
+		}
 		// most serious flag wins
 		logFlag := "none"
 
@@ -55,28 +83,28 @@ var Command = &cobra.Command{
 		// if user has many falgs, then the top-most case statement wins
 		switch {
 		case emergency:
-			slog.Emergency(logMsg)
+			slog.Emergency(logMsg, attrs...)
 			logFlag = "X"
 		case fatal:
-			slog.Fatal(logMsg)
+			slog.Fatal(logMsg, attrs...)
 			logFlag = "F"
 		case error:
-			slog.Error(logMsg)
+			slog.Error(logMsg, attrs...)
 			logFlag = "E"
 		case warn:
-			slog.Warn(logMsg)
+			slog.Warn(logMsg, attrs...)
 			logFlag = "W"
 		case success:
-			slog.Success(logMsg)
+			slog.Success(logMsg, attrs...)
 			logFlag = "S"
 		case info:
-			slog.Info(logMsg)
+			slog.Info(logMsg, attrs...)
 			logFlag = "I"
 		case trace:
-			slog.Trace(logMsg)
+			slog.Trace(logMsg, attrs...)
 			logFlag = "T"
 		case debug:
-			slog.Debug(logMsg)
+			slog.Debug(logMsg, attrs...)
 			logFlag = "D"
 		case build:
 			// a special case.
@@ -91,7 +119,7 @@ var Command = &cobra.Command{
 				slog.Error("   arg[1]      \"$doPROD\" empty string for tolerant dev mode otherwise any string for fragile PROD mode")
 				slog.Error("   arg[2]   \"OK message\" string to be logged for $?=0")
 				slog.Error("   arg[3]  \"Err Message\" string to be logged for $?=0")
-				os.Exit(1)
+				slog.Exit(1)
 			}
 			errInfo := fmt.Sprintf(" {err:%d, prod:\"%s\"}", exitCode, args[1])
 			if exitCode == 0 {
@@ -99,7 +127,7 @@ var Command = &cobra.Command{
 			} else if len(args[1]) > 0 {
 				// fragile production mode
 				slog.Error(args[3] + errInfo)
-				os.Exit(1)
+				slog.Exit(1)
 			} else {
 				// fragile production mode
 				slog.Warn(args[3] + errInfo)
@@ -107,7 +135,7 @@ var Command = &cobra.Command{
 			logFlag = "B"
 		}
 		// level, levelFile := slogger.GetLogLevel()
-		slog.Debug("Log (-%s) (%s)", logFlag, logMsg)
+		slog.Debug(fmt.Sprintf("Log (-%s) (%s)", logFlag, logMsg))
 	},
 }
 
@@ -0,0 +1,108 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package log adds a log command to the clog command line tool
+
+package logcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	slog "github.com/mrmxf/clog/slogger"
+)
+
+// CLI flags for collapsible sections & annotations
+var beginSection string
+var endSection bool
+var annotateFile string
+var annotateLine int
+
+// every clog Log is a new process so the open sections are kept in a file.
+// Inside a clog run (e.g. a clog Check or a clog script) the file is shared by
+// every process of the run. Otherwise it is shared by the clog Log calls from
+// the same shell (parent process). Set CLOG_SECTIONS to a file path to share
+// sections between shells.
+func sectionsPath() string {
+	if path := os.Getenv("CLOG_SECTIONS"); len(path) > 0 {
+		return path
+	}
+	name := fmt.Sprintf("clog-sections-%d.json", os.Getppid())
+	if run := slog.CurrentRun(); run.Depth > 0 {
+		name = "clog-sections-" + run.ID + ".json"
+	}
+	return filepath.Join(os.TempDir(), name)
+}
+
+func readSections() []*slog.Section {
+	sections := []*slog.Section{}
+	body, err := os.ReadFile(sectionsPath())
+	if err == nil {
+		json.Unmarshal(body, &sections)
+	}
+	return sections
+}
+
+func writeSections(sections []*slog.Section) {
+	if len(sections) == 0 {
+		os.Remove(sectionsPath())
+		return
+	}
+	body, _ := json.Marshal(sections)
+	if err := os.WriteFile(sectionsPath(), body, 0644); err != nil {
+		slog.Warn("clog Log cannot save sections: " + err.Error())
+	}
+}
+
+// logSection opens or closes a section. The section markers go to stdout
+// with the output of the commands inside the section.
+func logSection() {
+	sections := readSections()
+	ci := slog.DetectCI()
+	if len(beginSection) > 0 {
+		s := slog.NewSection(beginSection, len(sections))
+		s.Begin(os.Stdout, ci)
+		writeSections(append(sections, s))
+		return
+	}
+	if len(sections) == 0 {
+		slog.Warn("clog Log --end: no section is open")
+		return
+	}
+	last := sections[len(sections)-1]
+	last.End(os.Stdout, ci)
+	writeSections(sections[:len(sections)-1])
+}
+
+// sectionIndent indents local messages inside open sections
+func sectionIndent(msg string) string {
+	if slog.DetectCI() != slog.CINone {
+		return msg
+	}
+	sections := readSections()
+	if len(sections) == 0 {
+		return msg
+	}
+	return sections[len(sections)-1].Indent() + msg
+}
+
+// annotationAttrs adds the --file & --line used for CI annotations
+func annotationAttrs(attrs []any) []any {
+	if len(annotateFile) == 0 {
+		return attrs
+	}
+	attrs = append(attrs, "file", annotateFile)
+	if annotateLine > 0 {
+		attrs = append(attrs, "line", annotateLine)
+	}
+	return attrs
+}
+
+func init() {
+	Command.PersistentFlags().StringVar(&beginSection, "begin", "", "clog Log --begin \"Build\"  # start a collapsible section")
+	Command.PersistentFlags().BoolVar(&endSection, "end", false, "clog Log --end            # end the section & log its duration")
+	Command.PersistentFlags().StringVar(&annotateFile, "file", "", "clog Log -W \"bad\" --file src/main.go --line 12  # CI annotation position")
+	Command.PersistentFlags().IntVar(&annotateLine, "line", 0, "line number for the CI annotation (needs --file)")
+}
@@ -0,0 +1,40 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package logcmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/cmd/logcmd"
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/cobra"
+)
+
+func TestSections(t *testing.T) {
+	defer slogger.SetRun(slogger.CurrentRun())
+	t.Setenv("CLOG_SECTIONS", "")
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	Convey("the open sections of a nested clog should be kept per run", t, func() {
+		slogger.SetRun(slogger.RunInfo{ID: "testrun0", Span: "b7d04e55", Parent: "3f2a9c1e", Depth: 1})
+		path := filepath.Join(os.TempDir(), "clog-sections-testrun0.json")
+		os.Remove(path)
+
+		root := &cobra.Command{Use: "clog"}
+		root.AddCommand(logcmd.Command)
+		root.SetArgs([]string{"Log", "--begin", "Build"})
+		So(root.Execute(), ShouldBeNil)
+		_, err := os.Stat(path)
+		So(err, ShouldBeNil)
+
+		// the flags are package vars so the begin must be cleared
+		root.SetArgs([]string{"Log", "--begin=", "--end"})
+		So(root.Execute(), ShouldBeNil)
+		_, err = os.Stat(path)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+}
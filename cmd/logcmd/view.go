@@ -0,0 +1,171 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package log adds a log command to the clog command line tool
+
+package logcmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	slog "github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags for clog Log view
+var viewMin string
+var viewSince string
+var viewUntil string
+var viewWhere []string
+var viewFollow bool
+var viewOutput string
+
+// how often a followed file is checked for new lines
+var followInterval = 250 * time.Millisecond
+
+// ViewCommand renders JSON log lines (json or job style) as pretty text
+var ViewCommand = &cobra.Command{
+	Use:   "view <file|->",
+	Short: "pretty print & filter a JSON log file (json or job style)",
+	Long: `Read JSON log lines written with clog.log.style json or job and render them
+with the pretty handler & theme. Lines that are not JSON log records are
+printed unchanged unless a filter is used.`,
+	Example: `
+	clog Log view tmp/clog.json
+	clog Log view tmp/clog.json --min warn --where env=prod
+	clog Log view tmp/clog.json --since 1h --where 'user~^adm' --where job.status!=COMPLETED
+	clog Log view tmp/clog.json --since "2025-06-01 09:00:00" --until 2025-06-02
+	clog Log view tmp/clog.json --follow --output plain
+	kubectl logs my-pod | clog Log view - --output json   # re-encode with clog level names
+	`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "-"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		filter, filtered := viewFilter()
+		style, err := slog.ParseStyle(viewOutput)
+		if err != nil || (style != slog.StylePlain && style != slog.StylePretty && style != slog.StyleJSON) {
+			slog.Error("clog Log view --output must be plain, pretty or json")
+			slog.Exit(1)
+		}
+		h := slog.NewViewHandler(os.Stdout, style)
+
+		in := os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				slog.Error("clog Log view: " + err.Error())
+				slog.Exit(1)
+			}
+			defer f.Close()
+			in = f
+		}
+		eachLine(in, viewFollow && path != "-", func(line []byte) {
+			rec, err := slog.ParseJSONRecord(line)
+			if err != nil {
+				if !filtered && style != slog.StyleJSON {
+					os.Stdout.Write(line)
+				}
+				return
+			}
+			if filter.Match(rec) {
+				h.Handle(context.Background(), rec)
+			}
+		})
+	},
+}
+
+// viewFilter builds the filter from the flags. Bad flags are logged and clog
+// exits with 1. filtered is false if no filter was given.
+func viewFilter() (filter slog.LogFilter, filtered bool) {
+	filter.Min = slog.LevelTrace
+	fail := func(msg string) {
+		slog.Error("clog Log view " + msg)
+		slog.Exit(1)
+	}
+	if len(viewMin) > 0 {
+		level, err := slog.ParseLevel(viewMin)
+		if err != nil {
+			fail("--min: " + err.Error())
+		}
+		filter.Min = level
+		filtered = true
+	}
+	now := time.Now()
+	for _, bound := range []struct {
+		flag  string
+		value string
+		t     *time.Time
+	}{{"--since", viewSince, &filter.Since}, {"--until", viewUntil, &filter.Until}} {
+		if len(bound.value) == 0 {
+			continue
+		}
+		t, err := slog.ParseTimeBound(bound.value, now)
+		if err != nil {
+			fail(bound.flag + ": " + err.Error())
+		}
+		*bound.t = t
+		filtered = true
+	}
+	for _, expr := range viewWhere {
+		c, err := slog.ParseWhere(expr)
+		if err != nil {
+			fail("--where: " + err.Error())
+		}
+		filter.Where = append(filter.Where, c)
+		filtered = true
+	}
+	return filter, filtered
+}
+
+// eachLine calls fn for every line in r (with its newline). When following,
+// the end of the file is polled for new lines & a truncated file is read
+// again from the start.
+func eachLine(f *os.File, follow bool, fn func(line []byte)) {
+	reader := bufio.NewReader(f)
+	partial := []byte{}
+	offset := int64(0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		offset += int64(len(line))
+		partial = append(partial, line...)
+		if err == nil {
+			fn(partial)
+			partial = []byte{}
+			continue
+		}
+		if err != io.EOF {
+			slog.Error(fmt.Sprintf("clog Log view: %s", err.Error()))
+			slog.Exit(1)
+		}
+		if !follow {
+			if len(partial) > 0 {
+				fn(append(partial, '\n'))
+			}
+			return
+		}
+		time.Sleep(followInterval)
+		if info, err := f.Stat(); err == nil && info.Size() < offset {
+			f.Seek(0, io.SeekStart)
+			reader.Reset(f)
+			partial, offset = []byte{}, 0
+		}
+	}
+}
+
+func init() {
+	ViewCommand.Flags().StringVar(&viewMin, "min", "", "only show records at or above this level e.g. --min warn")
+	ViewCommand.Flags().StringVar(&viewSince, "since", "", "only show records from a time (RFC 3339, date, date time) or duration ago e.g. 2h")
+	ViewCommand.Flags().StringVar(&viewUntil, "until", "", "only show records up to a time or duration ago")
+	ViewCommand.Flags().StringArrayVar(&viewWhere, "where", nil, "attribute filter key=value, key!=value or key~regexp (repeatable)")
+	ViewCommand.Flags().BoolVarP(&viewFollow, "follow", "f", false, "keep reading new lines as the file grows")
+	ViewCommand.Flags().StringVarP(&viewOutput, "output", "o", "pretty", "plain | pretty | json")
+	Command.AddCommand(ViewCommand)
+}
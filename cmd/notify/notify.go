@@ -0,0 +1,98 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package notify adds a Notify command to the clog command line tool
+
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags
+var targets []string
+var level string
+var group string
+var list bool
+
+// the longest time allowed for all deliveries including retries & waiting
+// for a rate limit
+var timeout = 2 * time.Minute
+
+// Command define the cobra settings for this command
+var Command = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "Notify",
+	Short:         "send a message to the chat, webhook & email targets in clog.notify",
+	Long: `Send a message to the targets configured in clog.notify. Without --target
+the clog.notify routes choose the targets by level & attributes. Delivery is
+retried and waits for the rate limit of a target. Secrets are masked.`,
+	Example: `
+	clog Notify "nightly build passed"                      # use the routes
+	clog Notify --level error "deploy failed" env=prod      # e.g. route to oncall
+	clog Notify -t team -t ops "release 1.2.3 is live" version=1.2.3
+	clog Notify --list                                      # show the targets
+	`,
+	Run: func(cmd *cobra.Command, args []string) {
+		n := slogger.DefaultNotifier()
+		if n == nil {
+			slogger.Error("clog Notify: no targets - add clog.notify to clog.yaml")
+			slogger.Exit(1)
+		}
+		if list {
+			for _, name := range n.Targets() {
+				fmt.Println(name)
+			}
+			return
+		}
+		if len(args) == 0 {
+			cmd.Help()
+			slogger.Exit(1)
+		}
+		l, err := slogger.ParseLevel(level)
+		if err != nil {
+			slogger.Error("clog Notify --level: " + err.Error())
+			slogger.Exit(1)
+		}
+		words, attrs := slogger.ParseAttrArgs(args)
+		rec := slog.NewRecord(time.Now(), l, strings.Join(words, " "), 0)
+		for _, a := range slogger.AttrArgs(group, attrs) {
+			rec.AddAttrs(a.(slog.Attr))
+		}
+
+		to := targets
+		if len(to) == 0 {
+			to = n.Route(rec)
+		}
+		if len(to) == 0 {
+			slogger.Warn("clog Notify: no clog.notify route matched - nothing sent")
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := n.Send(ctx, slogger.NewNotification(rec), to, true); err != nil {
+			slogger.Error("clog Notify: " + err.Error())
+			slogger.Exit(1)
+		}
+		slog.Debug("clog Notify sent to " + strings.Join(to, ", "))
+	},
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+
+	Command.Flags().StringArrayVarP(&targets, "target", "t", nil, "send to a clog.notify target rather than the routes (repeatable)")
+	Command.Flags().StringVar(&level, "level", "info", "the level used by the routes & shown in the message")
+	Command.Flags().StringVar(&group, "group", "", "nest the key=value attributes in a group")
+	Command.Flags().BoolVar(&list, "list", false, "list the clog.notify targets")
+}
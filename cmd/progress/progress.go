@@ -0,0 +1,140 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package progress adds a Progress command to the clog command line tool
+
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cobra"
+)
+
+// CLI flags
+var total int64
+var set int64
+
+// Command define the cobra settings for this command
+var Command = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "Progress",
+	Short:         "show a progress bar or spinner for a long running script step",
+	Long: `Show a progress bar (with --total) or a spinner that is redrawn in place on
+a terminal. In CI, or when stderr is not a terminal, the progress is logged
+as plain lines instead. In a script run by clog, stderr is a terminal if it
+is for the clog that ran the script (CLOG_TTY=1). Every clog Progress is a new process so the state is
+kept in a temp file keyed by the run id of the parent clog (or by the parent
+shell). Set CLOG_PROGRESS to a file path to choose the file.`,
+	Example: `
+	clog Progress start "Uploading" --total 120
+	for f in *.mp4; do upload "$f"; clog Progress tick; done
+	clog Progress done
+
+	clog Progress start "Waiting for the cluster"   # spinner
+	clog Progress tick --set 42                     # jump to a count
+	`,
+}
+
+var startCommand = &cobra.Command{
+	Use:   "start <name>",
+	Short: "start a progress bar (--total) or spinner",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if p := readProgress(); p != nil {
+			slogger.Warn("clog Progress start: replacing " + p.Name)
+		}
+		writeProgress(slogger.NewProgress(args[0], total))
+	},
+}
+
+var tickCommand = &cobra.Command{
+	Use:   "tick [n]",
+	Short: "move the progress on by n (default 1)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p := readProgress()
+		if p == nil {
+			slogger.Warn("clog Progress tick: no progress has been started")
+			return
+		}
+		switch {
+		case cmd.Flags().Changed("set"):
+			p.Set(set)
+		case len(args) > 0:
+			n, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				slogger.Error(fmt.Sprintf("clog Progress tick: bad count (%s)", args[0]))
+				slogger.Exit(1)
+			}
+			p.Add(n)
+		default:
+			p.Add(1)
+		}
+		writeProgress(p)
+	},
+}
+
+var doneCommand = &cobra.Command{
+	Use:   "done",
+	Short: "finish the progress & log its duration",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		p := readProgress()
+		if p == nil {
+			slogger.Warn("clog Progress done: no progress has been started")
+			return
+		}
+		p.Done()
+		os.Remove(progressPath())
+	},
+}
+
+// progressPath is the state file of the run (if clog was started by clog)
+// or of the parent shell
+func progressPath() string {
+	if path := os.Getenv("CLOG_PROGRESS"); len(path) > 0 {
+		return path
+	}
+	key := strconv.Itoa(os.Getppid())
+	if run := slogger.CurrentRun(); run.Depth > 0 {
+		key = run.ID
+	}
+	return filepath.Join(os.TempDir(), "clog-progress-"+key+".json")
+}
+
+func readProgress() *slogger.Progress {
+	body, err := os.ReadFile(progressPath())
+	if err != nil {
+		return nil
+	}
+	p := &slogger.Progress{}
+	if err := json.Unmarshal(body, p); err != nil {
+		return nil
+	}
+	return p
+}
+
+func writeProgress(p *slogger.Progress) {
+	body, _ := json.Marshal(p)
+	if err := os.WriteFile(progressPath(), body, 0644); err != nil {
+		slogger.Warn("clog Progress cannot save the progress: " + err.Error())
+	}
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+
+	startCommand.Flags().Int64Var(&total, "total", 0, "the count when done - a spinner is shown without it")
+	tickCommand.Flags().Int64Var(&set, "set", 0, "set the count rather than adding to it")
+	Command.AddCommand(startCommand, tickCommand, doneCommand)
+}
@@ -12,13 +12,17 @@ package cmd
 import (
 	"log/slog"
 	"runtime"
+	"strings"
 
 	"github.com/mrmxf/clog/cmd/version"
 	"github.com/mrmxf/clog/ux/ui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-// CLI flag for changing the configuration file path
+// CLI flag for changing the configuration file path. The config is loaded
+// before cobra parses the flags so main finds it with ConfigFlag. It is a
+// root flag so it must come before the command e.g. clog -c x.yaml Check.
 var ConfigFilePath string
 
 // CLI flag for showing the long version string
@@ -30,8 +34,17 @@ var ShowVersionShort bool
 // CLI flag for showing the note associated with this version
 var ShowVersionNote bool
 
-// CLI flag for changing the logging level
-var LogLevel int
+// CLI flag for changing the logging level e.g. debug or kfg=trace,warn - see
+// applyLogLevelFlag
+var LogLevel string
+
+// CLI flag for choosing a profile. Like --config it is a root flag that is
+// found by main before cobra parses the flags - see ProfileFlag.
+var Profile string
+
+// CLI flag for stopping if the config does not match the schema - see
+// validateStrict
+var Strict bool
 
 var RootCommand = &cobra.Command{
 	Use:   "clog",
@@ -86,14 +99,88 @@ as api:      	 curl -H "Authorization: OAuth <ACCESS_TOKEN>" http://localhost:87
 	},
 }
 
+// ConfigFlag finds --config (or -c) in the command line arguments so that
+// main can pass it to config.New. It is nil if the flag is not used.
+func ConfigFlag(args []string) *string {
+	return findFlag(args, "config", "c")
+}
+
+// ProfileFlag finds --profile in the command line arguments so that
+// main can select the profile before config.New. It is nil if the flag is
+// not used.
+func ProfileFlag(args []string) *string {
+	return findFlag(args, "profile", "")
+}
+
+// findFlag returns the value of a string flag before cobra parses the flags
+// e.g. --name value, --name=value, -s value, -s=value or -svalue. The
+// shorthand is optional. Only the flags before the first argument (e.g. a
+// command or snippet name) are searched so the flags of a snippet are not
+// found.
+func findFlag(args []string, name string, shorthand string) *string {
+	flags := []string{"--" + name}
+	if len(shorthand) > 0 {
+		flags = append(flags, "-"+shorthand)
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") || arg == "-" {
+			break
+		}
+		for _, flag := range flags {
+			switch {
+			case arg == flag && i+1 < len(args):
+				return &args[i+1]
+			case strings.HasPrefix(arg, flag+"="):
+				value := strings.TrimPrefix(arg, flag+"=")
+				return &value
+			case flag == "-"+shorthand && strings.HasPrefix(arg, flag) && len(arg) > len(flag):
+				value := arg[len(flag):]
+				return &value
+			}
+		}
+		// skip the value of another flag so it is not taken as an argument
+		if takesValue(arg) {
+			i++
+		}
+	}
+	return nil
+}
+
+// takesValue is true for a root flag (e.g. --loglevel or -l) that is
+// followed by its value
+func takesValue(arg string) bool {
+	var flag *pflag.Flag
+	switch {
+	case strings.Contains(arg, "="):
+		return false
+	case strings.HasPrefix(arg, "--"):
+		if flag = RootCommand.LocalNonPersistentFlags().Lookup(arg[2:]); flag == nil {
+			flag = RootCommand.PersistentFlags().Lookup(arg[2:])
+		}
+	case len(arg) == 2:
+		if flag = RootCommand.LocalNonPersistentFlags().ShorthandLookup(arg[1:]); flag == nil {
+			flag = RootCommand.PersistentFlags().ShorthandLookup(arg[1:])
+		}
+	}
+	return flag != nil && flag.Value.Type() != "bool"
+}
+
 func init() {
 	_, file, _, _ := runtime.Caller(0)
 	slog.Debug("init " + file)
 
-	// Define persistent (global) flags and any flags for the root command
-	RootCommand.PersistentFlags().StringVarP(&ConfigFilePath, "config", "c", "", "clog -c myClogfig.yaml   # clog Cat core.clog.yaml > myClogfig.yaml")
+	// --config & --profile are applied by main before the command is found so
+	// they are root flags - cobra rejects them after the command rather than
+	// ignoring them. TraverseChildren parses them before the command.
+	RootCommand.TraverseChildren = true
+	RootCommand.Flags().StringVarP(&ConfigFilePath, "config", "c", "", "clog -c myClogfig.yaml   # clog Cat core.clog.yaml > myClogfig.yaml")
+	RootCommand.Flags().StringVar(&Profile, "profile", "", "clog --profile prod ...  # overlay the prod profile (or CLOG_PROFILE=prod)")
+
+	// Define persistent (global) flags
 	RootCommand.PersistentFlags().BoolVar(&ShowVersion, "version", false, "clog --version           # shows the full version string")
 	RootCommand.PersistentFlags().BoolVarP(&ShowVersionShort, "v", "v", false, "clog -v                  # shows just the semantic version")
 	RootCommand.PersistentFlags().BoolVarP(&ShowVersionNote, "note", "n", false, "clog --note              # shows just the version note")
-	RootCommand.PersistentFlags().IntVarP(&LogLevel, "loglevel", "l", 0, "clog --loglevel 1        # 0:OFF 1:DEBUG 2:INFO 3:WARN 4:ERROR")
+	RootCommand.PersistentFlags().BoolVar(&Strict, "strict", false, "clog --strict ...        # stop if clog.yaml does not match the schema")
+	RootCommand.PersistentFlags().StringVarP(&LogLevel, "loglevel", "l", "", "clog -l debug            # or -l kfg=trace,warn or 0:OFF 1:DEBUG 2:INFO 3:WARN 4:ERROR")
 }
@@ -0,0 +1,59 @@
+//  Copyright ©2017-2025    Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License    https://opensource.org/license/bsd-3-clause/
+
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mrmxf/clog/cmd"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/cobra"
+)
+
+func TestConfigFlag(t *testing.T) {
+	value := func(args string) any {
+		found := cmd.ConfigFlag(strings.Fields(args))
+		if found == nil {
+			return nil
+		}
+		return *found
+	}
+
+	Convey("--config should be found in every form before the command", t, func() {
+		So(value("--config a.yaml Check"), ShouldEqual, "a.yaml")
+		So(value("--config=a.yaml"), ShouldEqual, "a.yaml")
+		So(value("-c a.yaml"), ShouldEqual, "a.yaml")
+		So(value("-c=a.yaml"), ShouldEqual, "a.yaml")
+		So(value("-ca.yaml"), ShouldEqual, "a.yaml")
+		So(value("-l debug --version -c a.yaml"), ShouldEqual, "a.yaml")
+		So(value("Check"), ShouldBeNil)
+	})
+
+	Convey("the flags after the first argument should belong to the command", t, func() {
+		So(value("deploy -c prod.yaml"), ShouldBeNil)
+		So(value("-l debug deploy --config prod.yaml"), ShouldBeNil)
+		So(value("-- -c a.yaml"), ShouldBeNil)
+		So(*cmd.ProfileFlag(strings.Fields("--profile prod deploy --profile dev")), ShouldEqual, "prod")
+	})
+
+	Convey("cobra should reject --config & --profile after the command", t, func() {
+		sub := &cobra.Command{Use: "Sub", Run: func(*cobra.Command, []string) {}}
+		cmd.RootCommand.AddCommand(sub)
+		defer cmd.RootCommand.RemoveCommand(sub)
+		cmd.RootCommand.SilenceUsage = true
+		defer func() { cmd.RootCommand.SilenceUsage = false }()
+
+		cmd.RootCommand.SetArgs(strings.Fields("-c a.yaml --profile prod Sub"))
+		So(cmd.RootCommand.Execute(), ShouldBeNil)
+		So(cmd.ConfigFilePath, ShouldEqual, "a.yaml")
+		So(cmd.Profile, ShouldEqual, "prod")
+
+		for _, args := range []string{"Sub -c b.yaml", "Sub --config b.yaml", "Sub --profile dev"} {
+			So(value(args), ShouldBeNil)
+			cmd.RootCommand.SetArgs(strings.Fields(args))
+			So(cmd.RootCommand.Execute(), ShouldNotBeNil)
+		}
+	})
+}
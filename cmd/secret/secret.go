@@ -0,0 +1,187 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// package secret adds a Secret command to the clog command line tool
+
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// CLI flags
+var ageFile string
+
+// the yaml of a new secrets file
+var newSecrets = "# clog secrets - use in clog.yaml as secret://age/<file>#<key>\n"
+
+// Command define the cobra settings for this command
+var Command = &cobra.Command{
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	Use:           "Secret",
+	Short:         "manage the age encrypted secrets file",
+	Long: `Manage the yaml secrets in an age encrypted file (clog.secrets.file) that can
+be committed to the repo. It is encrypted for every clog.secrets.recipients
+key and decrypted with your identity (CLOG_AGE_IDENTITY or clog.secrets.identity).
+The age & age-keygen tools must be installed.
+
+Any config string can reference a secret. It is resolved when its key is read
+or when the snippet runs - clog stops if it cannot be resolved:
+  secret://env/NAME   secret://file/path   secret://pass/entry
+  secret://age/clogrc/secrets.age#db.password
+Resolved secrets are masked in logs & script output.`,
+	Example: `
+	age-keygen -o ~/.config/clog/age.key        # once per user
+	clog Secret set db.password                 # reads the value from stdin
+	clog Secret set slack.webhook https://hooks.slack.com/...
+	clog Secret get db.password
+	clog Secret get secret://pass/team/npm      # any secret reference
+	clog Secret ls
+	clog Secret edit                            # $EDITOR on the decrypted yaml
+	`,
+}
+
+var setCommand = &cobra.Command{
+	Use:   "set <key> [value]",
+	Short: "set a secret - the value is read from stdin if it is not given",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		secrets, err := cfg.ReadSecrets(secretsFile())
+		if errors.Is(err, os.ErrNotExist) {
+			secrets, err = map[string]any{}, nil
+		}
+		stop(err)
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			in, err := io.ReadAll(os.Stdin)
+			stop(err)
+			value = strings.TrimRight(string(in), "\r\n")
+		}
+		stop(config.SetSecretValue(secrets, args[0], value))
+		stop(cfg.WriteSecrets(secretsFile(), secrets))
+		slogger.Success("clog Secret: set " + args[0] + " in " + secretsFile())
+	},
+}
+
+var getCommand = &cobra.Command{
+	Use:   "get <key|secret://...>",
+	Short: "print a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		ref := args[0]
+		if !config.IsSecretRef(ref) {
+			ref = config.SecretScheme + "age/" + secretsFile() + "#" + ref
+		}
+		value, err := cfg.ResolveSecret(ref)
+		stop(err)
+		fmt.Println(value)
+	},
+}
+
+var lsCommand = &cobra.Command{
+	Use:   "ls",
+	Short: "list the keys (not the values) of the secrets",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		secrets, err := config.Cfg().ReadSecrets(secretsFile())
+		stop(err)
+		for _, key := range config.SecretKeys(secrets) {
+			fmt.Println(key)
+		}
+	},
+}
+
+var editCommand = &cobra.Command{
+	Use:   "edit",
+	Short: "edit the decrypted secrets with $EDITOR",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.Cfg()
+		plain, err := cfg.ReadSecretsYaml(secretsFile())
+		if errors.Is(err, os.ErrNotExist) {
+			plain, err = []byte(newSecrets), nil
+		}
+		stop(err)
+		edited, err := edit(plain)
+		stop(err)
+		if bytes.Equal(edited, plain) {
+			slogger.Info("clog Secret: no changes to " + secretsFile())
+			return
+		}
+		if err := yaml.Unmarshal(edited, &map[string]any{}); err != nil {
+			stop(fmt.Errorf("not saved - the secrets are not yaml: %w", err))
+		}
+		stop(cfg.WriteSecretsYaml(secretsFile(), edited))
+		slogger.Success("clog Secret: saved " + secretsFile())
+	},
+}
+
+// edit opens the yaml in $VISUAL or $EDITOR (default vi) in a private temp
+// folder that is removed afterwards
+func edit(plain []byte) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "clog-secret-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.yaml")
+	if err := os.WriteFile(path, plain, 0o600); err != nil {
+		return nil, err
+	}
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	c := exec.Command(editor[0], append(editor[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", editor[0], err)
+	}
+	return os.ReadFile(path)
+}
+
+// secretsFile is --file or clog.secrets.file
+func secretsFile() string {
+	if len(ageFile) > 0 {
+		return ageFile
+	}
+	return config.Cfg().SecretsFile()
+}
+
+// stop reports an error & exits
+func stop(err error) {
+	if err != nil {
+		slogger.Error("clog Secret: " + err.Error())
+		slogger.Exit(1)
+	}
+}
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+
+	Command.PersistentFlags().StringVarP(&ageFile, "file", "f", "", "the age file (default clog.secrets.file)")
+	Command.AddCommand(setCommand, getCommand, lsCommand, editCommand)
+}
@@ -32,7 +32,7 @@ var Command = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) < 2 {
 			cmd.Help()
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 
 		if debug {
@@ -52,16 +52,16 @@ var Command = &cobra.Command{
 			if found {
 				dbg := fmt.Sprintf("clog Should $%s \"%s\" - ✅ found in(%s)", haystackEnv, needle, haystack)
 				slog.Debug(dbg)
-				os.Exit(0)
+				slogger.Exit(0)
 			} else {
 				dbg := fmt.Sprintf("clog Should $%s \"%s\" ❌ missing from(%s)", haystackEnv, needle, haystack)
 				slog.Debug(dbg)
-				os.Exit(1)
+				slogger.Exit(1)
 			}
 		} else {
 			dbg := fmt.Sprintf("clog Should $%s \"%s\" - ❌ missing env %s", haystackEnv, needle, haystackEnv)
 			slog.Debug(dbg)
-			os.Exit(2)
+			slogger.Exit(2)
 		}
 	},
 }
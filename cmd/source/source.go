@@ -14,10 +14,11 @@ package source
 import (
 	"fmt"
 	"log/slog"
-	"os"
 	"runtime"
 	"strings"
 
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -40,18 +41,23 @@ var Command = &cobra.Command{
 			// source the snippet or script to stdout
 			switch srcCmd.Annotations["is-a"] {
 			case "snippet":
-				fmt.Println(srcCmd.Annotations["script"])
-				os.Exit(0)
+				script, err := config.Cfg().ResolveString(srcCmd.Annotations["script"])
+				if err != nil {
+					slog.Error(fmt.Sprintf("clog Source (%s) %s", cmdString, err.Error()))
+					slogger.Exit(1)
+				}
+				fmt.Println(script)
+				slogger.Exit(0)
 			case "script":
 				fmt.Println(srcCmd.Annotations["file-path"])
-				os.Exit(0)
+				slogger.Exit(0)
 			default:
 				slog.Error(fmt.Sprintf("clog Source (%s) neither snippet nor script", cmdString))
-				os.Exit(1)
+				slogger.Exit(1)
 			}
 		}
 		slog.Error(fmt.Sprintf("clog Source (%s) %s", cmdString, err.Error()))
-		os.Exit(1)
+		slogger.Exit(1)
 	},
 }
 
@@ -6,11 +6,11 @@ package version
 import (
 	"fmt"
 	"log/slog"
-	"os"
 	"runtime"
 
 	"github.com/mrmxf/clog/config"
 	"github.com/mrmxf/clog/crayon"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -23,22 +23,23 @@ var Command = &cobra.Command{
 		slog.Debug(fmt.Sprintf("run command: %s", file))
 
 		if len(args) == 0 {
-			fmt.Printf("%s (%s) %s\n",
+			fmt.Printf("%s (%s) %s%s\n",
 				crayon.ColorCapitals(config.Cfg().GetString("title"), nil, nil),
 				config.Cfg().GetString("app"),
-				config.Cfg().GetString("clog.version.long"))
-			os.Exit(0)
+				config.Cfg().GetString("clog.version.long"),
+				config.ProfileTag())
+			slogger.Exit(0)
 		}
 		if args[0] == "short" {
 			fmt.Println(config.Cfg().GetString("ver"))
-			os.Exit(0)
+			slogger.Exit(0)
 		}
 		if args[0] == "note" {
 			fmt.Println(config.Cfg().GetString("clog.version.note"))
-			os.Exit(0)
+			slogger.Exit(0)
 		}
 		slog.Error("unknown version argument (" + args[0] + ")")
-		os.Exit(1)
+		slogger.Exit(1)
 	},
 }
 
@@ -22,6 +22,7 @@ import (
 	"log/slog"
 	"os"
 	"runtime"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
 )
@@ -29,31 +30,36 @@ import (
 // export the Fs with the `core/` folder - initialised by calling program
 var coreFs embed.FS
 
-// Config type embeds the Viper struct and extends it
+// Config type embeds the Viper struct and extends it. A reload does not
+// change a Config - it makes a new one so call Cfg for the current config.
 type Config struct {
 	*viper.Viper
+	layers      []Layer  // the configs in merge order - see Explain
+	searchPaths []string // the search paths that were merged
+	configFiles []string // the config files that were merged or searched for
+	includes    []string // the include: globs that were searched
+	profile     string   // the active profile
 }
 
-// the global config variable used by other packages
-var cfg *Config = nil
+// the global config used by other packages - swapped by Reload
+var current atomic.Pointer[Config]
 
 // a cache of the Fs slice for future extension
 var fsCache []embed.FS = []embed.FS{}
 
-// the searchPaths from the config can be exported
-var searchPaths []string
+// the config file (e.g. from --config) that is merged after the search paths
+var cfgOverride string
 
 // create a new config object
 //
 // fsSlice is a slice of embed.FS objects that are searched search for configs
-// cfgPathOverride is the name of the config file to load
+// cfgPathOverride is a config file (e.g. from --config) that is merged after
+// the search paths. A profile is overlaid after it.
 //
-//	if nil or empty use `clog.yaml`
+//	if nil or empty only the search paths are merged
 func New(fsSlice *[]embed.FS, cfgPathOverride *string) *Config {
 	//initialise viper with logger that can be uses throughout clog
-	cfg = &Config{
-		viper.New(),
-	}
+	cfg := &Config{Viper: viper.New()}
 
 	//preserve fsCache for future use
 	if len(*fsSlice) > 0 {
@@ -63,12 +69,36 @@ func New(fsSlice *[]embed.FS, cfgPathOverride *string) *Config {
 		os.Exit(1)
 	}
 
+	cfgOverride = ""
+	if cfgPathOverride != nil {
+		cfgOverride = *cfgPathOverride
+	}
+	if err := cfg.load(); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	current.Store(cfg)
+	return cfg
+}
+
+// load reads the embedded config, merges the config files with it and then
+// overlays the profile. The profile is last so that it wins over every file
+// (--config too) & so that a profile can be defined in the --config file.
+// Only a missing override or a bad profile fails.
+func (cfg *Config) load() error {
 	// populate a new config object, load in the embedded config and set the
 	// initial search paths to find other configs to overlay
-	cfg.setDefaults(cfgPathOverride)
+	cfg.setDefaults()
 
-	// Merge the config file with defaults - ignore errors
-	cfg.mergeAllConfigs()
+	// Merge the config files with defaults - only a missing override is fatal
+	if err := cfg.mergeAllConfigs(cfgOverride); err != nil {
+		return fmt.Errorf("--config: %w", err)
+	}
+
+	// overlay the profile chosen by CLOG_PROFILE (or --profile)
+	if err := cfg.useProfile(); err != nil {
+		return fmt.Errorf("--profile: %w", err)
+	}
 
 	//enable auto-import of `env` variables declared in config
 	// e.g. AWS_ACCESS_KEY_ID becomes cfg.GetString("AWS_ACCESS_KEY_ID")
@@ -83,13 +113,12 @@ func New(fsSlice *[]embed.FS, cfgPathOverride *string) *Config {
 			slog.Warn("Failed to bind environment variable: " + envVariableName + " Error: " + err.Error())
 		}
 	}
-	return cfg
+	return nil
 }
 
 // get the config object
 func Cfg() *Config {
-
-	return cfg
+	return current.Load()
 }
 
 // get the coreFs
@@ -102,8 +131,12 @@ func FsCache() []embed.FS {
 	return fsCache
 }
 
+// get the search paths of the current config
 func SearchPaths() *[]string {
-	return &searchPaths
+	if cfg := Cfg(); cfg != nil {
+		return &cfg.searchPaths
+	}
+	return &[]string{}
 }
 
 func init() {
@@ -20,7 +20,7 @@ var rootConfigFilename = "core.clog.yaml"
 // root keys  using viper.setDefaults survive a config file merge
 // child keys using viper.setDefaults are lost
 
-func (cfg *Config) setDefaults(cfgPathOverride *string) {
+func (cfg *Config) setDefaults() {
 	//tell viper that we're reading YAML otherwise it silently fails.
 	cfg.SetConfigType("yaml")
 
@@ -39,7 +39,10 @@ func (cfg *Config) setDefaults(cfgPathOverride *string) {
 		msg := fmt.Sprintf("config.setDefaults() failed with clog's embedded file system: %s", err.Error())
 		panic(msg)
 	}
-	//parse & load the config
+	//parse & load the config - the embedded config is the first layer
+	cfg.layers = nil
+	cfg.includes = nil
+	cfg.addLayer("embedded:"+configPaths[0], rootConfig)
 	cfgReader := bytes.NewReader(rootConfig)
 	if err = cfg.ReadConfig(cfgReader); err != nil {
 		msg := fmt.Sprintf("config.setDefaults() failed reading clog's embedded file system: %s", err.Error())
@@ -47,10 +50,7 @@ func (cfg *Config) setDefaults(cfgPathOverride *string) {
 	}
 
 	//overlay various other configs with configCLI being the highest priority
-	searchPaths = cfg.GetStringSlice("clog.clogrc.search-paths")
-	if cfgPathOverride != nil && len(*cfgPathOverride) > 2 {
-		searchPaths = append(searchPaths, *cfgPathOverride)
-	}
+	cfg.searchPaths = cfg.Viper.GetStringSlice(SearchPathsKey)
 
 
 
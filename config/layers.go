@@ -0,0 +1,191 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - remember where every value came from
+//
+// Every config that is merged is kept as a Layer in merge order so that
+// `clog Config explain <key>` can show which files define a key, the line
+// of the key in each file and which definition won.
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layer is one config in the merge order - the embedded config is first
+type Layer struct {
+	Source string     // the file path, or embedded:<path> for the embedded config
+	root   *yaml.Node // the parsed document
+	err    error      // why the document could not be parsed
+}
+
+// Definition is where a layer defines a key
+type Definition struct {
+	Source string `json:"source" yaml:"source"`
+	Line   int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Value  any    `json:"value" yaml:"value"`
+	Winner bool   `json:"winner,omitempty" yaml:"winner,omitempty"`
+}
+
+// NewLayer parses the yaml of a config
+func NewLayer(source string, body []byte) (Layer, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(body, doc); err != nil {
+		return Layer{Source: source, err: err}, fmt.Errorf("%s: %w", source, err)
+	}
+	return Layer{Source: source, root: doc}, nil
+}
+
+// Layers returns the layers of the current config in merge order
+func Layers() []Layer {
+	if cfg := Cfg(); cfg != nil {
+		return cfg.layers
+	}
+	return nil
+}
+
+// addLayer records a config that has been merged. A config that cannot be
+// parsed is still recorded so that the merge order is complete.
+func (cfg *Config) addLayer(source string, body []byte) {
+	layer, err := NewLayer(source, body)
+	if err != nil {
+		slog.Debug("config layer has no provenance", "error", err)
+	}
+	cfg.layers = append(cfg.layers, layer)
+}
+
+// Lookup finds a key (e.g. clog.log.level) in the layer. Keys are not case
+// sensitive. line is the line of the key in the file.
+func (l Layer) Lookup(key string) (value any, line int, found bool) {
+	node, line := l.find(key)
+	if node == nil {
+		return nil, 0, false
+	}
+	if err := node.Decode(&value); err != nil {
+		return nil, line, false
+	}
+	return value, line, true
+}
+
+// node returns the value node of a key or nil
+func (l Layer) node(key string) *yaml.Node {
+	node, _ := l.find(key)
+	return node
+}
+
+// find returns the value node of a key and the line of the key
+func (l Layer) find(key string) (*yaml.Node, int) {
+	node, line := l.root, 0
+	if node == nil {
+		return nil, 0
+	}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, 0
+		}
+		node = node.Content[0]
+	}
+	for _, segment := range strings.Split(key, ".") {
+		node = resolveAlias(node)
+		if node.Kind != yaml.MappingNode {
+			return nil, 0
+		}
+		var next *yaml.Node
+		// the last duplicate key wins in the same way as the merge
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, segment) {
+				line, next = node.Content[i].Line, node.Content[i+1]
+			}
+		}
+		if next == nil {
+			return nil, 0
+		}
+		node = next
+	}
+	return resolveAlias(node), line
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// Explain returns every layer that defines a key in merge order. The last
+// definition of a value wins. Maps are merged so every layer that defines a
+// map contributes to it and none of them wins. A value that is set by an
+// env var or by clog at startup is added as the last definition.
+func (cfg *Config) Explain(key string) []Definition {
+	definitions, winner := explainLayers(cfg.layers, key)
+	if !cfg.IsSet(key) {
+		return definitions
+	}
+	effective := cfg.Viper.Get(key)
+	if _, isMap := effective.(map[string]any); !isMap {
+		if winner < 0 || !sameValue(definitions[winner].Value, effective) {
+			source := "runtime"
+			if env, set := os.LookupEnv(strings.ToUpper(key)); set && env == fmt.Sprint(effective) {
+				source = "env:" + strings.ToUpper(key)
+			}
+			definitions = append(definitions, Definition{Source: source, Value: effective})
+			winner = len(definitions) - 1
+		}
+	}
+	if winner >= 0 {
+		definitions[winner].Winner = true
+	}
+	return definitions
+}
+
+// ExplainLayers returns every layer that defines a key in merge order with
+// the last definition of a value as the winner. It is Explain for layers
+// that are not merged by this package e.g. the kfg configs.
+func ExplainLayers(layers []Layer, key string) []Definition {
+	definitions, winner := explainLayers(layers, key)
+	if winner >= 0 {
+		definitions[winner].Winner = true
+	}
+	return definitions
+}
+
+// explainLayers finds the definitions of a key and the index of the winner
+// which is -1 when the last definition is a map
+func explainLayers(layers []Layer, key string) ([]Definition, int) {
+	definitions := []Definition{}
+	winner := -1
+	for _, layer := range layers {
+		value, line, found := layer.Lookup(key)
+		if !found {
+			continue
+		}
+		definitions = append(definitions, Definition{Source: layer.Source, Line: line, Value: value})
+		if _, isMap := value.(map[string]any); isMap {
+			winner = -1
+		} else {
+			winner = len(definitions) - 1
+		}
+	}
+	return definitions, winner
+}
+
+// sameValue compares a yaml value with the value from viper which may have
+// been converted e.g. an int in the yaml is an int in viper too but a list
+// of strings is a []any
+func sameValue(a, b any) bool {
+	return reflect.DeepEqual(a, b) || fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
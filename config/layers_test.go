@@ -0,0 +1,126 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package config_test
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const layerYaml = `clog:
+  log:
+    level: info
+    Style: pretty
+  defaults: &defaults
+    retries: 3
+  notify: *defaults
+`
+
+func TestLayerLookup(t *testing.T) {
+	Convey("a key should be found with the line it is defined on", t, func() {
+		layer, err := config.NewLayer("test.yaml", []byte(layerYaml))
+		So(err, ShouldBeNil)
+
+		value, line, found := layer.Lookup("clog.log.level")
+		So(found, ShouldBeTrue)
+		So(value, ShouldEqual, "info")
+		So(line, ShouldEqual, 3)
+
+		value, line, found = layer.Lookup("CLOG.log.style")
+		So(found, ShouldBeTrue)
+		So(value, ShouldEqual, "pretty")
+		So(line, ShouldEqual, 4)
+
+		value, _, found = layer.Lookup("clog.notify.retries")
+		So(found, ShouldBeTrue)
+		So(value, ShouldEqual, 3)
+
+		_, _, found = layer.Lookup("clog.log.level.nope")
+		So(found, ShouldBeFalse)
+		_, _, found = layer.Lookup("clog.missing")
+		So(found, ShouldBeFalse)
+	})
+
+	Convey("a bad config should be an error", t, func() {
+		_, err := config.NewLayer("bad.yaml", []byte("clog: [unclosed"))
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, "bad.yaml")
+	})
+}
+
+func TestExplain(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GREETING", "hi")
+	write := func(name string, body string) string {
+		path := filepath.Join(home, name)
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	// $HOME/.clog.yaml is in the core search paths
+	home0 := write(".clog.yaml", "greeting: hello\ndemo:\n  port: 8000\n  hookprefix: /hooks\n")
+	override := write("override.yaml", "demo:\n  port: 9000\n")
+	cfg := config.New(&[]embed.FS{core.CoreFs}, &override)
+
+	Convey("the last definition of a value should win", t, func() {
+		definitions := cfg.Explain("demo.port")
+		So(definitions, ShouldHaveLength, 2)
+		So(definitions[0], ShouldResemble, config.Definition{Source: home0, Line: 3, Value: 8000})
+		So(definitions[1], ShouldResemble, config.Definition{Source: override, Line: 2, Value: 9000, Winner: true})
+		So(cfg.Explain("demo.missing"), ShouldBeEmpty)
+	})
+
+	Convey("every layer should contribute to a map & none of them win", t, func() {
+		definitions := cfg.Explain("demo")
+		So(definitions, ShouldHaveLength, 2)
+		for _, d := range definitions {
+			So(d.Winner, ShouldBeFalse)
+		}
+		So(cfg.GetStringMap("demo"), ShouldContainKey, "hookprefix")
+	})
+
+	Convey("a value from an env var should win over the files", t, func() {
+		definitions := cfg.Explain("greeting")
+		So(definitions, ShouldHaveLength, 2)
+		So(definitions[0].Winner, ShouldBeFalse)
+		So(definitions[1], ShouldResemble, config.Definition{Source: "env:GREETING", Value: "hi", Winner: true})
+	})
+
+	Convey("a value set at runtime should be the last definition", t, func() {
+		cfg.Set("demo.port", 7000)
+		definitions := cfg.Explain("demo.port")
+		So(definitions, ShouldHaveLength, 3)
+		So(definitions[1].Winner, ShouldBeFalse)
+		So(definitions[2], ShouldResemble, config.Definition{Source: "runtime", Value: 7000, Winner: true})
+	})
+}
+
+func TestExplainLayers(t *testing.T) {
+	Convey("layers that are merged elsewhere should be explained", t, func() {
+		base, err := config.NewLayer("embedded:konfig.yaml", []byte("kfg:\n  name: base\n  opts:\n    a: 1\n"))
+		So(err, ShouldBeNil)
+		user, err := config.NewLayer(".konfig.yaml", []byte("kfg:\n  opts:\n    b: 2\n  name: user\n"))
+		So(err, ShouldBeNil)
+		layers := []config.Layer{base, user}
+
+		definitions := config.ExplainLayers(layers, "kfg.name")
+		So(definitions, ShouldHaveLength, 2)
+		So(definitions[0].Winner, ShouldBeFalse)
+		So(definitions[1], ShouldResemble, config.Definition{Source: ".konfig.yaml", Line: 4, Value: "user", Winner: true})
+
+		definitions = config.ExplainLayers(layers, "kfg.opts")
+		So(definitions, ShouldHaveLength, 2)
+		So(definitions[0].Winner || definitions[1].Winner, ShouldBeFalse)
+
+		So(config.ExplainLayers(layers, "kfg.missing"), ShouldBeEmpty)
+	})
+}
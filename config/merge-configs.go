@@ -6,12 +6,18 @@
 package config
 
 import (
+	"bytes"
+	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // ExpandEnvVars will replace all elements like `$ENV_VAR` and `$THINGY` into
@@ -42,35 +48,186 @@ func ExpandEnvVars(input string) (expandedStr string, allValid bool) {
 	return expandedStr, allValid
 }
 
+// SearchPathsKey is the list of config files that are merged in order
+var SearchPathsKey = "clog.clogrc.search-paths"
+
+// IncludeKey is the list of files that a config file includes e.g.
+//
+//	include:
+//	  - clogrc/conf.d/*.yaml                     # relative to this file
+//	  - {path: local.clog.yaml, optional: true}  # skipped if missing
+//
+// The included files are merged in order straight after the file that
+// includes them so they override it.
+var IncludeKey = "include"
+
+// the most times the search paths are searched again because a merged file
+// changed them and the deepest nesting of include: directives
+const maxSearchDepth = 4
+
+// include is one entry of an include: list
+type include struct {
+	Path     string `yaml:"path"`
+	Optional bool   `yaml:"optional"`
+}
+
+// UnmarshalYAML accepts a path or a map with a path
+func (inc *include) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		inc.Path = node.Value
+		return nil
+	}
+	type plain include
+	return node.Decode((*plain)(inc))
+}
+
 // mergeAllConfigs will search for the default override config files once
 // the embedded config file has been loaded. When THE FINAL search location
 // has been searched, it will then see if SearchPathList has changed. If so,
-// it will call itself again until there is no more change to SearchPathList.
-// Note that the max depth will be 4
-func (cfg *Config) mergeAllConfigs() {
-	slog.Debug("Merging user defined configs", "SearchPathList", searchPaths)
-
-	for _, rawPath := range searchPaths {
-		relPath := strings.Replace(rawPath, "~", "$HOME", 1)
-		relPath, _ = ExpandEnvVars(relPath)
-		path, err := filepath.Abs(relPath)
-		if err != nil {
-			slog.Debug("Error getting absolute path", "path", relPath, "error", err)
-			continue
+// it will start again from the embedded config and merge the new list in
+// order until there is no more change to SearchPathList. Note that the max
+// depth will be 4. Every file is only loaded once per search.
+//
+// The override (from --config) is merged after the search paths so that it
+// wins over them. A profile is overlaid after it (see load). It must
+// exist. Its includes are merged but a change to the search paths is not.
+func (cfg *Config) mergeAllConfigs(override string) error {
+	slog.Debug("Merging user defined configs", "SearchPathList", cfg.searchPaths)
+
+	var loaded map[string]bool
+	for depth := 0; ; depth++ {
+		loaded = map[string]bool{}
+		for _, rawPath := range cfg.searchPaths {
+			path, err := expandPath(rawPath, "")
+			if err != nil {
+				slog.Debug("Error getting absolute path", "path", rawPath, "error", err)
+				continue
+			}
+			if loaded[path] {
+				continue
+			}
+			loaded[path] = true
+			cfg.mergeFile(path, false, loaded, 0)
 		}
-		ioReader, err := os.Open(path)
+		next := cfg.Viper.GetStringSlice(SearchPathsKey)
+		if slices.Equal(next, cfg.searchPaths) {
+			break
+		}
+		if depth == maxSearchDepth {
+			slog.Warn(fmt.Sprintf("%s still changing after %d searches - ignoring the change", SearchPathsKey, maxSearchDepth))
+			break
+		}
+		slog.Debug("search paths changed - searching again", "SearchPathList", next)
+		// a file that is earlier in the new list must not win over a later
+		// one so the merge starts again
+		cfg.setDefaults()
+		cfg.searchPaths = next
+	}
+
+	if len(override) > 0 {
+		path, err := expandPath(override, "")
 		if err == nil {
-			defer ioReader.Close()
-			slog.Debug("Found config file", "path", path)
-			err := cfg.MergeConfig(ioReader)
-			if err != nil {
-				slog.Error("Error merging config file", "path", path, "error", err)
+			loaded[path] = true
+			if !cfg.mergeFile(path, true, loaded, 0) {
+				err = fmt.Errorf("cannot load %s", path)
 			}
+		}
+		if err != nil {
+			return err
+		}
+		cfg.searchPaths = append(cfg.searchPaths, override)
+	}
+	cfg.configFiles = slices.Sorted(maps.Keys(loaded))
+	return nil
+}
 
+// mergeFile merges a config file and then the files that it includes. It
+// returns false if the file could not be merged. A missing file is only
+// reported if it is required.
+func (cfg *Config) mergeFile(path string, required bool, loaded map[string]bool, depth int) bool {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if required {
+			slog.Error("Cannot read config file", "path", path, "error", err)
 		} else {
 			slog.Debug("Did not find config file", "path", path)
 		}
+		return false
+	}
+	slog.Debug("Found config file", "path", path)
+	cfg.addLayer(path, body)
+	if err := cfg.MergeConfig(bytes.NewReader(body)); err != nil {
+		slog.Error("Error merging config file", "path", path, "error", err)
+		return false
+	}
+	cfg.mergeIncludes(path, body, loaded, depth)
+	return true
+}
+
+// mergeIncludes merges the files in the include: list of a config file.
+// Paths are relative to the file & may be globs. A required include that
+// is missing (or a glob that matches nothing) is reported.
+func (cfg *Config) mergeIncludes(path string, body []byte, loaded map[string]bool, depth int) {
+	doc := struct {
+		Include []include `yaml:"include"`
+	}{}
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		// a single include does not need to be a list
+		single := struct {
+			Include include `yaml:"include"`
+		}{}
+		if yaml.Unmarshal(body, &single) != nil {
+			slog.Error("Bad include list", "path", path, "error", err)
+			return
+		}
+		doc.Include = []include{single.Include}
+	}
+	if len(doc.Include) > 0 && depth >= maxSearchDepth {
+		slog.Warn(fmt.Sprintf("includes nested deeper than %d - ignoring the includes", maxSearchDepth), "path", path)
+		return
+	}
+	for _, inc := range doc.Include {
+		pattern, err := expandPath(inc.Path, filepath.Dir(path))
+		if err != nil || len(inc.Path) == 0 {
+			slog.Error("Bad include", "path", path, "include", inc.Path)
+			continue
+		}
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			// a new file that matches the glob is merged by a reload
+			cfg.includes = append(cfg.includes, pattern)
+			// Glob returns the matches in lexical order
+			matches, err = filepath.Glob(pattern)
+			if err != nil || (len(matches) == 0 && !inc.Optional) {
+				slog.Warn("include matched no files", "path", path, "include", inc.Path)
+			}
+		}
+		for _, match := range matches {
+			if loaded[match] {
+				slog.Debug("Config file already loaded", "path", match, "include", inc.Path)
+				continue
+			}
+			if _, err := os.Stat(match); err != nil && inc.Optional {
+				slog.Debug("Did not find optional include", "path", match)
+				// searched for so that a reload merges it once it exists
+				loaded[match] = true
+				continue
+			}
+			loaded[match] = true
+			cfg.mergeFile(match, true, loaded, depth+1)
+		}
+	}
+}
+
+// expandPath expands ~ and $ENV_VARS in a path and makes it absolute. A
+// relative path is relative to dir (or the working directory).
+func expandPath(rawPath string, dir string) (string, error) {
+	path := strings.Replace(rawPath, "~", "$HOME", 1)
+	path, _ = ExpandEnvVars(path)
+	if !filepath.IsAbs(path) && len(dir) > 0 {
+		path = filepath.Join(dir, path)
 	}
+	return filepath.Abs(path)
 }
 
 func init() {
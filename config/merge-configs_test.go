@@ -0,0 +1,67 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package config_test
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	write := func(name string, body string) {
+		path := filepath.Join(home, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// $HOME/.clog.yaml is in the core search paths
+	write(".clog.yaml", `include:
+  - conf.d/*.yaml
+  - {path: missing.yaml, optional: true}
+clog:
+  jumbo: {font: home}
+  clogrc:
+    search-paths: [$HOME/first.yaml, $HOME/.clog.yaml, $HOME/extra.yaml]
+`)
+	write("conf.d/a.yaml", "clog: {jumbo: {font: a, sample: a}}\ninclude: ../.clog.yaml\n")
+	write("conf.d/b.yaml", "clog: {jumbo: {font: b}}\n")
+	write("first.yaml", "clog: {jumbo: {font: first}, log: {level: error}}\n")
+	write("extra.yaml", "clog: {jumbo: {sample: extra}, clogrc: {search-paths: [$HOME/first.yaml, $HOME/.clog.yaml, $HOME/extra.yaml, $HOME/more.yaml]}}\n")
+	write("more.yaml", "clog: {log: {level: warn}}\n")
+	write("override.yaml", "clog: {jumbo: {sample: override}}\n")
+	override := filepath.Join(home, "override.yaml")
+	cfg := config.New(&[]embed.FS{core.CoreFs}, &override)
+
+	Convey("includes should be merged straight after the file that includes them", t, func() {
+		So(cfg.GetString("clog.jumbo.font"), ShouldEqual, "b")
+		definitions := cfg.Explain("clog.jumbo.font")
+		So(definitions, ShouldHaveLength, 5)
+		So(definitions[2].Source, ShouldEqual, filepath.Join(home, ".clog.yaml"))
+		So(definitions[3].Source, ShouldEqual, filepath.Join(home, "conf.d/a.yaml"))
+		So(definitions[4].Winner, ShouldBeTrue)
+	})
+
+	Convey("a changed search path list should be merged again in order", t, func() {
+		So(cfg.GetString("clog.log.level"), ShouldEqual, "warn")
+		definitions := cfg.Explain("clog.jumbo.font")
+		So(definitions[1].Source, ShouldEqual, filepath.Join(home, "first.yaml"))
+		So(config.Layers()[1].Source, ShouldEqual, filepath.Join(home, "first.yaml"))
+	})
+
+	Convey("the override should be merged after the search paths", t, func() {
+		So(cfg.GetString("clog.jumbo.sample"), ShouldEqual, "override")
+		layers := config.Layers()
+		So(layers[len(layers)-1].Source, ShouldEqual, override)
+		So(*config.SearchPaths(), ShouldContain, override)
+	})
+}
@@ -0,0 +1,142 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - named profiles that overlay the config
+//
+//	profiles:
+//	  staging:
+//	    clog: {log: {level: debug}}
+//	    snippets: {deploy: ./deploy.sh --target staging}
+//	  prod:
+//	    extends: staging
+//	    snippets: {deploy: ./deploy.sh --target prod}
+//
+// `clog --profile prod` (or CLOG_PROFILE=prod) overlays staging and then
+// prod on the config once every file (the --config file too) has been merged
+// so a profile wins over every file.
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ProfileEnv selects a profile. clog --profile sets it so that nested clog
+// processes use the same profile.
+const ProfileEnv = "CLOG_PROFILE"
+
+// ProfilesKey is the map of named profiles
+var ProfilesKey = "profiles"
+
+// ExtendsKey names the profile that a profile overlays
+var ExtendsKey = "extends"
+
+// Profile returns the active profile or "" if there is none
+func Profile() string {
+	if cfg := Cfg(); cfg != nil {
+		return cfg.profile
+	}
+	return ""
+}
+
+// ProfileTag is " [profile prod]" for the version banner & menu header or ""
+// if there is no profile
+func ProfileTag() string {
+	profile := Profile()
+	if len(profile) == 0 {
+		return ""
+	}
+	return " [profile " + profile + "]"
+}
+
+// Profiles returns the names of the profiles in the config
+func (cfg *Config) Profiles() []string {
+	return slices.Sorted(maps.Keys(cfg.Viper.GetStringMap(ProfilesKey)))
+}
+
+// ProfileChain returns a profile and the profiles it extends, base first
+func (cfg *Config) ProfileChain(name string) ([]string, error) {
+	chain := []string{}
+	for next := name; len(next) > 0; next = cfg.Viper.GetString(ProfilesKey + "." + next + "." + ExtendsKey) {
+		if !cfg.IsSet(ProfilesKey + "." + next) {
+			return nil, fmt.Errorf("unknown profile (%s) - profiles are: %s", next, strings.Join(cfg.Profiles(), ", "))
+		}
+		if slices.Contains(chain, next) {
+			return nil, fmt.Errorf("profile %s extends itself (%s)", name, strings.Join(append(chain, next), " ⇒ "))
+		}
+		chain = append(chain, next)
+	}
+	slices.Reverse(chain)
+	return chain, nil
+}
+
+// applyProfile overlays the profiles of the chain in order. The part of a
+// profile in each file is recorded as a layer so Explain shows the line.
+func (cfg *Config) applyProfile(name string) error {
+	chain, err := cfg.ProfileChain(name)
+	if err != nil {
+		return err
+	}
+	fileLayers := slices.Clone(cfg.layers)
+	for _, p := range chain {
+		// viper returns its own maps & merges them by reference so the
+		// overlay is a copy to keep the profiles unchanged
+		overlay := deepCopy(cfg.Viper.GetStringMap(ProfilesKey + "." + p)).(map[string]any)
+		delete(overlay, ExtendsKey)
+		for _, layer := range fileLayers {
+			if node := layer.node(ProfilesKey + "." + p); node != nil {
+				cfg.layers = append(cfg.layers, Layer{Source: layer.Source + " (profile " + p + ")", root: node})
+			}
+		}
+		if err := cfg.MergeConfigMap(overlay); err != nil {
+			return fmt.Errorf("profile %s: %w", p, err)
+		}
+	}
+	cfg.profile = name
+	return nil
+}
+
+// deepCopy copies the maps & slices of a config value
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, item := range v {
+			c[key] = deepCopy(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	}
+	return value
+}
+
+// useProfile applies the profile named by CLOG_PROFILE (if any)
+func (cfg *Config) useProfile() error {
+	cfg.profile = ""
+	name := strings.TrimSpace(os.Getenv(ProfileEnv))
+	if len(name) == 0 {
+		return nil
+	}
+	if err := cfg.applyProfile(name); err != nil {
+		return err
+	}
+	slog.Debug("Using profile", "profile", name)
+	return nil
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
@@ -0,0 +1,84 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package config_test
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const profilesYaml = `snippets:
+  deploy: echo dev
+profiles:
+  staging:
+    clog: {jumbo: {font: staging}}
+    snippets: {deploy: echo staging}
+  prod:
+    extends: staging
+    snippets: {deploy: echo prod}
+  loop: {extends: loop}
+`
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".clog.yaml"), []byte(profilesYaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("a profile should overlay the profiles it extends", t, func() {
+		t.Setenv(config.ProfileEnv, "prod")
+		cfg := config.New(&[]embed.FS{core.CoreFs}, nil)
+		So(config.Profile(), ShouldEqual, "prod")
+		So(config.ProfileTag(), ShouldEqual, " [profile prod]")
+		So(cfg.GetString("snippets.deploy"), ShouldEqual, "echo prod")
+		So(cfg.GetString("clog.jumbo.font"), ShouldEqual, "staging")
+		chain, err := cfg.ProfileChain("prod")
+		So(err, ShouldBeNil)
+		So(chain, ShouldResemble, []string{"staging", "prod"})
+
+		definitions := cfg.Explain("snippets.deploy")
+		So(definitions, ShouldHaveLength, 3)
+		So(definitions[1].Source, ShouldEndWith, "(profile staging)")
+		So(definitions[2].Line, ShouldEqual, 9)
+		So(definitions[2].Winner, ShouldBeTrue)
+	})
+
+	Convey("a profile should win over the --config file & may be defined in it", t, func() {
+		t.Setenv(config.ProfileEnv, "ci")
+		override := filepath.Join(home, "override.yaml")
+		body := "snippets: {deploy: echo override}\nprofiles:\n  ci: {snippets: {deploy: echo ci}}\n"
+		if err := os.WriteFile(override, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.New(&[]embed.FS{core.CoreFs}, &override)
+		So(config.Profile(), ShouldEqual, "ci")
+		So(cfg.GetString("snippets.deploy"), ShouldEqual, "echo ci")
+
+		definitions := cfg.Explain("snippets.deploy")
+		So(definitions, ShouldHaveLength, 3)
+		So(definitions[1].Source, ShouldEqual, override)
+		So(definitions[2].Source, ShouldEqual, override+" (profile ci)")
+		So(definitions[2].Winner, ShouldBeTrue)
+	})
+
+	Convey("without a profile the base config should be used", t, func() {
+		t.Setenv(config.ProfileEnv, "")
+		cfg := config.New(&[]embed.FS{core.CoreFs}, nil)
+		So(config.Profile(), ShouldBeEmpty)
+		So(cfg.GetString("snippets.deploy"), ShouldEqual, "echo dev")
+		So(cfg.Profiles(), ShouldResemble, []string{"loop", "prod", "staging"})
+
+		_, err := cfg.ProfileChain("loop")
+		So(err, ShouldNotBeNil)
+		_, err = cfg.ProfileChain("nope")
+		So(err.Error(), ShouldStartWith, "unknown profile (nope)")
+	})
+}
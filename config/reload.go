@@ -0,0 +1,171 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - reload the config while clog runs
+//
+// A long running clog (e.g. the hookhandler service) can reload the config
+// when a file changes (see Watch and gommi's ChiMux.Serve) and the parts of
+// the app that use it can subscribe to the keys that changed:
+//
+//	config.Subscribe("svc.hooks", func(cfg *config.Config, changed []string) {
+//		hooks.Reload()
+//	})
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Subscription is called after a reload with the changed keys that it
+// subscribed to
+type Subscription func(cfg *Config, changed []string)
+
+type subscriber struct {
+	id     int
+	prefix string
+	fn     Subscription
+}
+
+var subscribers []subscriber
+var lastSubscriber int
+var subscribersMutex sync.Mutex
+
+// only one reload at a time
+var reloadMutex sync.Mutex
+
+// the values & aliases set while clog runs are kept when the config is
+// reloaded - a reload holds the mutex until the new config is current
+var runtimeValues = map[string]any{}
+var runtimeAliases = map[string]string{}
+var runtimeMutex sync.Mutex
+
+// Set sets a value while clog runs. It overrides the config files and is kept
+// when the config is reloaded. Viper is not safe for a Set while another
+// goroutine reads the config so set values before the config is shared.
+func (cfg *Config) Set(key string, value any) {
+	runtimeMutex.Lock()
+	defer runtimeMutex.Unlock()
+	runtimeValues[key] = value
+	cfg.Viper.Set(key, value)
+}
+
+// RegisterAlias makes an alias for a key that is kept when the config is
+// reloaded
+func (cfg *Config) RegisterAlias(alias string, key string) {
+	runtimeMutex.Lock()
+	defer runtimeMutex.Unlock()
+	runtimeAliases[alias] = key
+	cfg.Viper.RegisterAlias(alias, key)
+}
+
+// Subscribe calls fn after every reload that changes a key with the prefix
+// (e.g. clog.log or svc.hooks) or any key if the prefix is "". Call the
+// returned func to unsubscribe.
+func Subscribe(prefix string, fn Subscription) (unsubscribe func()) {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	lastSubscriber++
+	id := lastSubscriber
+	subscribers = append(subscribers, subscriber{id: id, prefix: strings.ToLower(prefix), fn: fn})
+	return func() {
+		subscribersMutex.Lock()
+		defer subscribersMutex.Unlock()
+		subscribers = slices.DeleteFunc(subscribers, func(s subscriber) bool { return s.id == id })
+	}
+}
+
+// Reload merges the config files again and validates them against the
+// schema. If there are errors they are logged and the current config is kept.
+// Otherwise the new config replaces the current one (see Cfg), the resolved
+// secrets are forgotten and the subscribers are called with the keys that
+// changed. A goroutine that is using the old config can finish with it.
+func Reload() error {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	next := &Config{Viper: viper.New()}
+	err := next.load()
+	if err == nil {
+		var errs []SchemaError
+		if errs, err = next.Validate(); len(errs) > 0 {
+			for _, e := range errs {
+				slog.Error(e.Error())
+			}
+			err = fmt.Errorf("%d schema errors", len(errs))
+		}
+	}
+	if err != nil {
+		slog.Error("config reload rejected - keeping the current config: " + err.Error())
+		return err
+	}
+	runtimeMutex.Lock()
+	for alias, key := range runtimeAliases {
+		next.Viper.RegisterAlias(alias, key)
+	}
+	for key, value := range runtimeValues {
+		next.Viper.Set(key, value)
+	}
+	old := current.Swap(next)
+	runtimeMutex.Unlock()
+	forgetSecrets()
+
+	changed := next.Viper.AllKeys()
+	if old != nil {
+		changed = changedKeys(old.Viper, next.Viper)
+	}
+	slog.Info("config reloaded", "changed", len(changed))
+	notify(next, changed)
+	return nil
+}
+
+// changedKeys returns the keys that were added, removed or changed
+func changedKeys(old *viper.Viper, next *viper.Viper) []string {
+	keys := append(old.AllKeys(), next.AllKeys()...)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	return slices.DeleteFunc(keys, func(key string) bool {
+		return reflect.DeepEqual(old.Get(key), next.Get(key))
+	})
+}
+
+// notify calls the subscribers of the changed keys. A subscriber that panics
+// is reported so the others are still called.
+func notify(cfg *Config, changed []string) {
+	subscribersMutex.Lock()
+	subs := slices.Clone(subscribers)
+	subscribersMutex.Unlock()
+	for _, s := range subs {
+		keys := []string{}
+		for _, key := range changed {
+			if len(s.prefix) == 0 || key == s.prefix || strings.HasPrefix(key, s.prefix+".") {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error(fmt.Sprintf("config subscriber (%s) failed: %v", s.prefix, r))
+				}
+			}()
+			s.fn(cfg, keys)
+		}()
+	}
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
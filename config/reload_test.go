@@ -0,0 +1,140 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package config_test
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReload(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	file := filepath.Join(home, ".clog.yaml")
+	write := func(body string) {
+		if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("svc: {port: 8000}\n")
+	cfg := config.New(&[]embed.FS{core.CoreFs}, nil)
+	cfg.Set("clog.version.short", "1.2.3")
+
+	changes := make(chan []string, 10)
+	unsubscribe := config.Subscribe("svc", func(cfg *config.Config, changed []string) {
+		changes <- changed
+	})
+	defer unsubscribe()
+
+	Convey("a reload should use the changed files & tell the subscribers", t, func() {
+		write("svc: {port: 9000, hookprefix: /hooks}\n")
+		So(config.Reload(), ShouldBeNil)
+		So(config.Cfg().GetInt("svc.port"), ShouldEqual, 9000)
+		So(config.Cfg().GetString("clog.version.short"), ShouldEqual, "1.2.3")
+		So(cfg.GetInt("svc.port"), ShouldEqual, 8000)
+		So(<-changes, ShouldResemble, []string{"svc.hookprefix", "svc.port"})
+	})
+
+	Convey("an invalid config should be rejected & the old one kept", t, func() {
+		write("svc: {port: nine}\n")
+		So(config.Reload(), ShouldNotBeNil)
+		So(config.Cfg().GetInt("svc.port"), ShouldEqual, 9000)
+		So(config.Layers()[len(config.Layers())-1].Source, ShouldEqual, file)
+		So(changes, ShouldBeEmpty)
+	})
+
+	Convey("a watched file should be reloaded once it stops changing", t, func() {
+		config.ReloadDelay = 50 * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- config.Watch(ctx) }()
+		time.Sleep(100 * time.Millisecond)
+		write("svc: {port: 9001}\n")
+		write("svc: {port: 9002}\n")
+
+		select {
+		case changed := <-changes:
+			So(changed, ShouldContain, "svc.port")
+		case <-time.After(5 * time.Second):
+			So("no reload", ShouldBeEmpty)
+		}
+		So(config.Cfg().GetInt("svc.port"), ShouldEqual, 9002)
+		cancel()
+		So(<-done, ShouldBeNil)
+	})
+	Convey("a reload should resolve the secrets again", t, func() {
+		secret := filepath.Join(home, "token.txt")
+		os.WriteFile(secret, []byte("first-token\n"), 0600)
+		write("svc: {port: 9002, token: secret://file/" + secret + "}\n")
+		So(config.Reload(), ShouldBeNil)
+		So(config.Cfg().GetString("svc.token"), ShouldEqual, "first-token")
+		os.WriteFile(secret, []byte("second-token\n"), 0600)
+		So(config.Cfg().GetString("svc.token"), ShouldEqual, "first-token")
+		So(config.Reload(), ShouldBeNil)
+		So(config.Cfg().GetString("svc.token"), ShouldEqual, "second-token")
+	})
+
+	Convey("the config should be safe to read during a reload", t, func() {
+		stop := make(chan bool)
+		started := make(chan bool)
+		read := make(chan int)
+		go func() {
+			reads := 0
+			for {
+				select {
+				case <-stop:
+					read <- reads
+					return
+				default:
+					config.Cfg().GetInt("svc.port")
+					if reads++; reads == 1 {
+						close(started)
+					}
+				}
+			}
+		}()
+		// the reads must overlap the reloads
+		<-started
+		for port := range 3 {
+			write(fmt.Sprintf("svc: {port: %d}\n", 9100+port))
+			So(config.Reload(), ShouldBeNil)
+		}
+		close(stop)
+		So(<-read, ShouldBeGreaterThan, 0)
+		So(config.Cfg().GetInt("svc.port"), ShouldEqual, 9102)
+	})
+
+	Convey("only the config files & include globs should be watched", t, func() {
+		os.MkdirAll(filepath.Join(home, "conf.d"), 0755)
+		write("include: {path: conf.d/*.yaml, optional: true}\nsvc: {port: 9200}\n")
+		So(config.Reload(), ShouldBeNil)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- config.Watch(ctx) }()
+		time.Sleep(100 * time.Millisecond)
+
+		before := config.Cfg()
+		os.WriteFile(filepath.Join(home, "releases.yaml"), []byte("svc: {port: 1}\n"), 0644)
+		os.WriteFile(filepath.Join(home, "docker-compose.yml"), []byte("services: {}\n"), 0644)
+		time.Sleep(4 * config.ReloadDelay)
+		So(config.Cfg(), ShouldEqual, before)
+
+		os.WriteFile(filepath.Join(home, "conf.d", "port.yaml"), []byte("svc: {port: 9201}\n"), 0644)
+		for deadline := time.Now().Add(5 * time.Second); config.Cfg() == before && time.Now().Before(deadline); {
+			time.Sleep(20 * time.Millisecond)
+		}
+		So(config.Cfg().GetInt("svc.port"), ShouldEqual, 9201)
+		cancel()
+		So(<-done, ShouldBeNil)
+	})
+}
@@ -0,0 +1,403 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - validate config layers against a JSON Schema
+//
+// The embedded core.clog.schema.json describes clog.yaml. Only the parts of
+// JSON Schema that it uses are supported: $ref to $defs, type, enum,
+// pattern, properties, additionalProperties, propertyNames, required, items
+// and anyOf. The yaml is validated as parsed so every error has the line &
+// column of the value in its file.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaFilename is the embedded schema of clog.yaml
+var SchemaFilename = "core.clog.schema.json"
+
+// Schema is a JSON Schema. The schema `false` is a Schema that never
+// matches and `true` is an empty Schema.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*Schema `json:"$defs"`
+	Description          string             `json:"description"`
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	PropertyNames        *Schema            `json:"propertyNames"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	never                bool
+	pattern              *regexp.Regexp
+	root                 *Schema
+}
+
+// SchemaError is a value in a config that does not match the schema
+type SchemaError struct {
+	Source  string `json:"source"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error is file:line:column: path: message
+func (e SchemaError) Error() string {
+	where := e.Source
+	if e.Line > 0 {
+		where = fmt.Sprintf("%s:%d:%d", e.Source, e.Line, e.Column)
+	}
+	if len(e.Path) == 0 {
+		return where + ": " + e.Message
+	}
+	return where + ": " + e.Path + ": " + e.Message
+}
+
+// schemaTypes is a type name or a list of them
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("schema type must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+// UnmarshalJSON accepts the boolean schemas true & false
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{never: !b}
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// LoadSchema parses a JSON Schema and compiles its patterns
+func LoadSchema(body []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := json.Unmarshal(body, s); err != nil {
+		return nil, fmt.Errorf("bad schema: %w", err)
+	}
+	if err := s.compile(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SchemaJSON returns the embedded schema of clog.yaml
+func SchemaJSON() ([]byte, error) {
+	fs, paths, err := FindEmbedded(SchemaFilename)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(paths[0])
+}
+
+// ClogSchema returns the parsed embedded schema of clog.yaml
+func ClogSchema() (*Schema, error) {
+	body, err := SchemaJSON()
+	if err != nil {
+		return nil, err
+	}
+	return LoadSchema(body)
+}
+
+// compile sets the root of every sub-schema and compiles the patterns
+func (s *Schema) compile(root *Schema) error {
+	if s == nil {
+		return nil
+	}
+	s.root = root
+	if len(s.Pattern) > 0 {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("bad schema pattern (%s): %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	children := []*Schema{s.AdditionalProperties, s.PropertyNames, s.Items}
+	children = append(children, s.AnyOf...)
+	for _, child := range s.Defs {
+		children = append(children, child)
+	}
+	for _, child := range s.Properties {
+		children = append(children, child)
+	}
+	for _, child := range children {
+		if err := child.compile(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve follows $ref to a definition in the root schema
+func (s *Schema) resolve() *Schema {
+	for len(s.Ref) > 0 {
+		name, found := strings.CutPrefix(s.Ref, "#/$defs/")
+		def := s.root.Defs[name]
+		if !found || def == nil {
+			return &Schema{never: true, root: s.root, Description: "unknown $ref " + s.Ref}
+		}
+		s = def
+	}
+	return s
+}
+
+// Validate checks a layer against the schema. A layer that could not be
+// parsed has a single error.
+func (s *Schema) Validate(l Layer) []SchemaError {
+	v := &validation{source: l.Source}
+	if l.err != nil {
+		return []SchemaError{{Source: l.Source, Message: l.err.Error()}}
+	}
+	// a profile layer is validated as a part of its file
+	if l.root == nil || l.root.Kind != yaml.DocumentNode || len(l.root.Content) == 0 {
+		return nil
+	}
+	v.check(l.root.Content[0], s, "")
+	return v.errs
+}
+
+// Validate checks every layer of the config against the embedded schema
+func (cfg *Config) Validate() ([]SchemaError, error) {
+	s, err := ClogSchema()
+	if err != nil {
+		return nil, err
+	}
+	errs := []SchemaError{}
+	for _, layer := range cfg.layers {
+		errs = append(errs, s.Validate(layer)...)
+	}
+	return errs, nil
+}
+
+type validation struct {
+	source string
+	errs   []SchemaError
+}
+
+func (v *validation) fail(node *yaml.Node, path string, format string, args ...any) {
+	v.errs = append(v.errs, SchemaError{
+		Source:  v.source,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check validates node against s & records the errors
+func (v *validation) check(node *yaml.Node, s *Schema, path string) {
+	node = resolveAlias(node)
+	s = s.resolve()
+	if s.never {
+		v.fail(node, path, "is not allowed")
+		return
+	}
+	if len(s.AnyOf) > 0 {
+		v.checkAnyOf(node, s, path)
+	}
+	kind := nodeType(node)
+	if len(s.Type) > 0 && !slices.Contains(s.Type, kind) && !(kind == "integer" && slices.Contains(s.Type, "number")) {
+		v.fail(node, path, "must be %s (not %s)", strings.Join(s.Type, " or "), kind)
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(node, s.Enum) {
+		v.fail(node, path, "must be one of %s (not %s)", enumList(s.Enum), node.Value)
+	}
+	if s.pattern != nil && kind == "string" && !s.pattern.MatchString(node.Value) {
+		v.fail(node, path, "must match %s (not %s)", s.Pattern, node.Value)
+	}
+	switch kind {
+	case "object":
+		v.checkObject(node, s, path)
+	case "array":
+		if s.Items != nil {
+			for i, item := range node.Content {
+				v.check(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	}
+}
+
+func (v *validation) checkObject(node *yaml.Node, s *Schema, path string) {
+	pairs := mappingPairs(node)
+	for _, name := range s.Required {
+		if !slices.ContainsFunc(pairs, func(p [2]*yaml.Node) bool { return p[0].Value == name }) {
+			v.fail(node, path, "needs the key %s", name)
+		}
+	}
+	for _, pair := range pairs {
+		key, value := pair[0], pair[1]
+		keyPath := strings.TrimPrefix(path+"."+key.Value, ".")
+		if s.PropertyNames != nil {
+			names := s.PropertyNames.resolve()
+			if names.pattern != nil && !names.pattern.MatchString(key.Value) {
+				v.fail(key, keyPath, "bad key - %s", describe(names))
+				if _, known := s.Properties[key.Value]; !known {
+					continue
+				}
+			}
+		}
+		if property, known := s.Properties[key.Value]; known {
+			v.check(value, property, keyPath)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties == nil:
+		case s.AdditionalProperties.never:
+			v.fail(key, keyPath, "unknown key%s", suggest(key.Value, s.Properties))
+		default:
+			v.check(value, s.AdditionalProperties, keyPath)
+		}
+	}
+}
+
+// checkAnyOf passes if any schema of s.AnyOf matches. Otherwise the errors
+// of the only choice of the right type are reported or a summary of the
+// choices.
+func (v *validation) checkAnyOf(node *yaml.Node, s *Schema, path string) {
+	var typed [][]SchemaError
+	keys := []string{}
+	for _, choice := range s.AnyOf {
+		trial := &validation{source: v.source}
+		trial.check(node, choice, path)
+		if len(trial.errs) == 0 {
+			return
+		}
+		resolved := choice.resolve()
+		if len(resolved.Type) > 0 && slices.Contains(resolved.Type, nodeType(node)) {
+			typed = append(typed, trial.errs)
+		}
+		if len(resolved.Type) == 0 && len(resolved.Required) > 0 {
+			keys = append(keys, resolved.Required...)
+		}
+	}
+	switch {
+	case len(typed) == 1:
+		v.errs = append(v.errs, typed[0]...)
+	case len(keys) == len(s.AnyOf):
+		v.fail(node, path, "needs one of the keys %s", strings.Join(keys, ", "))
+	case len(s.Description) > 0:
+		v.fail(node, path, "must be %s", s.Description)
+	default:
+		summary := []string{}
+		for _, choice := range s.AnyOf {
+			summary = append(summary, describe(choice.resolve()))
+		}
+		v.fail(node, path, "must be %s", strings.Join(summary, " or "))
+	}
+}
+
+// describe summarises a schema for an error message
+func describe(s *Schema) string {
+	switch {
+	case len(s.Description) > 0 && len(s.Properties) == 0:
+		return s.Description
+	case len(s.Enum) > 0:
+		return "one of " + enumList(s.Enum)
+	case len(s.Type) > 0:
+		return strings.Join(s.Type, " or ")
+	}
+	return "valid"
+}
+
+// suggest finds a known key that is the same apart from case, underscores
+// or a missing hyphen
+func suggest(key string, known map[string]*Schema) string {
+	simplify := func(k string) string {
+		return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(k))
+	}
+	for name := range known {
+		if simplify(name) == simplify(key) {
+			return " - did you mean " + name + "?"
+		}
+	}
+	return ""
+}
+
+// mappingPairs returns the key & value nodes of a mapping including the
+// keys merged in with <<
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	pairs := [][2]*yaml.Node{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Tag == "!!merge" {
+			merged := []*yaml.Node{resolveAlias(value)}
+			if merged[0].Kind == yaml.SequenceNode {
+				merged = merged[0].Content
+			}
+			for _, m := range merged {
+				pairs = append(pairs, mappingPairs(resolveAlias(m))...)
+			}
+			continue
+		}
+		pairs = append(pairs, [2]*yaml.Node{key, value})
+	}
+	return pairs
+}
+
+// nodeType is the JSON type of a yaml node
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
+
+func inEnum(node *yaml.Node, enum []any) bool {
+	return node.Kind == yaml.ScalarNode && slices.ContainsFunc(enum, func(e any) bool {
+		return fmt.Sprint(e) == node.Value
+	})
+}
+
+func enumList(enum []any) string {
+	names := []string{}
+	for _, e := range enum {
+		names = append(names, fmt.Sprint(e))
+	}
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
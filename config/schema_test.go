@@ -0,0 +1,69 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mrmxf/clog/config"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const badYaml = `clog:
+  log:
+    level: verbose
+    sinks:
+      - {style: json, add_source: true}
+  log_level: 3
+check:
+  pre-build:
+    blocks:
+      - ok: echo ok
+snippets:
+  my-group:
+    my_snippet: echo hello
+`
+
+func TestSchema(t *testing.T) {
+	body, err := os.ReadFile("../core/core.clog.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := config.LoadSchema(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("the embedded core config should match the schema", t, func() {
+		core, err := os.ReadFile("../core/core.clog.yaml")
+		So(err, ShouldBeNil)
+		layer, err := config.NewLayer("core.clog.yaml", core)
+		So(err, ShouldBeNil)
+		So(schema.Validate(layer), ShouldBeEmpty)
+	})
+
+	Convey("every mistake should be reported with its line & column", t, func() {
+		layer, err := config.NewLayer("bad.yaml", []byte(badYaml))
+		So(err, ShouldBeNil)
+		errs := schema.Validate(layer)
+		messages := []string{}
+		for _, e := range errs {
+			messages = append(messages, e.Error())
+		}
+		So(messages, ShouldHaveLength, 5)
+		So(messages[0], ShouldStartWith, "bad.yaml:3:12: clog.log.level: must be trace")
+		So(messages[1], ShouldEqual, "bad.yaml:5:23: clog.log.sinks[0].add_source: unknown key - did you mean add-source?")
+		So(messages[2], ShouldStartWith, "bad.yaml:6:3: clog.log_level: bad key")
+		So(messages[3], ShouldEqual, "bad.yaml:10:9: check.pre-build.blocks[0]: needs one of the keys try, assert, finally")
+		So(messages[4], ShouldStartWith, "bad.yaml:13:5: snippets.my-group.my_snippet: bad key")
+	})
+
+	Convey("a config that cannot be parsed should be one error", t, func() {
+		layer, _ := config.NewLayer("broken.yaml", []byte("clog: [unclosed"))
+		errs := schema.Validate(layer)
+		So(errs, ShouldHaveLength, 1)
+		So(errs[0].Error(), ShouldStartWith, "broken.yaml: yaml:")
+	})
+}
@@ -0,0 +1,234 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - the age encrypted secrets file
+//
+// The secrets file is yaml encrypted with age (https://age-encryption.org)
+// for every recipient in clog.secrets.recipients so that it can be committed
+// to the repo. Each user decrypts it with their own identity. The age &
+// age-keygen tools must be installed.
+//
+//	clog:
+//	  secrets:
+//	    file: clogrc/secrets.age
+//	    identity: ~/.config/clog/age.key       # or CLOG_AGE_IDENTITY
+//	    recipients: [age1..., age1...]          # default: the identity's key
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AgeIdentityEnv overrides clog.secrets.identity
+const AgeIdentityEnv = "CLOG_AGE_IDENTITY"
+
+// SecretsFileKey is the age file managed by clog Secret
+var SecretsFileKey = "clog.secrets.file"
+
+// SecretsIdentityKey is the age identity (private key) file
+var SecretsIdentityKey = "clog.secrets.identity"
+
+// SecretsRecipientsKey is the list of age public keys that can decrypt
+var SecretsRecipientsKey = "clog.secrets.recipients"
+
+var defaultSecretsFile = "clogrc/secrets.age"
+var defaultAgeIdentity = "~/.config/clog/age.key"
+
+// the decrypted age files - guarded by secretMutex
+var ageFiles = map[string]map[string]any{}
+
+// SecretsFile returns the age file managed by clog Secret
+func (cfg *Config) SecretsFile() string {
+	if file := cfg.Viper.GetString(SecretsFileKey); len(file) > 0 {
+		return file
+	}
+	return defaultSecretsFile
+}
+
+// AgeIdentity returns the path of the age identity used to decrypt
+func (cfg *Config) AgeIdentity() (string, error) {
+	identity := os.Getenv(AgeIdentityEnv)
+	if len(identity) == 0 {
+		identity = cfg.Viper.GetString(SecretsIdentityKey)
+	}
+	if len(identity) == 0 {
+		identity = defaultAgeIdentity
+	}
+	return expandPath(identity, "")
+}
+
+// AgeRecipients returns the public keys that the secrets file is encrypted
+// for. If none are configured then the key of the identity is used.
+func (cfg *Config) AgeRecipients() ([]string, error) {
+	if recipients := cfg.Viper.GetStringSlice(SecretsRecipientsKey); len(recipients) > 0 {
+		return recipients, nil
+	}
+	identity, err := cfg.AgeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	out, err := runSecretTool(nil, "age-keygen", "-y", identity)
+	if err != nil {
+		return nil, fmt.Errorf("no %s and no public key for %s: %w", SecretsRecipientsKey, identity, err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// ReadSecrets decrypts an age file of yaml secrets. A missing file is an
+// error that matches os.ErrNotExist.
+func (cfg *Config) ReadSecrets(path string) (map[string]any, error) {
+	plain, err := cfg.ReadSecretsYaml(path)
+	if err != nil {
+		return nil, err
+	}
+	secrets := map[string]any{}
+	if err := yaml.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("%s is not yaml: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// ReadSecretsYaml decrypts an age file to yaml
+func (cfg *Config) ReadSecretsYaml(path string) ([]byte, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	identity, err := cfg.AgeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	plain, err := runSecretTool(nil, "age", "--decrypt", "--identity", identity, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// WriteSecrets encrypts yaml secrets to an age file for every recipient
+func (cfg *Config) WriteSecrets(path string, secrets map[string]any) error {
+	plain, err := yaml.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	return cfg.WriteSecretsYaml(path, plain)
+}
+
+// WriteSecretsYaml encrypts yaml to an (armored) age file for every recipient
+func (cfg *Config) WriteSecretsYaml(path string, plain []byte) error {
+	recipients, err := cfg.AgeRecipients()
+	if err != nil {
+		return err
+	}
+	args := []string{"--encrypt", "--armor"}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+	cipher, err := runSecretTool(plain, "age", args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, cipher, 0o644); err != nil {
+		return err
+	}
+	secretMutex.Lock()
+	delete(ageFiles, path)
+	secretMutex.Unlock()
+	return nil
+}
+
+// SecretKeys returns the dotted keys of the values in the secrets
+func SecretKeys(secrets map[string]any) []string {
+	keys := []string{}
+	for key, value := range secrets {
+		if m, isMap := value.(map[string]any); isMap {
+			for _, sub := range SecretKeys(m) {
+				keys = append(keys, key+"."+sub)
+			}
+			continue
+		}
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// SecretValue returns the value of a dotted key in the secrets
+func SecretValue(secrets map[string]any, key string) (string, error) {
+	var value any = secrets
+	for _, part := range strings.Split(key, ".") {
+		m, isMap := value.(map[string]any)
+		if !isMap {
+			return "", fmt.Errorf("no secret %s", key)
+		}
+		if value, isMap = m[part]; !isMap {
+			return "", fmt.Errorf("no secret %s - keys are: %s", key, strings.Join(SecretKeys(secrets), ", "))
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case map[string]any:
+		return "", fmt.Errorf("%s is a map of secrets (%s) not a secret", key, strings.Join(slices.Sorted(maps.Keys(v)), ", "))
+	case []any:
+		return "", fmt.Errorf("%s is a list not a secret", key)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// SetSecretValue sets a dotted key in the secrets
+func SetSecretValue(secrets map[string]any, key string, value string) error {
+	parts := strings.Split(key, ".")
+	m := secrets
+	for i, part := range parts[:len(parts)-1] {
+		switch next := m[part].(type) {
+		case map[string]any:
+			m = next
+		case nil:
+			m[part] = map[string]any{}
+			m = m[part].(map[string]any)
+		default:
+			return fmt.Errorf("%s is a secret not a map", strings.Join(parts[:i+1], "."))
+		}
+	}
+	if _, isMap := m[parts[len(parts)-1]].(map[string]any); isMap {
+		return fmt.Errorf("%s is a map of secrets", key)
+	}
+	m[parts[len(parts)-1]] = value
+	return nil
+}
+
+// ageSecret returns a key of an age file. The caller holds secretMutex.
+func (cfg *Config) ageSecret(path string, key string) (string, error) {
+	if len(key) == 0 {
+		return "", errors.New("no key - use secret://age/" + path + "#key")
+	}
+	secrets, found := ageFiles[path]
+	if !found {
+		var err error
+		if secrets, err = cfg.ReadSecrets(path); err != nil {
+			return "", err
+		}
+		ageFiles[path] = secrets
+	}
+	return SecretValue(secrets, key)
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
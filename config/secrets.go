@@ -0,0 +1,205 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - secret references in config values
+//
+// A string value can be a reference to a secret that is resolved when the
+// value of its key is read (e.g. with GetString) or when the snippet runs:
+//
+//	secret://env/NAME                    an env variable
+//	secret://file/path                   a file (e.g. secret://file//run/secrets/db)
+//	secret://pass/entry                  the first line of `pass show entry`
+//	secret://age/file.age#key.path       a key of an age encrypted yaml file
+//
+// Resolved values are masked in logs & script output. The references (not
+// the values) are kept in the config so clog Config shows the references.
+// A map (e.g. snippets) keeps its references so that only the secrets that
+// are used are resolved.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mrmxf/clog/slogger"
+	"github.com/spf13/cast"
+)
+
+// SecretScheme is the prefix of a secret reference
+const SecretScheme = "secret://"
+
+// the resolved secrets
+var secretCache = map[string]string{}
+var secretMutex sync.Mutex
+
+// IsSecretRef is true for a secret reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretScheme)
+}
+
+// ResolveSecret returns the value of a secret reference. The value is cached
+// for the life of the process and masked in logs.
+func (cfg *Config) ResolveSecret(ref string) (string, error) {
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+	if value, found := secretCache[ref]; found {
+		return value, nil
+	}
+	kind, location, _ := strings.Cut(strings.TrimPrefix(ref, SecretScheme), "/")
+	var value string
+	var err error
+	switch kind {
+	case "env":
+		var set bool
+		if value, set = os.LookupEnv(location); !set {
+			err = fmt.Errorf("env var %s is not set", location)
+		}
+	case "file":
+		value, err = readSecretFile(location)
+	case "pass":
+		value, err = passShow(location)
+	case "age":
+		path, key, _ := strings.Cut(location, "#")
+		value, err = cfg.ageSecret(path, key)
+	default:
+		err = fmt.Errorf("unknown secret type (%s) - use env, file, pass or age", kind)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	secretCache[ref] = value
+	slogger.MarkSecret(value)
+	return value, nil
+}
+
+// forgetSecrets clears the resolved secrets so a reloaded config resolves
+// them again. The old values are still masked in logs.
+func forgetSecrets() {
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+	clear(secretCache)
+	clear(ageFiles)
+}
+
+// Get returns the value of a key with a secret reference resolved. Only a
+// string value is resolved - the references in a map or a list are kept so
+// they are resolved by the code that uses them. Use cfg.Viper.Get for the
+// value with the reference.
+func (cfg *Config) Get(key string) any {
+	value := cfg.Viper.Get(key)
+	if ref, isString := value.(string); isString {
+		return cfg.mustResolve(ref)
+	}
+	return value
+}
+
+// GetString returns the value of a key as a string with a secret reference
+// resolved. clog exits if the reference cannot be resolved - use GetStringE
+// to handle the error.
+func (cfg *Config) GetString(key string) string {
+	return cast.ToString(cfg.Get(key))
+}
+
+// GetStringE returns the value of a key as a string with a secret reference
+// resolved or the error if the reference cannot be resolved
+func (cfg *Config) GetStringE(key string) (string, error) {
+	return cfg.ResolveString(cfg.Viper.GetString(key))
+}
+
+// GetStringSlice returns the value of a key as a []string with the secret
+// references resolved
+func (cfg *Config) GetStringSlice(key string) []string {
+	values := slices.Clone(cfg.Viper.GetStringSlice(key))
+	for i, value := range values {
+		values[i] = cfg.mustResolve(value)
+	}
+	return values
+}
+
+// GetStringMap returns the value of a key as a map. The secret references
+// are kept - resolve the value that is used with ResolveString.
+func (cfg *Config) GetStringMap(key string) map[string]any {
+	return cfg.Viper.GetStringMap(key)
+}
+
+// GetStringMapString returns the value of a key as a map of strings. The
+// secret references are kept - resolve the value that is used with
+// ResolveString.
+func (cfg *Config) GetStringMapString(key string) map[string]string {
+	return cfg.Viper.GetStringMapString(key)
+}
+
+// ResolveString returns a value with a secret reference resolved. A value
+// that is not a reference is returned unchanged.
+func (cfg *Config) ResolveString(value string) (string, error) {
+	if !IsSecretRef(value) {
+		return value, nil
+	}
+	return cfg.ResolveSecret(value)
+}
+
+// mustResolve resolves a secret reference or exits so that nothing runs with
+// an empty credential
+func (cfg *Config) mustResolve(value string) string {
+	secret, err := cfg.ResolveString(value)
+	if err != nil {
+		slog.Error("cannot resolve secret " + err.Error())
+		slogger.Exit(1)
+	}
+	return secret
+}
+
+// readSecretFile reads a secret file without the trailing newline
+func readSecretFile(location string) (string, error) {
+	path, err := expandPath(location, "")
+	if err != nil {
+		return "", err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(body), "\r\n"), nil
+}
+
+// passShow returns the first line of a pass entry - pass may ask for the
+// passphrase of the gpg key
+func passShow(entry string) (string, error) {
+	out, err := runSecretTool(nil, "pass", "show", entry)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimRight(line, "\r"), nil
+}
+
+// runSecretTool runs a tool with stdin & returns stdout. The terminal is
+// kept for stderr so the tool can ask for a passphrase.
+func runSecretTool(stdin []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	} else {
+		cmd.Stdin = os.Stdin
+	}
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", name, err)
+	}
+	return out, nil
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
@@ -0,0 +1,112 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package config_test
+
+import (
+	"embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/core"
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const secretsYaml = `clog:
+  secrets:
+    file: secrets.age
+snippets:
+  token: secret://env/CLOG_TEST_TOKEN
+  key: secret://file/key.txt
+  db: secret://age/secrets.age#db.password
+  missing: secret://env/CLOG_TEST_MISSING
+  unused: secret://pass/team/unused
+`
+
+// fakeAge stands in for age & age-keygen - it does not encrypt
+const fakeAge = `#!/bin/sh
+case "$1" in
+  --decrypt) for f; do :; done; cat "$f" ;;
+  -y) echo age1fake ;;
+  *) cat ;;
+esac
+`
+
+// fakePass records that it ran
+const fakePass = `#!/bin/sh
+touch "$HOME/pass-ran"
+echo pass-secret
+`
+
+func TestSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(home)
+	t.Setenv("CLOG_TEST_TOKEN", "token-from-the-env")
+	bin := filepath.Join(home, "bin")
+	files := map[string]string{
+		".clog.yaml":     secretsYaml,
+		"key.txt":        "key-from-a-file\n",
+		"bin/age":        fakeAge,
+		"bin/age-keygen": fakeAge,
+		"bin/pass":       fakePass,
+		"secrets.age":    "db: {password: password-from-age}\n",
+	}
+	for name, body := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(home, name)), 0755)
+		if err := os.WriteFile(filepath.Join(home, name), []byte(body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	cfg := config.New(&[]embed.FS{core.CoreFs}, nil)
+
+	Convey("secret references should be resolved when they are read", t, func() {
+		So(cfg.GetString("snippets.token"), ShouldEqual, "token-from-the-env")
+		So(cfg.GetString("snippets.key"), ShouldEqual, "key-from-a-file")
+		So(cfg.GetString("snippets.db"), ShouldEqual, "password-from-age")
+		So(cfg.Viper.GetString("snippets.token"), ShouldEqual, "secret://env/CLOG_TEST_TOKEN")
+		value, err := cfg.ResolveString("not a reference")
+		So(err, ShouldBeNil)
+		So(value, ShouldEqual, "not a reference")
+	})
+
+	Convey("a map should keep its references so unused secrets are not resolved", t, func() {
+		So(cfg.GetStringMapString("snippets")["token"], ShouldEqual, "secret://env/CLOG_TEST_TOKEN")
+		So(cfg.GetStringMap("snippets")["unused"], ShouldEqual, "secret://pass/team/unused")
+		_, err := os.Stat(filepath.Join(home, "pass-ran"))
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+
+	Convey("a reference that cannot be resolved should be an error", t, func() {
+		value, err := cfg.GetStringE("snippets.missing")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "CLOG_TEST_MISSING is not set")
+		So(value, ShouldBeEmpty)
+	})
+
+	Convey("resolved secrets should be masked in logs", t, func() {
+		So(slogger.Redact("token=token-from-the-env"), ShouldNotContainSubstring, "token-from-the-env")
+		So(slogger.Redact("db password-from-age"), ShouldNotContainSubstring, "password-from-age")
+	})
+
+	Convey("the secrets file should be updated by key", t, func() {
+		secrets, err := cfg.ReadSecrets(cfg.SecretsFile())
+		So(err, ShouldBeNil)
+		So(config.SetSecretValue(secrets, "slack.webhook", "https://hooks.example.com"), ShouldBeNil)
+		So(config.SetSecretValue(secrets, "db", "flat"), ShouldNotBeNil)
+		So(cfg.WriteSecrets(cfg.SecretsFile(), secrets), ShouldBeNil)
+
+		secrets, err = cfg.ReadSecrets(cfg.SecretsFile())
+		So(err, ShouldBeNil)
+		So(config.SecretKeys(secrets), ShouldResemble, []string{"db.password", "slack.webhook"})
+		value, err := config.SecretValue(secrets, "slack.webhook")
+		So(err, ShouldBeNil)
+		So(value, ShouldEqual, "https://hooks.example.com")
+		_, err = config.SecretValue(secrets, "db")
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,124 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+//
+// clog's config package - watch the config files & reload on change
+
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ReloadDelay is how long the config files must be unchanged before a
+// reload so that a burst of writes (e.g. an editor saving) is one reload
+var ReloadDelay = 500 * time.Millisecond
+
+// ConfigFiles returns the config files of the current config that were
+// merged or searched for
+func ConfigFiles() []string {
+	if cfg := Cfg(); cfg != nil {
+		return cfg.configFiles
+	}
+	return nil
+}
+
+// Watch reloads the config (see Reload) when a config file changes until
+// the context is done. The folders of the merged files and of the search
+// paths are watched so that a file that is replaced, created or deleted is
+// seen as well as a new file matching an include glob. Other files in the
+// folders (e.g. releases.yaml) are ignored.
+func Watch(ctx context.Context) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	files := watchFiles(w)
+
+	// a stopped timer that is reset by every change
+	debounce := time.NewTimer(ReloadDelay)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			debounce.Stop()
+			return nil
+		case err := <-w.Errors:
+			slog.Warn("config watch: " + err.Error())
+		case e := <-w.Events:
+			if e.Op == fsnotify.Chmod || !files.matches(e.Name) {
+				continue
+			}
+			slog.Debug("config file changed", "path", e.Name, "op", e.Op.String())
+			debounce.Reset(ReloadDelay)
+		case <-debounce.C:
+			if Reload() == nil {
+				files = watchFiles(w)
+			}
+		}
+	}
+}
+
+// watched is what a changed file must match to reload the config
+type watched struct {
+	files map[string]bool // the config files that were merged or searched for
+	globs []string        // the include: globs
+}
+
+// watchFiles watches the folders of the files & include globs of the
+// current config. Folders that do not exist are skipped.
+func watchFiles(w *fsnotify.Watcher) watched {
+	files := watched{files: map[string]bool{}}
+	dirs := []string{}
+	for _, file := range ConfigFiles() {
+		files.files[file] = true
+		dirs = append(dirs, filepath.Dir(file))
+	}
+	if cfg := Cfg(); cfg != nil {
+		files.globs = cfg.includes
+		for _, glob := range cfg.includes {
+			// a folder that is a glob cannot be watched
+			if dir := filepath.Dir(glob); !strings.ContainsAny(dir, "*?[") {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err != nil || slices.Contains(w.WatchList(), dir) {
+			continue
+		}
+		if err := w.Add(dir); err != nil {
+			slog.Warn("config watch: cannot watch " + dir + ": " + err.Error())
+		}
+	}
+	return files
+}
+
+// matches is true for a config file or a file that matches an include glob
+func (f watched) matches(path string) bool {
+	path = filepath.Clean(path)
+	if f.files[path] {
+		return true
+	}
+	for _, glob := range f.globs {
+		if match, _ := filepath.Match(glob, path); match {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	// log the order of the init files in case there are problems
+	_, file, _, _ := runtime.Caller(0)
+	slog.Debug("init " + file)
+}
@@ -21,6 +21,7 @@ import (
 
 //go:embed all:tpl
 //go:embed core.clog.yaml
+//go:embed core.clog.schema.json
 var CoreFs embed.FS
 
 // var discardPrefix is for discarding prefixes that are added when using
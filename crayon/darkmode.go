@@ -0,0 +1,53 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License  https://opensource.org/license/bsd-3-clause/
+
+package crayon
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DetectDarkMode guesses if the terminal has a dark background. CLOG_DARKMODE
+// (true/false) wins, otherwise the background from COLORFGBG (e.g. "15;0") is
+// used. Unknown terminals are treated as light.
+func DetectDarkMode() bool {
+	if v, err := strconv.ParseBool(os.Getenv("CLOG_DARKMODE")); err == nil {
+		return v
+	}
+	fgbg := strings.Split(os.Getenv("COLORFGBG"), ";")
+	bg, err := strconv.Atoi(fgbg[len(fgbg)-1])
+	if err != nil {
+		return false
+	}
+	// ANSI colors 0-6 & 8 are dark backgrounds, 7 & 9-15 are light
+	return bg <= 6 || bg == 8
+}
+
+// Role returns the ANSI escape sequence for a role name (e.g. "info",
+// "warning") and false if the role is unknown. The sequence is empty if
+// colour is disabled (e.g. NO_COLOR is set).
+func Role(name string) (string, bool) {
+	c := Color()
+	roles := map[string]func(a ...interface{}) string{
+		"builtin": c.Builtin,
+		"command": c.Command,
+		"debug":   c.Dim,
+		"dim":     c.Dim,
+		"error":   c.Error,
+		"file":    c.File,
+		"heading": c.Heading,
+		"info":    c.Info,
+		"success": c.Success,
+		"text":    c.Text,
+		"url":     c.Url,
+		"warning": c.Warning,
+	}
+	sprint, ok := roles[strings.ToLower(name)]
+	if !ok {
+		return "", false
+	}
+	// keep the escape sequence in front of the text
+	return strings.Split(sprint("XXX"), "XXX")[0], true
+}
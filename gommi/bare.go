@@ -10,6 +10,8 @@
 //    	r.NewEmbedFileServer(eFS, "/", "embedWWW/")
 //     	http.ListenAndServe("0.0.0.0:8080", r)
 //
+// or use r.Serve(ctx, "0.0.0.0:8080") to reload the config while it serves.
+//
 // Usually pages are served from:
 //  - templates in the embedded file system for basic server side rendering
 //  - the embedded file system for non-templates
@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/mrmxf/clog/slogger"
 )
 
 func setContentType(w http.ResponseWriter, r *http.Request) {
@@ -214,7 +215,7 @@ func (r *ChiMux) NewFileServer(prefix string, mountPath string) error {
 		msg := "gommi.NewEmbedFileServer cannot find mountPath"
 		slog.Error(msg, "mountPath", mountPath)
 		if opt.AbortOnError {
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 		return errors.New(msg)
 	}
@@ -0,0 +1,21 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package gommi
+
+import (
+	"github.com/mrmxf/clog/slogger"
+)
+
+// MountLogLevels adds an admin endpoint that reads & changes the levels of
+// the default slogger logger while the server runs e.g.
+//
+//	r.MountLogLevels("/admin/log-levels", os.Getenv("ADMIN_TOKEN"))
+//	curl -X PUT -H "Authorization: Bearer $ADMIN_TOKEN" \
+//	  "localhost:8080/admin/log-levels?component=gommi&level=debug"
+//
+// If token is empty the endpoint is open, so only do that on a private port.
+// See slogger.LevelsHTTPHandler for the requests.
+func (m *ChiMux) MountLogLevels(pattern string, token string) {
+	m.Handle(pattern, slogger.LevelsHTTPHandler(slogger.LogLevels(), token))
+}
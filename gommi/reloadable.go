@@ -0,0 +1,39 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package gommi
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Reloadable is a handler whose routes are rebuilt while the server runs
+// e.g. when the config is reloaded
+type Reloadable struct {
+	build   func() http.Handler
+	handler atomic.Pointer[http.Handler]
+}
+
+// MountReloadable mounts the routes made by build. Call Reload to replace
+// them - requests in flight finish with the old routes e.g.
+//
+//	hooks := r.MountReloadable("/hook", func() http.Handler { return hookRoutes(config.Cfg()) })
+//	config.Subscribe("svc.hooks", func(*config.Config, []string) { hooks.Reload() })
+func (m *ChiMux) MountReloadable(pattern string, build func() http.Handler) *Reloadable {
+	r := &Reloadable{build: build}
+	r.Reload()
+	m.Mount(pattern, r)
+	return r
+}
+
+// Reload builds the routes again
+func (r *Reloadable) Reload() {
+	h := r.build()
+	r.handler.Store(&h)
+}
+
+// ServeHTTP serves a request with the current routes
+func (r *Reloadable) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	(*r.handler.Load()).ServeHTTP(w, req)
+}
@@ -0,0 +1,45 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package gommi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mrmxf/clog/config"
+)
+
+// Serve serves the mux on addr until ctx is done. The config files are
+// watched while it serves (see config.Watch) so a changed file is reloaded
+// and the routes mounted with MountReloadable are rebuilt by their
+// subscriptions e.g.
+//
+//	r, _ := gommi.Bare()
+//	hooks := r.MountReloadable("/hook", func() http.Handler { return hookRoutes(config.Cfg()) })
+//	config.Subscribe("svc.hooks", func(*config.Config, []string) { hooks.Reload() })
+//	r.Serve(ctx, fmt.Sprintf(":%d", config.Cfg().GetInt("svc.port")))
+func (m *ChiMux) Serve(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	watched := make(chan error, 1)
+	if config.Cfg() != nil {
+		go func() { watched <- config.Watch(ctx) }()
+	} else {
+		watched <- nil
+	}
+
+	srv := &http.Server{Addr: addr, Handler: m}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	cancel()
+	return errors.Join(err, <-watched)
+}
@@ -0,0 +1,85 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package gommi_test
+
+import (
+	"context"
+	"embed"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/core"
+	"github.com/mrmxf/clog/gommi"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestServe(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	write := func(body string) {
+		if err := os.WriteFile(filepath.Join(home, ".clog.yaml"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("svc: {hookprefix: /first}\n")
+	config.New(&[]embed.FS{core.CoreFs}, nil)
+	config.ReloadDelay = 50 * time.Millisecond
+
+	// a free port for the server
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	get := func() string {
+		res, err := http.Get("http://" + addr + "/hook/")
+		if err != nil {
+			return ""
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	// poll until the server returns want or give up
+	waitFor := func(want string) string {
+		got := ""
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+			if got = get(); got == want {
+				break
+			}
+		}
+		return got
+	}
+
+	Convey("a served mux should rebuild its routes when the config changes", t, func() {
+		r, _ := gommi.Bare(gommi.Options{Logger: slog.New(slog.DiscardHandler)})
+		hooks := r.MountReloadable("/hook", func() http.Handler {
+			prefix := config.Cfg().GetString("svc.hookprefix")
+			return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { io.WriteString(w, prefix) })
+		})
+		unsubscribe := config.Subscribe("svc.hookprefix", func(*config.Config, []string) { hooks.Reload() })
+		defer unsubscribe()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- r.Serve(ctx, addr) }()
+		So(waitFor("/first"), ShouldEqual, "/first")
+
+		write("svc: {hookprefix: /second}\n")
+		So(waitFor("/second"), ShouldEqual, "/second")
+
+		cancel()
+		So(<-done, ShouldBeNil)
+	})
+}
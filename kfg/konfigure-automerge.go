@@ -19,6 +19,7 @@ import (
 //   - 3. For each path, attempts to merge the configuration file using MergeKonfig
 //   - 4. Logs success or failure for each file using slog.Debug
 //   - 5. Missing files are silently ignored (merge is optional)
+//   - 6. Each merged file is recorded in Layers (see MergeKonfig & Explain)
 //
 // Returns an error only if the "kfg" section cannot be unmarshaled or if there's
 // a critical configuration error. File-not-found errors are ignored.
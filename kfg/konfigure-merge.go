@@ -29,6 +29,7 @@ import (
 //   - 3. Uses fs.Provider to read from the specified filesystem (defaults to OS filesystem)
 //   - 4. Uses yaml.Parser() to parse the YAML content
 //   - 5. Merges the parsed data into the existing global koanf instance (Kfg)
+//   - 6. Records the merged file in Layers after the files loaded before it
 //
 // Returns an error if Kfg is not initialized, file cannot be read, or parsing fails.
 // Returns nil if successful or if the file doesn't exist (merge is optional).
@@ -67,6 +68,7 @@ func MergeKonfig(opt ...*KonfigureOpt) error {
 	switch {
 	case err == nil:
 		slog.Debug("AutoMerge: konfig search", "found", true, "path", filePath)
+		addLayer(configFs, filePath)
 		return nil
 	case os.IsNotExist(err):
 		slog.Debug("AutoMerge: konfig search", "found", false, "path", filePath)
@@ -5,11 +5,13 @@
 package kfg
 
 import (
+	iofs "io/fs"
 	"log/slog"
 
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/fs"
 	"github.com/knadh/koanf/v2"
+	"github.com/mrmxf/clog-mrmxf/config"
 )
 
 // Raw is the global koanf instance used for configuration management. koanf is
@@ -19,6 +21,34 @@ import (
 // all loaded configuration data.
 var Raw *koanf.Koanf
 
+// Layers are the configs loaded into Raw in merge order so that a value can
+// be explained in the same way as `clog Config explain` (see Explain).
+var Layers []config.Layer
+
+// Explain returns every loaded config that defines a key in merge order and
+// marks the definition that won.
+func Explain(key string) []config.Definition {
+	return config.ExplainLayers(Layers, key)
+}
+
+// addLayer records a config that has been loaded into Raw. The embedded
+// config is recorded as embedded:<path> like the clog config layers.
+func addLayer(configFs iofs.FS, filePath string) {
+	source := filePath
+	if configFs == Efs {
+		source = "embedded:" + filePath
+	}
+	body, err := iofs.ReadFile(configFs, filePath)
+	if err != nil {
+		slog.Debug("kfg layer has no provenance", "path", filePath, "err", err)
+	}
+	layer, err := config.NewLayer(source, body)
+	if err != nil {
+		slog.Debug("kfg layer has no provenance", "err", err)
+	}
+	Layers = append(Layers, layer)
+}
+
 // Konfigure (re)loads the configuration from a filesystem into the global koanf instance.
 // This function must be called before using Unmarshal to ensure configuration data is available.
 //
@@ -34,6 +64,7 @@ var Raw *koanf.Koanf
 //   - 3. Uses fs.Provider to read from the specified filesystem
 //   - 4. Uses yaml.Parser() to parse the YAML content into a structured format
 //   - 5. Loads the parsed data into the global koanf instance (Kfg)
+//   - 6. Records the file in Layers so that Explain can show where a value came from
 //
 // Returns an error if the file cannot be read or parsed, nil if successful.
 func Konfigure(opt ...*KonfigureOpt) error {
@@ -58,6 +89,7 @@ func Konfigure(opt ...*KonfigureOpt) error {
 
 	// Initialize the global koanf instance
 	Raw = koanf.New(".")
+	Layers = nil
 
 	// Load configuration if AutoLoad is true
 	if options.PreventAutoLoad {
@@ -70,6 +102,7 @@ func Konfigure(opt ...*KonfigureOpt) error {
 		slog.Debug("kfg.Konfigure failed to load embedded "+filePath, "err", err)
 		return err
 	}
+	addLayer(configFs, filePath)
 
 	// If AutoMerge is enabled, automatically merge additional configuration files
 	if !options.PreventAutoMerge {
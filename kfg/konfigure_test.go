@@ -6,6 +6,7 @@ package kfg
 
 import (
 	"testing"
+	"testing/fstest"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -238,3 +239,29 @@ func TestKonfigureWithAutoMerge(t *testing.T) {
 		})
 	})
 }
+
+func TestExplain(t *testing.T) {
+	Convey("the loaded & merged configs should be recorded as layers", t, func() {
+		err := Konfigure(&KonfigureOpt{
+			AppFs:               testEfs,
+			FilePath:            "konfigure-autoapp_test.yaml",
+			PreventAutoMerge:    true,
+			PreventAutoApp:      true,
+			PreventAutoReleases: true,
+		})
+		So(err, ShouldBeNil)
+		So(Layers, ShouldHaveLength, 1)
+
+		overlay := fstest.MapFS{".konfig.yaml": {Data: []byte("test-app:\n  name: Overlay\n")}}
+		So(MergeKonfig(&KonfigureOpt{AppFs: overlay}), ShouldBeNil)
+		So(MergeKonfig(&KonfigureOpt{AppFs: overlay, FilePath: "missing.yaml"}), ShouldBeNil)
+		So(Layers, ShouldHaveLength, 2)
+
+		definitions := Explain("test-app.name")
+		So(definitions, ShouldHaveLength, 2)
+		So(definitions[0].Source, ShouldEqual, "konfigure-autoapp_test.yaml")
+		So(definitions[1].Source, ShouldEqual, ".konfig.yaml")
+		So(definitions[1].Winner, ShouldBeTrue)
+		So(Raw.String("test-app.name"), ShouldEqual, "Overlay")
+	})
+}
@@ -6,6 +6,7 @@
 package scripts
 
 import (
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
@@ -20,6 +21,12 @@ const ERR_ESTRPIPE = 86
 
 // execute a command and restream Stdin & StdOut - return status
 func Exec(command string, args []string, env map[string]string) (int, error) {
+	return ExecTee(command, args, env, os.Stdout, os.Stderr)
+}
+
+// execute a command and restream its stdout & stderr to the given writers
+// (e.g. an io.MultiWriter of the console and a log file) - return status
+func ExecTee(command string, args []string, env map[string]string, stdout io.Writer, stderr io.Writer) (int, error) {
 	exe := exec.Command(command, args...)
 
 	// add in any env variables
@@ -50,23 +57,16 @@ func Exec(command string, args []string, env map[string]string) (int, error) {
 	// wg ensures that we finish
 	var wg sync.WaitGroup
 	var exitCode = 0
-	wg.Add(1)
+	wg.Add(2)
 	go func() {
-		// defer stdin.Close()
-		// io.WriteString(stdin, "values written to stdin are passed to cmd's standard input")
-
-		// stdout, errStdout = copyAndCapture(os.Stdout, stdoutIn)
-		_, errStdout = rewriteStdout(os.Stdout, execStdOut)
-		_, errStderr = rewriteStdout(os.Stderr, execStdErr)
+		_, errStdout = rewriteStdout(stdout, execStdOut)
+		wg.Done()
+	}()
+	go func() {
+		_, errStderr = rewriteStdout(stderr, execStdErr)
 		wg.Done()
 	}()
 
-	// stderr, errStderr = copyAndCapture(os.Stderr, stderrIn)
-	_, err = rewriteStdout(os.Stderr, execStdErr)
-	if err != nil {
-		slog.Error("Failed to rewrite script os.Stderr " + err.Error())
-		return ERR_ESTRPIPE, err
-	}
 	// wait for all the standard output to be rewritten
 	wg.Wait()
 	//wait for the process to exit
@@ -7,11 +7,11 @@ package scripts
 
 import (
 	"log/slog"
-	"os"
 	"path/filepath"
 	"runtime"
 
 	"github.com/mrmxf/clog/crayon"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +26,7 @@ func FindScripts(rootCmd *cobra.Command, folderGlob string) {
 	//if there is an error, log it and exit
 	if err != nil {
 		slog.Error("unable to find scripts "+folderGlob, "err", err)
-		os.Exit(1)
+		slogger.Exit(1)
 	}
 
 	//add each script found
@@ -7,11 +7,12 @@ package scripts
 
 import (
 	"log/slog"
-	"os"
 	"os/exec"
 	"strings"
 
 	"runtime"
+
+	"github.com/mrmxf/clog/slogger"
 )
 
 // GitCmd represents the base core
@@ -26,7 +27,7 @@ func GetShellPath() string {
 	}
 	if len(whichShell) < 3 {
 		slog.Error("Unable to find a compatible shell to run, exiting")
-		os.Exit(1)
+		slogger.Exit(1)
 	}
 	shellPath := strings.TrimSpace(string(whichShell))
 	slog.Debug("Using shell: " + shellPath)
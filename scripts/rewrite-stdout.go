@@ -9,30 +9,35 @@ import (
 	"io"
 	"runtime"
 
+	"github.com/mrmxf/clog/slogger"
 	"golang.org/x/exp/slog"
 )
 
 // execute the script and display progress
 // from https://blog.kowalczyk.info/article/wOYk/advanced-command-execution-in-go-with-osexec.html
+//
+// Secrets are masked (see slogger.RedactWriter) even when they are split
+// across reads.
 func rewriteStdout(w io.Writer, r io.Reader) ([]byte, error) {
 	var out []byte
+	rw := slogger.NewRedactWriter(w)
 	buf := make([]byte, 1024)
 	for {
 		n, err := r.Read(buf[:])
 		if n > 0 {
 			d := buf[:n]
 			out = append(out, d...)
-			_, err := w.Write(d)
+			_, err := rw.Write(d)
 			if err != nil {
-				return out, err
+				return []byte(slogger.Redact(string(out))), err
 			}
 		}
 		if err != nil {
 			// Read returns io.EOF at the end of file, which is not an error for us
 			if err == io.EOF {
-				err = nil
+				err = rw.Flush()
 			}
-			return out, err
+			return []byte(slogger.Redact(string(out))), err
 		}
 	}
 }
@@ -8,9 +8,9 @@ package scripts
 import (
 	"fmt"
 	"log/slog"
-	"os"
 	"runtime"
 
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -72,9 +72,9 @@ func AddScript(cmd *cobra.Command, filePath string) error {
 		exitCode, err := Exec("bash", shell, nil)
 		if err != nil {
 			slog.Debug("Failed to execute script", "err", err.Error())
-			os.Exit(1)
+			slogger.Exit(1)
 		}
-		os.Exit(exitCode)
+		slogger.Exit(exitCode)
 		// exe := exec.Command("bash", shell...)
 
 		// // var stdout, stderr []byte
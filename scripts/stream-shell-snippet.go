@@ -7,11 +7,14 @@ package scripts
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+
+	"github.com/mrmxf/clog/slogger"
 )
 
 // Execute a shell snippet and get the result, return code and sys error
@@ -24,6 +27,8 @@ func CaptureShellSnippet(snippet string, env map[string]string) (string, int, er
 
 	cmd := exec.Command(shell, "-c", snippet)
 	cmd.Env = os.Environ()
+	// captured output is not a terminal so a nested clog must not redraw
+	cmd.Env = append(cmd.Env, slogger.TTYEnv+"=0")
 	// append environemnt variables from the passed map
 	for k, v := range env {
 		cmd.Env = append(cmd.Env, k+"="+v)
@@ -61,6 +66,22 @@ func AwaitShellSnippet(snippet string, env map[string]string, cliArgs []string)
 	return exitStatus, err
 }
 
+// Execute a shell snippet and stream stdout & stderr to the given writers
+// e.g. the console and a log file. Returns the exit status.
+func AwaitShellSnippetTee(snippet string, env map[string]string, cliArgs []string, stdout io.Writer, stderr io.Writer) (int, error) {
+	shell := GetShellPath()
+
+	slog.Debug("Streaming shell snippet (tee): ", "shell", shell, "command", snippet)
+
+	//append a dummy executable and the arguments so that $1 in the script works.
+	args := append([]string{"-c", snippet, "clog(snippet)"}, cliArgs...)
+	exitStatus, err := ExecTee(shell, args, env, stdout, stderr)
+
+	slog.Debug("Status of shell snippet: " + fmt.Sprintf("%v", exitStatus))
+
+	return exitStatus, err
+}
+
 func init() {
 	// log the order of the init files in case there are problems
 	_, file, _, _ := runtime.Caller(0)
@@ -0,0 +1,112 @@
+//  Copyright ©2017-2025  Mr MXF   info@mrmxf.com
+//  BSD-3-Clause License           https://opensource.org/license/bsd-3-clause/
+//
+// compare versions against simple constraints e.g. ">=1.22, <2"
+
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// find the first version-like string in some text e.g. `go version go1.22.3`
+var reVersion = regexp.MustCompile(`[0-9]+(\.[0-9]+){0,2}`)
+
+// FindVersion returns the first version-like string (e.g. 1.22.3) in text or
+// an empty string if there is none
+func FindVersion(text string) string {
+	return reVersion.FindString(text)
+}
+
+// Compare returns -1, 0 or +1 when version a is less than, equal to or greater
+// than version b. Missing minor and patch values are treated as 0 and any
+// leading "v" and trailing pre-release suffix are ignored.
+func Compare(a string, b string) (int, error) {
+	va, err := parseNumbers(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseNumbers(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1, nil
+		case va[i] > vb[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// Satisfies returns true if version meets every comma separated clause of the
+// constraint. Supported operators are = == != > >= < <= and a bare version
+// which means equal.
+//
+//	ok, err := semver.Satisfies("1.22.3", ">=1.22, <2")
+func Satisfies(version string, constraint string) (bool, error) {
+	if len(strings.TrimSpace(constraint)) == 0 {
+		return true, nil
+	}
+	for _, clause := range strings.Split(constraint, ",") {
+		clause = strings.TrimSpace(clause)
+		op := strings.TrimRight(clause, "v0123456789.-+abcdefghijklmnopqrstuvwxyz")
+		ref := strings.TrimSpace(clause[len(op):])
+		cmp, err := Compare(version, ref)
+		if err != nil {
+			return false, err
+		}
+		ok := false
+		switch strings.TrimSpace(op) {
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		default:
+			return false, fmt.Errorf("unknown operator (%s) in version constraint (%s)", op, constraint)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parse major.minor.patch into 3 integers
+func parseNumbers(version string) ([3]int, error) {
+	nums := [3]int{}
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	// discard any pre-release or build suffix
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if len(v) == 0 {
+		return nums, errors.New("empty version string")
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > 3 {
+		return nums, fmt.Errorf("version (%s) has too many parts", version)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nums, fmt.Errorf("version (%s) is not numeric", version)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
@@ -0,0 +1,55 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/mrmxf/clog/semver"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConstraint(t *testing.T) {
+
+	Convey("FindVersion should extract versions from tool output", t, func() {
+		So(semver.FindVersion("go version go1.22.3 linux/amd64"), ShouldEqual, "1.22.3")
+		So(semver.FindVersion("aws-cli/2.15.1 Python/3.11.6"), ShouldEqual, "2.15.1")
+		So(semver.FindVersion("no version here"), ShouldEqual, "")
+	})
+
+	Convey("Compare should order versions numerically", t, func() {
+		cmp, err := semver.Compare("1.22.3", "1.9")
+		So(err, ShouldBeNil)
+		So(cmp, ShouldEqual, 1)
+		cmp, _ = semver.Compare("v1.2", "1.2.0")
+		So(cmp, ShouldEqual, 0)
+		cmp, _ = semver.Compare("1.2.0-rc1", "1.10.0")
+		So(cmp, ShouldEqual, -1)
+		_, err = semver.Compare("one.two", "1.2")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Satisfies should apply every clause of a constraint", t, func() {
+		tests := []struct {
+			version    string
+			constraint string
+			ok         bool
+		}{
+			{"1.22.3", ">=1.22", true},
+			{"1.21.9", ">=1.22", false},
+			{"1.22.3", ">=1.22, <2", true},
+			{"2.0.0", ">=1.22, <2", false},
+			{"1.22.3", "1.22.3", true},
+			{"1.22.3", "!= 1.22.3", false},
+			{"1.22.3", "", true},
+		}
+		for _, tt := range tests {
+			ok, err := semver.Satisfies(tt.version, tt.constraint)
+			So(err, ShouldBeNil)
+			So(ok, ShouldEqual, tt.ok)
+		}
+		_, err := semver.Satisfies("1.2.3", "~>1.2")
+		So(err, ShouldNotBeNil)
+	})
+}
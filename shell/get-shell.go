@@ -7,11 +7,12 @@ package shell
 
 import (
 	"log/slog"
-	"os"
 	"os/exec"
 	"strings"
 
 	"runtime"
+
+	"github.com/mrmxf/clog/slogger"
 )
 
 // GitCmd represents the base core
@@ -25,7 +26,7 @@ func GetShellPath() string {
 	}
 	if len(whichShell) < 3 {
 		slog.Error("Unable to find a compatible shell to run, exiting")
-		os.Exit(1)
+		slogger.Exit(1)
 	}
 	shellPath := strings.TrimSpace(string(whichShell))
 	slog.Debug("Using shell: " + shellPath)
@@ -6,11 +6,16 @@
 package shell
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+
+	"github.com/mrmxf/clog/slogger"
 )
 
 // Execute a shell snippet and get the result, return code and sys error
@@ -22,6 +27,8 @@ func CaptureShellSnippet(snippet string, env map[string]string) (string, int, er
 
 	cmd := exec.Command(shell, "-c", snippet)
 	cmd.Env = os.Environ()
+	// captured output is not a terminal so a nested clog must not redraw
+	cmd.Env = append(cmd.Env, slogger.TTYEnv+"=0")
 	// append environment variables from the passed map
 	for k, v := range env {
 		cmd.Env = append(cmd.Env, k+"="+v)
@@ -41,6 +48,86 @@ func CaptureShellSnippet(snippet string, env map[string]string) (string, int, er
 	return result, exitStatus, nil
 }
 
+// Execute a shell snippet and get the combined result, return code and sys
+// error as [CaptureShellSnippet]. A copy of stdout and stderr is also sent
+// to the stdout and stderr writers (if not nil) as the snippet runs e.g. to
+// save them separately to a log or artifact file. Secrets are masked in the
+// copies and in the result (see slogger.Redact).
+//
+// Without writers stdout & stderr share one pipe and keep their order in the
+// result. With writers they are read by separate goroutines, so lines written
+// to stdout and stderr close together may be swapped in the combined result.
+func CaptureShellSnippetTee(snippet string, env map[string]string, stdout io.Writer, stderr io.Writer) (string, int, error) {
+	shell := GetShellPath()
+
+	slog.Debug("Capturing shell snippet (tee): ", "shell", shell, "command", snippet)
+
+	cmd := exec.Command(shell, "-c", snippet)
+	cmd.Env = os.Environ()
+	// captured output is not a terminal so a nested clog must not redraw
+	cmd.Env = append(cmd.Env, slogger.TTYEnv+"=0")
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	// stdout & stderr are copied in different goroutines so the combined
+	// buffer must be locked. teeWriter returns the same buffer for both when
+	// there are no writers so exec uses one pipe and the order is kept.
+	combined := &lockedBuffer{}
+	redactOut, redactErr := redactWriter(stdout), redactWriter(stderr)
+	cmd.Stdout = teeWriter(combined, redactOut)
+	cmd.Stderr = teeWriter(combined, redactErr)
+	err := cmd.Run()
+	exitStatus := cmd.ProcessState.ExitCode()
+	for _, w := range []*slogger.RedactWriter{redactOut, redactErr} {
+		if w != nil {
+			w.Flush()
+		}
+	}
+
+	output := slogger.Redact(combined.String())
+	result := strings.TrimSpace(output)
+	slog.Debug("Result of shell snippet: ", "StdOut+StdErr", result, "$?", exitStatus)
+
+	if err != nil {
+		return output, exitStatus, err
+	}
+	return result, exitStatus, nil
+}
+
+// wrap a writer (if not nil) to mask secrets
+func redactWriter(w io.Writer) *slogger.RedactWriter {
+	if w == nil {
+		return nil
+	}
+	return slogger.NewRedactWriter(w)
+}
+
+// a bytes.Buffer that can be written from several goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// write to the buffer and optionally to another writer
+func teeWriter(buf io.Writer, w *slogger.RedactWriter) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, w)
+}
+
 // Execute a shell snippet and stream the result, stdError & return status
 func StreamShellSnippet(snippet string, env map[string]string) *exec.Cmd {
 	// figure out what shell we will run and log it for debugging
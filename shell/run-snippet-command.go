@@ -7,18 +7,19 @@ package shell
 
 import (
 	"fmt"
-	"os"
+
+	"github.com/mrmxf/clog/slogger"
 )
 
-// Execute a shell snippet, print & return result
-// On error (exitStatus>0), and os.Exit(exitStatus)
+// Execute a shell snippet, print (with secrets masked) & return result
+// On error (exitStatus>0), and slogger.Exit(exitStatus)
 func ShellSnippet(snippet string) string {
 	result, exitStatus, err := CaptureShellSnippet(snippet, nil)
 
-	fmt.Print(result)
+	fmt.Print(slogger.Redact(result))
 
 	if err != nil || exitStatus > 0 {
-		os.Exit(exitStatus)
+		slogger.Exit(exitStatus)
 	}
 	return result
 }
@@ -0,0 +1,172 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_media
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/longkai/rfc7807"
+)
+
+// attribute keys used by the job events. A job handler looks for these keys
+// to turn a log record into an ST 2126 job record.
+const (
+	EventKey    = "event"    // LogLevelName of the event e.g. JOB_START
+	JobKey      = "job"      // *JobInfo
+	FunctionKey = "function" // name of the function for FUNCTION_START & FUNCTION_END
+	StartedKey  = "started"  // time.Time that a function started
+)
+
+// the job event names
+const (
+	EventJobStart      LogLevelName = "JOB_START"
+	EventJobUpdate     LogLevelName = "JOB_UPDATE"
+	EventJobEnd        LogLevelName = "JOB_END"
+	EventFunctionStart LogLevelName = "FUNCTION_START"
+	EventFunctionEnd   LogLevelName = "FUNCTION_END"
+)
+
+// slog levels used for the events. Job & function events are logged at Info
+// so that they are seen at the default level. The ST 2126 level code of a
+// function event (450) comes from its event name, not from its slog level.
+const (
+	LevelJob      = slog.LevelInfo
+	LevelFunction = slog.LevelInfo
+)
+
+// ISO 8601 layout used for the job dates
+const DateFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// JobRecordInfo is the externally visible ST 2126 view of a JobInfo
+type JobRecordInfo struct {
+	Id              URL           `json:"id"`
+	Type            string        `json:"type,omitempty"`
+	Profile         URL           `json:"profile,omitempty"`
+	ProfileName     string        `json:"profileName,omitempty"`
+	Execution       URL           `json:"execution,omitempty"`
+	Assignment      URL           `json:"assignment,omitempty"`
+	Input           string        `json:"input,omitempty"`
+	Status          JobStatusEnum `json:"status"`
+	Error           ErrorInfo     `json:"error,omitempty"`
+	ActualStartDate string        `json:"actualStartDate,omitempty"`
+	ActualEndDate   string        `json:"actualEndDate,omitempty"`
+	ActualDuration  int           `json:"actualDuration,omitempty"`
+	Output          *OutputInfo   `json:"output,omitempty"`
+}
+
+// Record returns the ST 2126 properties of the job
+func (j *JobInfo) Record() JobRecordInfo {
+	r := JobRecordInfo{
+		Id:              j.Id,
+		Type:            j.Type,
+		Profile:         j.Profile,
+		ProfileName:     j.ProfileName,
+		Execution:       j.Execution,
+		Assignment:      j.Assignment,
+		Input:           j.Input,
+		Status:          j.Status,
+		Error:           j.Error,
+		ActualStartDate: j.ActualStartDate,
+		ActualEndDate:   j.ActualEndDate,
+		ActualDuration:  j.ActualDuration,
+	}
+	if len(r.Id) == 0 && j.XjobId > 0 {
+		r.Id = URL(j.IdString())
+	}
+	if len(j.Output.LogLocation) > 0 {
+		r.Output = &OutputInfo{LogLocation: j.Output.LogLocation}
+	}
+	return r
+}
+
+// LogValue implements slog.LogValuer so that handlers that do not understand
+// jobs (pretty, plain, JSON) still show the important properties.
+func (j *JobInfo) LogValue() slog.Value {
+	r := j.Record()
+	attrs := []slog.Attr{
+		slog.String("id", string(r.Id)),
+		slog.String("status", string(r.Status)),
+	}
+	if r.ActualDuration > 0 {
+		attrs = append(attrs, slog.Int("duration", r.ActualDuration))
+	}
+	if r.Error != nil {
+		attrs = append(attrs, slog.String("error", r.Error.Detail))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Start marks the job as RUNNING and logs JOB_START
+func (j *JobInfo) Start(l *slog.Logger, msg string, args ...any) {
+	if len(j.ActualStartDate) == 0 {
+		j.ActualStartDate = time.Now().Format(DateFormat)
+	}
+	j.Status = RUNNING
+	j.log(l, LevelJob, EventJobStart, msg, args...)
+}
+
+// Update changes the status of the job (if not empty) and logs JOB_UPDATE
+func (j *JobInfo) Update(l *slog.Logger, status JobStatusEnum, msg string, args ...any) {
+	if len(status) > 0 {
+		j.Status = status
+	}
+	j.log(l, LevelJob, EventJobUpdate, msg, args...)
+}
+
+// End logs JOB_END. The status is FAILED if the job has an Error, otherwise it
+// is COMPLETED unless the job was already CANCELLED or FAILED. The duration is
+// calculated from ActualStartDate.
+func (j *JobInfo) End(l *slog.Logger, msg string, args ...any) {
+	now := time.Now()
+	j.ActualEndDate = now.Format(DateFormat)
+	if start, err := time.Parse(DateFormat, j.ActualStartDate); err == nil {
+		j.ActualDuration = int(now.Sub(start).Milliseconds())
+	} else if start, err := time.Parse(time.RFC3339, j.ActualStartDate); err == nil {
+		j.ActualDuration = int(now.Sub(start).Milliseconds())
+	}
+	switch {
+	case j.Error != nil:
+		j.Status = FAILED
+	case j.Status != CANCELLED && j.Status != FAILED:
+		j.Status = COMPLETED
+	}
+	level := LevelJob
+	if j.Status == FAILED {
+		level = slog.LevelError
+	}
+	j.log(l, level, EventJobEnd, msg, args...)
+}
+
+// Fail sets an RFC 7807 error on the job from err and ends the job
+func (j *JobInfo) Fail(l *slog.Logger, err error, msg string, args ...any) {
+	j.Error = NewErrorInfo(err.Error(), 500)
+	j.End(l, msg, args...)
+}
+
+// FunctionStart logs FUNCTION_START and returns the start time that should be
+// passed to FunctionEnd
+func (j *JobInfo) FunctionStart(l *slog.Logger, name string) time.Time {
+	j.log(l, LevelFunction, EventFunctionStart, name, FunctionKey, name)
+	return time.Now()
+}
+
+// FunctionEnd logs FUNCTION_END with the duration since started
+func (j *JobInfo) FunctionEnd(l *slog.Logger, name string, started time.Time) {
+	j.log(l, LevelFunction, EventFunctionEnd, name, FunctionKey, name, StartedKey, started)
+}
+
+func (j *JobInfo) log(l *slog.Logger, level slog.Level, event LogLevelName, msg string, args ...any) {
+	if l == nil {
+		l = slog.Default()
+	}
+	args = append([]any{EventKey, string(event), JobKey, j}, args...)
+	l.Log(context.Background(), level, msg, args...)
+}
+
+// NewErrorInfo creates an RFC 7807 problem detail for a failed job
+func NewErrorInfo(detail string, status int) ErrorInfo {
+	return rfc7807.Customize("st2126", "job.failed", "job failed", detail, status, nil, nil)
+}
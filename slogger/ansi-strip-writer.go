@@ -0,0 +1,93 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"io"
+	"sync"
+)
+
+// AnsiStripWriter removes ANSI escape sequences (colors, cursor movement) from
+// a stream before writing it to the underlying writer. It keeps state between
+// calls to Write so that an escape sequence split across two writes is still
+// removed.
+type AnsiStripWriter struct {
+	out   io.Writer
+	mu    sync.Mutex
+	state int
+}
+
+const (
+	ansiText   = iota // normal text
+	ansiEsc           // seen ESC
+	ansiCsi           // inside ESC [ ... final byte
+	ansiOsc           // inside ESC ] ... BEL or ESC \
+	ansiOscEsc        // seen ESC inside an OSC sequence
+)
+
+// NewAnsiStripWriter returns a writer that strips ANSI escapes before writing
+// to out.
+func NewAnsiStripWriter(out io.Writer) *AnsiStripWriter {
+	return &AnsiStripWriter{out: out}
+}
+
+// StripANSI returns s without any ANSI escape sequences
+func StripANSI(s string) string {
+	w := AnsiStripWriter{}
+	return string(w.strip([]byte(s)))
+}
+
+// Write implements io.Writer. The returned count is len(p) on success so that
+// callers (e.g. io.Copy) do not treat the removed bytes as a short write.
+func (w *AnsiStripWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	clean := w.strip(p)
+	if len(clean) == 0 {
+		return len(p), nil
+	}
+	if _, err := w.out.Write(clean); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// strip runs the escape sequence state machine over p
+func (w *AnsiStripWriter) strip(p []byte) []byte {
+	clean := make([]byte, 0, len(p))
+	for _, c := range p {
+		switch w.state {
+		case ansiText:
+			if c == 0x1b {
+				w.state = ansiEsc
+			} else {
+				clean = append(clean, c)
+			}
+		case ansiEsc:
+			switch c {
+			case '[':
+				w.state = ansiCsi
+			case ']':
+				w.state = ansiOsc
+			default:
+				// two byte sequence e.g. ESC 7
+				w.state = ansiText
+			}
+		case ansiCsi:
+			// parameters & intermediates are 0x20-0x3f, final byte is 0x40-0x7e
+			if c >= 0x40 && c <= 0x7e {
+				w.state = ansiText
+			}
+		case ansiOsc:
+			if c == 0x07 {
+				w.state = ansiText
+			} else if c == 0x1b {
+				w.state = ansiOscEsc
+			}
+		case ansiOscEsc:
+			w.state = ansiText
+		}
+	}
+	return clean
+}
@@ -0,0 +1,33 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAnsiStrip(t *testing.T) {
+
+	Convey("ANSI escapes should be stripped", t, func() {
+		So(slogger.StripANSI("\x1b[31mred\x1b[0m text"), ShouldEqual, "red text")
+		So(slogger.StripANSI("\x1b[A\x1b[G\x1b[Kup"), ShouldEqual, "up")
+		So(slogger.StripANSI("\x1b]0;title\x07plain"), ShouldEqual, "plain")
+		So(slogger.StripANSI("no escapes"), ShouldEqual, "no escapes")
+	})
+
+	Convey("escapes split across writes should be stripped", t, func() {
+		buf := bytes.NewBuffer(nil)
+		w := slogger.NewAnsiStripWriter(buf)
+		for _, chunk := range []string{"a\x1b", "[1;3", "1mb\x1b[", "0m", "c"} {
+			n, err := w.Write([]byte(chunk))
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, len(chunk))
+		}
+		So(buf.String(), ShouldEqual, "abc")
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// a key=value argument. Keys start with a letter or underscore.
+var reAttrArg = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.-]*)=(.*)$`)
+
+// ParseAttrArgs splits command line args into the message words and the
+// trailing key=value pairs e.g.
+//
+//	deployed to prod env=prod version=1.2.3 duration=3.2s
+//
+// The first arg is always part of the message so that a message containing
+// an = is never lost. Values are typed by TypedValue.
+func ParseAttrArgs(args []string) ([]string, []slog.Attr) {
+	i := len(args)
+	for i > 1 && reAttrArg.MatchString(args[i-1]) {
+		i--
+	}
+	attrs := []slog.Attr{}
+	for _, arg := range args[i:] {
+		kv := reAttrArg.FindStringSubmatch(arg)
+		attrs = append(attrs, slog.Attr{Key: kv[1], Value: TypedValue(kv[2])})
+	}
+	return args[:i], attrs
+}
+
+// TypedValue converts a string into an int, float, bool, duration or time
+// value if it looks like one, otherwise it stays a string.
+func TypedValue(s string) slog.Value {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return slog.Int64Value(i)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !strings.ContainsAny(s, "xXnN") {
+		return slog.Float64Value(f)
+	}
+	switch s {
+	case "true":
+		return slog.BoolValue(true)
+	case "false":
+		return slog.BoolValue(false)
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return slog.DurationValue(d)
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return slog.TimeValue(t)
+	}
+	return slog.StringValue(s)
+}
+
+// ParseAttrsJSON converts a JSON object into attributes. Nested objects
+// become groups. Keys are sorted so that the output is predictable.
+func ParseAttrsJSON(body string) ([]slog.Attr, error) {
+	dec := json.NewDecoder(bytes.NewBufferString(body))
+	dec.UseNumber()
+	obj := map[string]any{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, errors.New("attrs json must be an object: " + err.Error())
+	}
+	return jsonAttrs(obj), nil
+}
+
+func jsonAttrs(obj map[string]any) []slog.Attr {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := []slog.Attr{}
+	for _, k := range keys {
+		switch v := obj[k].(type) {
+		case map[string]any:
+			attrs = append(attrs, slog.Attr{Key: k, Value: slog.GroupValue(jsonAttrs(v)...)})
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				attrs = append(attrs, slog.Int64(k, i))
+			} else if f, err := v.Float64(); err == nil {
+				attrs = append(attrs, slog.Float64(k, f))
+			} else {
+				attrs = append(attrs, slog.String(k, v.String()))
+			}
+		default:
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+	return attrs
+}
+
+// AttrArgs converts attributes into logger args. If group is not empty the
+// attributes are nested in a group of that name.
+func AttrArgs(group string, attrs []slog.Attr) []any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	if len(group) > 0 {
+		return []any{slog.Attr{Key: group, Value: slog.GroupValue(attrs...)}}
+	}
+	args := make([]any, 0, len(attrs))
+	for _, a := range attrs {
+		args = append(args, a)
+	}
+	return args
+}
@@ -0,0 +1,52 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAttrs(t *testing.T) {
+
+	Convey("trailing key=value args should become typed attributes", t, func() {
+		words, attrs := slogger.ParseAttrArgs([]string{"deployed", "to", "prod", "env=prod", "replicas=3", "load=0.5", "ok=true", "took=3.2s", "version=1.2.3"})
+		So(words, ShouldResemble, []string{"deployed", "to", "prod"})
+		So(attrs, ShouldHaveLength, 6)
+		So(attrs[0].Value.String(), ShouldEqual, "prod")
+		So(attrs[1].Value.Kind(), ShouldEqual, slog.KindInt64)
+		So(attrs[2].Value.Kind(), ShouldEqual, slog.KindFloat64)
+		So(attrs[3].Value.Kind(), ShouldEqual, slog.KindBool)
+		So(attrs[4].Value.Duration(), ShouldEqual, 3200*time.Millisecond)
+		So(attrs[5].Value.Kind(), ShouldEqual, slog.KindString)
+	})
+
+	Convey("the first arg and non trailing pairs stay in the message", t, func() {
+		words, attrs := slogger.ParseAttrArgs([]string{"a=b"})
+		So(words, ShouldResemble, []string{"a=b"})
+		So(attrs, ShouldBeEmpty)
+		words, attrs = slogger.ParseAttrArgs([]string{"set", "x=1", "now", "y=2"})
+		So(words, ShouldResemble, []string{"set", "x=1", "now"})
+		So(attrs, ShouldHaveLength, 1)
+	})
+
+	Convey("attrs json should nest objects as groups", t, func() {
+		attrs, err := slogger.ParseAttrsJSON(`{"n":3,"env":"prod","tags":{"canary":true}}`)
+		So(err, ShouldBeNil)
+		So(attrs, ShouldHaveLength, 3)
+		So(attrs[0].Key, ShouldEqual, "env")
+		So(attrs[1].Value.Int64(), ShouldEqual, 3)
+		So(attrs[2].Value.Kind(), ShouldEqual, slog.KindGroup)
+		_, err = slogger.ParseAttrsJSON(`[1,2]`)
+		So(err, ShouldNotBeNil)
+
+		args := slogger.AttrArgs("deploy", attrs)
+		So(args, ShouldHaveLength, 1)
+		So(args[0].(slog.Attr).Key, ShouldEqual, "deploy")
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// CI is the continuous integration system that clog is running in
+type CI int
+
+const (
+	CINone CI = iota
+	CIGitHub
+	CIGitLab
+)
+
+// add a string function to Sprintf("%s") our new type
+func (c CI) String() string {
+	switch c {
+	case CIGitHub:
+		return "github"
+	case CIGitLab:
+		return "gitlab"
+	}
+	return "none"
+}
+
+// DetectCI uses the environment variables set by the CI runners
+func DetectCI() CI {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return CIGitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		return CIGitLab
+	}
+	return CINone
+}
+
+// Section is a collapsible part of the log. In GitHub Actions it is a
+// ::group::, in GitLab CI a section_start / section_end pair and locally a
+// header with indentation.
+type Section struct {
+	Name  string    `json:"name"`
+	Id    string    `json:"id"`
+	Start time.Time `json:"start"`
+	Depth int       `json:"depth"`
+}
+
+// characters that are not allowed in a GitLab section id
+var reSectionId = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// the indent for local sections
+const sectionIndent = "  "
+
+// NewSection creates a section that starts now. Depth is the number of
+// sections that are already open.
+func NewSection(name string, depth int) *Section {
+	id := strings.Trim(reSectionId.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if len(id) == 0 {
+		id = "section"
+	}
+	return &Section{
+		Name:  name,
+		Id:    fmt.Sprintf("%s_%d", id, depth),
+		Start: time.Now(),
+		Depth: depth,
+	}
+}
+
+// Indent is the prefix for local messages inside the section
+func (s *Section) Indent() string {
+	return strings.Repeat(sectionIndent, s.Depth+1)
+}
+
+// Begin writes the start marker of the section for the CI to w
+func (s *Section) Begin(w io.Writer, ci CI) {
+	switch ci {
+	case CIGitHub:
+		fmt.Fprintf(w, "::group::%s\n", s.Name)
+	case CIGitLab:
+		fmt.Fprintf(w, "\x1b[0Ksection_start:%d:%s[collapsed=true]\r\x1b[0K%s\n", s.Start.Unix(), s.Id, s.Name)
+	default:
+		fmt.Fprintf(w, "%s▼ %s\n", strings.Repeat(sectionIndent, s.Depth), s.Name)
+	}
+}
+
+// End logs the duration of the section then writes the end marker for the CI
+// to w. The duration is returned.
+func (s *Section) End(w io.Writer, ci CI) time.Duration {
+	d := time.Since(s.Start).Round(time.Millisecond)
+	msg := fmt.Sprintf("%s took %s", s.Name, d)
+	if ci == CINone {
+		msg = strings.Repeat(sectionIndent, s.Depth) + "▲ " + msg
+	}
+	Info(msg, "section", s.Name, "duration", d)
+	switch ci {
+	case CIGitHub:
+		fmt.Fprintln(w, "::endgroup::")
+	case CIGitLab:
+		fmt.Fprintf(w, "\x1b[0Ksection_end:%d:%s\r\x1b[0K\n", time.Now().Unix(), s.Id)
+	}
+	return d
+}
+
+// CIAnnotationHandler turns warn & error records into GitHub Actions workflow
+// commands (::warning / ::error) so that they show up as annotations. Every
+// record is still passed to the wrapped handler. The file & line come from
+// "file" & "line" attributes or from the source of the log call if it is
+// inside the current folder.
+type CIAnnotationHandler struct {
+	slog.Handler
+	out io.Writer
+}
+
+var _ slog.Handler = (*CIAnnotationHandler)(nil)
+
+// NewCIAnnotationHandler wraps h and writes annotations to out
+func NewCIAnnotationHandler(h slog.Handler, out io.Writer) *CIAnnotationHandler {
+	return &CIAnnotationHandler{Handler: h, out: out}
+}
+
+// UseCIAnnotations wraps the default logger with a CIAnnotationHandler when
+// running in GitHub Actions. GitLab has no log annotations so nothing changes
+// there or when running locally.
+func UseCIAnnotations() {
+	if DetectCI() != CIGitHub {
+		return
+	}
+	h := slog.Default().Handler()
+	if rh, ok := h.(*RedactHandler); ok {
+		h = rh.Unwrap()
+	}
+	if rh, ok := h.(*RunHandler); ok {
+		h = rh.Unwrap()
+	}
+	if _, done := h.(*CIAnnotationHandler); done {
+		return
+	}
+	setDefaultHandler(NewCIAnnotationHandler(h, os.Stdout))
+}
+
+// Enabled implements slog.Handler. Warnings are always annotated.
+func (h *CIAnnotationHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= LevelWarn || h.Handler.Enabled(ctx, l)
+}
+
+// Handle implements slog.Handler.
+func (h *CIAnnotationHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if rec.Level >= LevelWarn {
+		h.annotate(rec)
+	}
+	if !h.Handler.Enabled(ctx, rec.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h *CIAnnotationHandler) annotate(rec slog.Record) {
+	command := "warning"
+	if rec.Level >= LevelError {
+		command = "error"
+	}
+	file, line := "", ""
+	rec.Attrs(func(a slog.Attr) bool {
+		switch a.Key {
+		case "file":
+			file = a.Value.String()
+		case "line":
+			line = a.Value.String()
+		}
+		return true
+	})
+	if len(file) == 0 && rec.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		if rel, err := filepath.Rel(cwd, frame.File); err == nil && !strings.HasPrefix(rel, "..") {
+			file, line = rel, fmt.Sprint(frame.Line)
+		}
+	}
+	props := []string{}
+	if len(file) > 0 {
+		props = append(props, "file="+escapeProperty(file))
+		if len(line) > 0 {
+			props = append(props, "line="+escapeProperty(line))
+		}
+	}
+	params := ""
+	if len(props) > 0 {
+		params = " " + strings.Join(props, ",")
+	}
+	fmt.Fprintf(h.out, "::%s%s::%s\n", command, params, escapeData(StripANSI(rec.Message)))
+}
+
+// WithAttrs implements slog.Handler.
+func (h *CIAnnotationHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &CIAnnotationHandler{Handler: h.Handler.WithAttrs(attrs), out: h.out}
+}
+
+// WithGroup implements slog.Handler.
+func (h *CIAnnotationHandler) WithGroup(name string) slog.Handler {
+	return &CIAnnotationHandler{Handler: h.Handler.WithGroup(name), out: h.out}
+}
+
+// escaping rules for GitHub workflow commands
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
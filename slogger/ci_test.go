@@ -0,0 +1,57 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCI(t *testing.T) {
+
+	Convey("the CI should be detected from the environment", t, func() {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("GITLAB_CI", "true")
+		So(slogger.DetectCI(), ShouldEqual, slogger.CIGitLab)
+		t.Setenv("GITHUB_ACTIONS", "true")
+		So(slogger.DetectCI(), ShouldEqual, slogger.CIGitHub)
+	})
+
+	Convey("sections should print the markers for each CI", t, func() {
+		s := slogger.NewSection("Unit Tests!", 1)
+		So(s.Id, ShouldEqual, "unit_tests_1")
+		for ci, want := range map[slogger.CI][2]string{
+			slogger.CIGitHub: {"::group::Unit Tests!\n", "::endgroup::\n"},
+			slogger.CIGitLab: {"section_start:", "section_end:"},
+			slogger.CINone:   {"  ▼ Unit Tests!\n", ""},
+		} {
+			begin, end := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+			s.Begin(begin, ci)
+			s.End(end, ci)
+			So(begin.String(), ShouldContainSubstring, want[0])
+			So(end.String(), ShouldContainSubstring, want[1])
+		}
+	})
+
+	Convey("warnings & errors should become GitHub annotations", t, func() {
+		out, annotations := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+		inner := slogger.NewPrettyHandler(out, &slogger.PrettyHandlerOptions{Level: slogger.LevelError, NoColor: true})
+		logger := slog.New(slogger.NewCIAnnotationHandler(inner, annotations))
+		logger.Info("quiet")
+		logger.Warn("50% done, nearly", "file", "src/a,b.go", "line", 7)
+		logger.Error("line1\nline2")
+
+		// the error has no file attr so the source of the log call is used
+		So(annotations.String(), ShouldStartWith,
+			"::warning file=src/a%2Cb.go,line=7::50%25 done, nearly\n"+
+				"::error file=ci_test.go,line=")
+		So(annotations.String(), ShouldEndWith, "::line1%0Aline2\n")
+		So(out.String(), ShouldNotContainSubstring, "nearly")
+		So(out.String(), ShouldContainSubstring, "line1")
+	})
+}
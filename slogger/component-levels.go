@@ -0,0 +1,233 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ComponentKey is the attribute that names the component of a record. A
+// record without one belongs to the package of the log call.
+const ComponentKey = "component"
+
+// LevelSet is a base level with overrides for components e.g.
+//
+//	clog:
+//	  log:
+//	    level: info
+//	    levels: {gommi: warn, kfg: debug, scripts: trace}
+//
+// A component name matches a component attribute or a package path that
+// contains it as whole path elements (e.g. kfg or cmd/check). The longest
+// matching name wins. A LevelSet is safe to change while logging.
+type LevelSet struct {
+	mu         sync.RWMutex
+	base       slog.Level
+	components map[string]slog.Level
+}
+
+// the levels of the default logger
+var logLevels = NewLevelSet(defaultLogLevel)
+
+// LogLevels returns the levels of the default logger. Changes take effect
+// immediately.
+func LogLevels() *LevelSet {
+	return logLevels
+}
+
+// NewLevelSet creates a set without component levels
+func NewLevelSet(base slog.Level) *LevelSet {
+	return &LevelSet{base: base, components: map[string]slog.Level{}}
+}
+
+// Level implements slog.Leveler. It is the lowest level of the set so that
+// a handler using it passes every record that a component may want - wrap
+// the handler in a LevelsHandler to apply the component levels.
+func (s *LevelSet) Level() slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	lowest := s.base
+	for _, l := range s.components {
+		lowest = min(lowest, l)
+	}
+	return lowest
+}
+
+// Base returns the level of records without a component level
+func (s *LevelSet) Base() slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.base
+}
+
+// SetBase sets the level of records without a component level
+func (s *LevelSet) SetBase(level slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.base = level
+}
+
+// Set sets the level of a component
+func (s *LevelSet) Set(component string, level slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.components[component] = level
+}
+
+// Delete removes the level of a component
+func (s *LevelSet) Delete(component string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.components, component)
+}
+
+// SetComponents replaces all the component levels
+func (s *LevelSet) SetComponents(levels map[string]slog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.components = maps.Clone(levels)
+	if s.components == nil {
+		s.components = map[string]slog.Level{}
+	}
+}
+
+// Components returns a copy of the component levels
+func (s *LevelSet) Components() map[string]slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.components)
+}
+
+// For returns the level of a component attribute or package path
+func (s *LevelSet) For(component string) slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	level, found := s.match(component)
+	if !found {
+		return s.base
+	}
+	return level
+}
+
+// match finds the longest component name in path. s must be locked.
+func (s *LevelSet) match(path string) (slog.Level, bool) {
+	if len(path) == 0 {
+		return s.base, false
+	}
+	level, best := s.base, -1
+	elements := "/" + path + "/"
+	for name, l := range s.components {
+		if len(name) > best && strings.Contains(elements, "/"+name+"/") {
+			level, best = l, len(name)
+		}
+	}
+	return level, best >= 0
+}
+
+// forRecord returns the level for a record with an optional component
+// attribute and the program counter of the log call
+func (s *LevelSet) forRecord(component string, pc uintptr) slog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.components) == 0 {
+		return s.base
+	}
+	if level, found := s.match(component); found {
+		return level
+	}
+	if level, found := s.match(packageOf(pc)); found {
+		return level
+	}
+	return s.base
+}
+
+// packageOf returns the import path of the function at pc e.g.
+// github.com/mrmxf/clog/gommi
+func packageOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		return name[:slash+1+dot]
+	}
+	return name
+}
+
+// LevelsHandler drops the records that are below the level of their
+// component in a LevelSet. The handler that it wraps should use the set as
+// its level.
+type LevelsHandler struct {
+	next      slog.Handler
+	set       *LevelSet
+	component string // from WithAttrs
+	grouped   bool   // attributes are in a group so cannot be a component
+}
+
+var _ slog.Handler = (*LevelsHandler)(nil)
+
+// NewLevelsHandler wraps h with the levels of set
+func NewLevelsHandler(h slog.Handler, set *LevelSet) *LevelsHandler {
+	return &LevelsHandler{next: h, set: set}
+}
+
+// Unwrap returns the handler that records are passed to
+func (h *LevelsHandler) Unwrap() slog.Handler {
+	return h.next
+}
+
+// Enabled implements slog.Handler.
+func (h *LevelsHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.set.Level() && h.next.Enabled(ctx, l)
+}
+
+// Handle implements slog.Handler.
+func (h *LevelsHandler) Handle(ctx context.Context, rec slog.Record) error {
+	component := h.component
+	if !h.grouped {
+		rec.Attrs(func(a slog.Attr) bool {
+			if a.Key == ComponentKey {
+				component = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if rec.Level < h.set.forRecord(component, rec.PC) {
+		return nil
+	}
+	return h.next.Handle(ctx, rec)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *LevelsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == ComponentKey {
+				h2.component = a.Value.String()
+			}
+		}
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *LevelsHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.grouped = true
+	return &h2
+}
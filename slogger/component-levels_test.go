@@ -0,0 +1,106 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestComponentLevels(t *testing.T) {
+
+	Convey("the longest matching component should set the level", t, func() {
+		set := slogger.NewLevelSet(slogger.LevelInfo)
+		set.SetComponents(map[string]slog.Level{"gommi": slogger.LevelWarn, "clog": slogger.LevelDebug, "cmd/check": slogger.LevelTrace})
+		So(set.For("github.com/mrmxf/clog/gommi"), ShouldEqual, slogger.LevelWarn)
+		So(set.For("github.com/mrmxf/clog/cmd/check"), ShouldEqual, slogger.LevelTrace)
+		So(set.For("github.com/mrmxf/clog/scripts"), ShouldEqual, slogger.LevelDebug)
+		So(set.For("github.com/other/gommidb"), ShouldEqual, slogger.LevelInfo)
+		So(set.For("gommi"), ShouldEqual, slogger.LevelWarn)
+		So(set.Level(), ShouldEqual, slogger.LevelTrace)
+		set.Delete("cmd/check")
+		So(set.Level(), ShouldEqual, slogger.LevelDebug)
+	})
+
+	Convey("records should be filtered by package or component attribute", t, func() {
+		set := slogger.NewLevelSet(slogger.LevelWarn)
+		buf := &bytes.Buffer{}
+		logger := slog.New(slogger.NewLevelsHandler(slogger.NewPrettyHandler(buf, &slogger.PrettyHandlerOptions{Level: set, NoColor: true}), set))
+		logger.Info("hidden")
+		set.Set("slogger_test", slogger.LevelDebug)
+		logger.Debug("from this package")
+		set.SetComponents(map[string]slog.Level{"deploy": slogger.LevelTrace})
+		logger.Debug("no component")
+		logger.Log(context.Background(), slogger.LevelTrace, "by attr", "component", "deploy")
+		logger.With("component", "deploy").Debug("by logger")
+		logger.WithGroup("g").Debug("grouped", "component", "deploy")
+		So(buf.String(), ShouldNotContainSubstring, "hidden")
+		So(buf.String(), ShouldNotContainSubstring, "no component")
+		So(buf.String(), ShouldNotContainSubstring, "grouped")
+		So(buf.String(), ShouldContainSubstring, "from this package")
+		So(buf.String(), ShouldContainSubstring, "by attr")
+		So(buf.String(), ShouldContainSubstring, "by logger")
+	})
+
+	Convey("sinks without a level should follow the level set", t, func() {
+		set := slogger.NewLevelSet(slogger.LevelWarn)
+		dir := t.TempDir()
+		h, level, file, err := slogger.NewSinkHandler(slogger.Sink{Style: "plain", Target: dir + "/a.log"}, set)
+		So(err, ShouldBeNil)
+		defer file.Close()
+		So(level, ShouldEqual, slogger.LevelWarn)
+		set.Set("deploy", slogger.LevelDebug)
+		slog.New(h).Debug("shown", "component", "deploy")
+		slog.New(h).Info("hidden")
+		body, _ := os.ReadFile(dir + "/a.log")
+		So(string(body), ShouldContainSubstring, "shown")
+		So(string(body), ShouldNotContainSubstring, "hidden")
+		_, level, _, err = slogger.NewSinkHandler(slogger.Sink{Style: "plain", Target: "stdout", Level: "error"}, set)
+		So(err, ShouldBeNil)
+		So(level, ShouldEqual, slogger.LevelError)
+	})
+
+	Convey("the admin endpoint should read & change the levels", t, func() {
+		set := slogger.NewLevelSet(slogger.LevelInfo)
+		srv := httptest.NewServer(slogger.LevelsHTTPHandler(set, "s3cret"))
+		defer srv.Close()
+		do := func(method, query, body string, token string) (int, slogger.LevelsDoc) {
+			req, _ := http.NewRequest(method, srv.URL+query, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := http.DefaultClient.Do(req)
+			So(err, ShouldBeNil)
+			defer res.Body.Close()
+			doc := slogger.LevelsDoc{}
+			json.NewDecoder(res.Body).Decode(&doc)
+			return res.StatusCode, doc
+		}
+		status, _ := do(http.MethodGet, "", "", "wrong")
+		So(status, ShouldEqual, http.StatusUnauthorized)
+
+		status, doc := do(http.MethodPut, "", `{"level":"warn","components":{"kfg":"trace"}}`, "s3cret")
+		So(status, ShouldEqual, http.StatusOK)
+		So(doc.Level, ShouldEqual, "WARN")
+		So(doc.Components, ShouldResemble, map[string]string{"kfg": "TRACE"})
+		So(set.Base(), ShouldEqual, slogger.LevelWarn)
+
+		_, doc = do(http.MethodPut, "?component=gommi&level=error", "", "s3cret")
+		So(doc.Components["gommi"], ShouldEqual, "ERROR")
+		_, doc = do(http.MethodDelete, "?component=kfg", "", "s3cret")
+		So(doc.Components, ShouldResemble, map[string]string{"gommi": "ERROR"})
+
+		status, _ = do(http.MethodPut, "", `{"level":"loud"}`, "s3cret")
+		So(status, ShouldEqual, http.StatusBadRequest)
+		So(set.Base(), ShouldEqual, slogger.LevelWarn)
+	})
+}
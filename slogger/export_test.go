@@ -0,0 +1,20 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import "log/slog"
+
+// SaveLoggerState lets the slogger_test tests change the loggers & levels.
+// Call the returned func to put them back.
+func SaveLoggerState() (restore func()) {
+	def, logger := slog.Default(), Logger
+	console, file, base := logLevel, logLevelFile, logLevels.Base()
+	return func() {
+		CloseSinks()
+		slog.SetDefault(def)
+		Logger = logger
+		logLevel, logLevelFile = console, file
+		logLevels.SetBase(base)
+	}
+}
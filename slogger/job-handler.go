@@ -0,0 +1,260 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	media "github.com/mrmxf/clog/slogger-media"
+)
+
+// JobHandler writes one JSON object per line shaped like an SMPTE ST 2126
+// job log record. Records carrying the slogger-media event attributes become
+// JOB_START, JOB_UPDATE, JOB_END, FUNCTION_START & FUNCTION_END records. All
+// other records are written with their ST 2126 level name & code.
+type JobHandler struct {
+	opts   slog.HandlerOptions
+	out    io.Writer
+	state  *jobState
+	attrs  []groupedAttr
+	groups []string
+}
+
+// groupedAttr is an attribute added with WithAttrs and the groups that were
+// open at the time
+type groupedAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// jobState is shared between all the handlers derived with WithAttrs and
+// WithGroup so that status transitions & function durations are tracked
+// across them.
+type jobState struct {
+	mu        sync.Mutex
+	status    map[media.URL]media.JobStatusEnum
+	functions map[string]time.Time
+}
+
+// JobRecord is the JSON line written for every log record
+type JobRecord struct {
+	Time           string               `json:"time"`
+	Level          media.LogLevelName   `json:"level"`
+	LevelCode      media.LogLevelCode   `json:"levelCode"`
+	Msg            string               `json:"msg"`
+	Job            *media.JobRecordInfo `json:"job,omitempty"`
+	PreviousStatus media.JobStatusEnum  `json:"previousStatus,omitempty"`
+	Function       string               `json:"function,omitempty"`
+	Duration       int64                `json:"functionDuration,omitempty"`
+	Source         *slog.Source         `json:"source,omitempty"`
+	Attrs          map[string]any       `json:"attrs,omitempty"`
+}
+
+var _ slog.Handler = (*JobHandler)(nil)
+
+// NewJobHandler creates a JobHandler that writes to out. If opts is nil the
+// level is Info.
+func NewJobHandler(out io.Writer, opts *slog.HandlerOptions) *JobHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	return &JobHandler{
+		opts: *opts,
+		out:  out,
+		state: &jobState{
+			status:    map[media.URL]media.JobStatusEnum{},
+			functions: map[string]time.Time{},
+		},
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *JobHandler) Enabled(_ context.Context, l slog.Level) bool {
+	min := LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return l >= min
+}
+
+// jobLevelName maps a slog level to the ST 2126 level name
+func jobLevelName(l slog.Level) media.LogLevelName {
+	switch {
+	case l >= LevelFatal:
+		return "FATAL"
+	case l >= LevelError:
+		return "ERROR"
+	case l >= LevelWarn:
+		return "WARN"
+	case l >= LevelInfo:
+		return "INFO"
+	}
+	return "DEBUG"
+}
+
+// Handle implements slog.Handler.
+func (h *JobHandler) Handle(_ context.Context, rec slog.Record) error {
+	r := JobRecord{
+		Time:  rec.Time.Format(media.DateFormat),
+		Level: jobLevelName(rec.Level),
+		Msg:   rec.Message,
+		Attrs: map[string]any{},
+	}
+	if h.opts.AddSource && rec.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		r.Source = &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+	}
+
+	var job *media.JobInfo
+	var started time.Time
+	event := media.LogLevelName("")
+	collect := func(groups []string, a slog.Attr) {
+		// the event attributes are only recognised outside of groups
+		if len(groups) == 0 {
+			switch a.Key {
+			case media.EventKey:
+				event = media.LogLevelName(a.Value.String())
+				return
+			case media.JobKey:
+				if j, ok := a.Value.Any().(*media.JobInfo); ok {
+					job = j
+					return
+				}
+			case media.FunctionKey:
+				r.Function = a.Value.String()
+				return
+			case media.StartedKey:
+				if t, ok := a.Value.Any().(time.Time); ok {
+					started = t
+					return
+				}
+			}
+		}
+		addAttr(r.Attrs, groups, a)
+	}
+	for _, ga := range h.attrs {
+		collect(ga.groups, ga.attr)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		collect(h.groups, a)
+		return true
+	})
+
+	if _, known := media.LogLevel[event]; known {
+		r.Level = event
+	}
+	r.LevelCode = media.LogLevel[r.Level]
+
+	h.state.mu.Lock()
+	if job != nil {
+		info := job.Record()
+		r.Job = &info
+		if prev, seen := h.state.status[info.Id]; seen && prev != info.Status {
+			r.PreviousStatus = prev
+		}
+		h.state.status[info.Id] = info.Status
+	}
+	if len(r.Function) > 0 {
+		key := r.Function
+		if r.Job != nil {
+			key = string(r.Job.Id) + "/" + key
+		}
+		switch r.Level {
+		case media.EventFunctionStart:
+			h.state.functions[key] = rec.Time
+		case media.EventFunctionEnd:
+			if started.IsZero() {
+				started = h.state.functions[key]
+			}
+			delete(h.state.functions, key)
+			if !started.IsZero() {
+				r.Duration = rec.Time.Sub(started).Milliseconds()
+			}
+		}
+	}
+	h.state.mu.Unlock()
+
+	if len(r.Attrs) == 0 {
+		r.Attrs = nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("job handler: %w", err)
+	}
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	_, err = h.out.Write(append(line, '\n'))
+	return err
+}
+
+// addAttr adds a resolved attribute to m inside the groups
+func addAttr(m map[string]any, groups []string, a slog.Attr) {
+	value := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	for _, g := range groups {
+		sub, ok := m[g].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			m[g] = sub
+		}
+		m = sub
+	}
+	if value.Kind() == slog.KindGroup {
+		if len(a.Key) == 0 {
+			for _, ga := range value.Group() {
+				addAttr(m, nil, ga)
+			}
+			return
+		}
+		sub := map[string]any{}
+		for _, ga := range value.Group() {
+			addAttr(sub, nil, ga)
+		}
+		m[a.Key] = sub
+		return
+	}
+	switch value.Kind() {
+	case slog.KindTime:
+		m[a.Key] = value.Time().Format(media.DateFormat)
+	case slog.KindDuration:
+		m[a.Key] = value.Duration().String()
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			m[a.Key] = err.Error()
+			return
+		}
+		m[a.Key] = value.Any()
+	default:
+		m[a.Key] = value.Any()
+	}
+}
+
+// WithAttrs implements slog.Handler.
+func (h *JobHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]groupedAttr{}, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{groups: h.groups, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *JobHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
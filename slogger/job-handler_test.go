@@ -0,0 +1,85 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	media "github.com/mrmxf/clog/slogger-media"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// decode each JSON line written by the job handler
+func jobRecords(buf *bytes.Buffer) []slogger.JobRecord {
+	records := []slogger.JobRecord{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		r := slogger.JobRecord{}
+		So(json.Unmarshal([]byte(line), &r), ShouldBeNil)
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestJobHandler(t *testing.T) {
+
+	Convey("job events should be written as ST 2126 records", t, func() {
+		buf := bytes.NewBuffer(nil)
+		logger := slog.New(slogger.NewJobHandler(buf, &slog.HandlerOptions{Level: slogger.LevelDebug}))
+		job := &media.JobInfo{Id: "job0042", Type: "Transcode", Status: media.QUEUED}
+
+		job.Start(logger, "start")
+		t0 := job.FunctionStart(logger, "encode")
+		time.Sleep(2 * time.Millisecond)
+		job.FunctionEnd(logger, "encode", t0)
+		job.Fail(logger, errors.New("disk full"), "failed")
+		logger.Info("plain", "frames", 25)
+
+		r := jobRecords(buf)
+		So(r, ShouldHaveLength, 5)
+		So(r[0].Level, ShouldEqual, media.EventJobStart)
+		So(r[0].LevelCode, ShouldEqual, media.JOB_START)
+		So(r[0].Job.Id, ShouldEqual, media.URL("job0042"))
+		So(r[0].Job.Status, ShouldEqual, media.RUNNING)
+		So(r[1].Level, ShouldEqual, media.EventFunctionStart)
+		So(r[1].Function, ShouldEqual, "encode")
+		So(r[2].Level, ShouldEqual, media.EventFunctionEnd)
+		So(r[2].Duration, ShouldBeGreaterThanOrEqualTo, 2)
+		So(r[3].Level, ShouldEqual, media.EventJobEnd)
+		So(r[3].Job.Status, ShouldEqual, media.FAILED)
+		So(r[3].PreviousStatus, ShouldEqual, media.RUNNING)
+		So(r[3].Job.Error.Detail, ShouldEqual, "disk full")
+		So(r[3].Job.ActualEndDate, ShouldNotBeEmpty)
+		So(r[4].Level, ShouldEqual, media.LogLevelName("INFO"))
+		So(r[4].Job, ShouldBeNil)
+		So(r[4].Attrs["frames"], ShouldEqual, 25)
+	})
+
+	Convey("WithAttrs & WithGroup should nest attributes", t, func() {
+		buf := bytes.NewBuffer(nil)
+		logger := slog.New(slogger.NewJobHandler(buf, nil))
+		logger.With("a", 1).WithGroup("g").With("b", 2).Warn("nested", "c", 3)
+		logger.Debug("hidden")
+
+		r := jobRecords(buf)
+		So(r, ShouldHaveLength, 1)
+		So(r[0].Level, ShouldEqual, media.LogLevelName("WARN"))
+		So(r[0].Attrs["a"], ShouldEqual, 1)
+		So(r[0].Attrs["g"], ShouldResemble, map[string]any{"b": 2.0, "c": 3.0})
+	})
+
+	Convey("ParseStyle should understand config style names", t, func() {
+		style, err := slogger.ParseStyle("job")
+		So(err, ShouldBeNil)
+		So(style, ShouldEqual, slogger.StyleJob)
+		_, err = slogger.ParseStyle("fancy")
+		So(err, ShouldNotBeNil)
+	})
+}
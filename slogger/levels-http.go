@@ -0,0 +1,117 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// LevelsDoc is the JSON of the levels endpoint. A component with an empty
+// level is removed by a PUT.
+type LevelsDoc struct {
+	Level      string            `json:"level,omitempty"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// LevelsHTTPHandler is an admin endpoint that reads & changes the levels of
+// a running server:
+//
+//	GET                                 {"level":"INFO","components":{"gommi":"WARN"}}
+//	PUT {"level":"debug","components":{"kfg":"trace","gommi":""}}
+//	PUT ?component=kfg&level=debug      (or ?level=warn for the base level)
+//	DELETE ?component=kfg
+//
+// Every request returns the levels after the change. If token is not empty a
+// request needs an "Authorization: Bearer <token>" header. Mount it on an
+// admin port or behind authentication - it changes what is logged.
+func LevelsHTTPHandler(set *LevelSet, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(token) > 0 {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		var err error
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			err = putLevels(set, r)
+		case http.MethodDelete:
+			component := r.URL.Query().Get("component")
+			if len(component) == 0 {
+				err = fmt.Errorf("DELETE needs ?component=name")
+			} else {
+				set.Delete(component)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		doc := LevelsDoc{Level: LevelName(set.Base()), Components: map[string]string{}}
+		for name, l := range set.Components() {
+			doc.Components[name] = LevelName(l)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(doc)
+	})
+}
+
+// putLevels applies the query or the JSON body of a request. Nothing is
+// changed if any level is bad.
+func putLevels(set *LevelSet, r *http.Request) error {
+	doc := LevelsDoc{}
+	q := r.URL.Query()
+	if q.Has("level") {
+		if component := q.Get("component"); len(component) > 0 {
+			doc.Components = map[string]string{component: q.Get("level")}
+		} else {
+			doc.Level = q.Get("level")
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("bad levels json: %w", err)
+	}
+	var base *slog.Level
+	if len(doc.Level) > 0 {
+		l, err := ParseLevel(doc.Level)
+		if err != nil {
+			return err
+		}
+		base = &l
+	}
+	components := map[string]*slog.Level{}
+	for name, value := range doc.Components {
+		if len(value) == 0 {
+			components[name] = nil
+			continue
+		}
+		l, err := ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		components[name] = &l
+	}
+	if base != nil {
+		set.SetBase(*base)
+	}
+	for name, l := range components {
+		if l == nil {
+			set.Delete(name)
+		} else {
+			set.Set(name, *l)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	media "github.com/mrmxf/clog/slogger-media"
+)
+
+// ParseJSONRecord converts a line written by the json or job (ST 2126)
+// handlers back into a record so that it can be filtered & handled again,
+// e.g. by a PrettyHandler. The source of the original call is kept as a
+// "source" attribute because a record can only carry a program counter.
+func ParseJSONRecord(line []byte) (slog.Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	obj := map[string]any{}
+	if err := dec.Decode(&obj); err != nil {
+		return slog.Record{}, errors.New("not a json log line: " + err.Error())
+	}
+	levelName, _ := obj[slog.LevelKey].(string)
+	msg, _ := obj[slog.MessageKey].(string)
+	if len(levelName) == 0 && len(msg) == 0 {
+		return slog.Record{}, errors.New("not a json log line: no level or msg")
+	}
+	t := time.Time{}
+	if s, ok := obj[slog.TimeKey].(string); ok {
+		t, _ = time.Parse(time.RFC3339Nano, s)
+	}
+	level := jsonLevel(levelName, obj)
+	delete(obj, slog.TimeKey)
+	delete(obj, slog.LevelKey)
+	delete(obj, slog.MessageKey)
+
+	attrs := []slog.Attr{}
+	if _, isJob := obj["levelCode"]; isJob {
+		delete(obj, "levelCode")
+		if _, event := media.LogLevel[media.LogLevelName(levelName)]; event && levelName != string(jobLevelName(level)) {
+			attrs = append(attrs, slog.String(media.EventKey, levelName))
+		}
+		// the attrs of a job record are nested to keep them apart from the
+		// job properties
+		if nested, ok := obj["attrs"].(map[string]any); ok {
+			delete(obj, "attrs")
+			for k, v := range nested {
+				if _, clash := obj[k]; !clash {
+					obj[k] = v
+				}
+			}
+		}
+	}
+	if src, ok := obj[slog.SourceKey].(map[string]any); ok {
+		delete(obj, slog.SourceKey)
+		file, _ := src["file"].(string)
+		attrs = append(attrs, slog.String(slog.SourceKey, fmt.Sprintf("%s:%v", file, src["line"])))
+	}
+	rec := slog.NewRecord(t, level, msg, 0)
+	rec.AddAttrs(append(attrs, jsonAttrs(obj)...)...)
+	return rec, nil
+}
+
+// jsonLevel converts the level of a json line. Job & function events are Info
+// (or Error for a failed job).
+func jsonLevel(name string, obj map[string]any) slog.Level {
+	switch media.LogLevelName(name) {
+	case media.EventJobStart, media.EventJobUpdate:
+		return media.LevelJob
+	case media.EventJobEnd:
+		if job, ok := obj[media.JobKey].(map[string]any); ok && job["status"] == string(media.FAILED) {
+			return LevelError
+		}
+		return media.LevelJob
+	case media.EventFunctionStart, media.EventFunctionEnd:
+		return media.LevelFunction
+	}
+	if level, err := ParseLevel(name); err == nil {
+		return level
+	}
+	// the names written by slog e.g. DEBUG-4 or ERROR+2
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err == nil {
+		return level
+	}
+	return LevelInfo
+}
+
+// ReplaceLevelNames is a slog.HandlerOptions ReplaceAttr that writes the
+// slogger level names (e.g. TRACE, SUCCESS, FATAL) rather than DEBUG-4,
+// INFO+2 or ERROR+2
+func ReplaceLevelNames(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.LevelKey {
+		return a
+	}
+	if _, ok := a.Value.Any().(slog.Level); !ok {
+		return a
+	}
+	return sloggerReplaceAttr(groups, a)
+}
+
+// LogFilter selects records by level, time & attributes
+type LogFilter struct {
+	Min   slog.Level
+	Since time.Time // zero for no lower bound
+	Until time.Time // zero for no upper bound
+	Where []AttrCondition
+}
+
+// AttrCondition compares an attribute (dotted key for groups) with a value
+type AttrCondition struct {
+	Key   string
+	Op    string // = | != | ~
+	Value string
+	re    *regexp.Regexp
+}
+
+// ParseWhere parses key=value, key!=value or key~regexp. The first operator
+// in expr is used so values may contain the others.
+func ParseWhere(expr string) (AttrCondition, error) {
+	i := strings.IndexAny(expr, "=~")
+	if i < 1 {
+		return AttrCondition{}, fmt.Errorf("where must be key=value, key!=value or key~regexp (%s)", expr)
+	}
+	c := AttrCondition{Key: expr[:i], Op: expr[i : i+1], Value: expr[i+1:]}
+	if c.Key == "!" {
+		return c, fmt.Errorf("where has no key (%s)", expr)
+	}
+	switch {
+	case c.Op == "=" && strings.HasSuffix(c.Key, "!"):
+		c.Key, c.Op = strings.TrimSuffix(c.Key, "!"), "!="
+	case c.Op == "~":
+		re, err := regexp.Compile(c.Value)
+		if err != nil {
+			return c, fmt.Errorf("bad where (%s): %w", expr, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+// Match is true if the record passes all the filters
+func (f LogFilter) Match(rec slog.Record) bool {
+	if rec.Level < f.Min {
+		return false
+	}
+	if !f.Since.IsZero() && rec.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && rec.Time.After(f.Until) {
+		return false
+	}
+	if len(f.Where) == 0 {
+		return true
+	}
+	values := map[string]string{}
+	rec.Attrs(func(a slog.Attr) bool {
+		flattenAttr(values, "", a)
+		return true
+	})
+	for _, c := range f.Where {
+		value, found := values[c.Key]
+		switch c.Op {
+		case "=":
+			if !found || value != c.Value {
+				return false
+			}
+		case "!=":
+			if found && value == c.Value {
+				return false
+			}
+		case "~":
+			if !found || !c.re.MatchString(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// flattenAttr adds the string value of an attribute with a dotted key
+func flattenAttr(values map[string]string, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+	key := a.Key
+	if len(prefix) > 0 {
+		key = prefix + "." + a.Key
+	}
+	if value.Kind() == slog.KindGroup {
+		for _, ga := range value.Group() {
+			flattenAttr(values, key, ga)
+		}
+		return
+	}
+	values[key] = value.String()
+}
+
+// ParseTimeBound converts a --since or --until value into a time. It may be
+// RFC 3339, a date, a date & time or a duration before now (e.g. 90m).
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, time.DateTime, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("time must be RFC 3339, a date, a date & time or a duration (%s)", s)
+}
+
+// NewViewHandler creates a handler that renders records read with
+// ParseJSONRecord as plain or pretty text (with the current theme & colour
+// policy) or as json with the slogger level names. All levels are handled.
+func NewViewHandler(out *os.File, style SlogStyle) slog.Handler {
+	var h slog.Handler
+	switch style {
+	case StyleJSON:
+		h = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: LevelTrace, ReplaceAttr: ReplaceLevelNames})
+	case StylePlain:
+		h = NewPrettyHandler(out, &PrettyHandlerOptions{Level: LevelTrace, NoColor: true})
+	default:
+		h = NewPrettyHandler(out, &PrettyHandlerOptions{
+			Level:   LevelTrace,
+			NoColor: !ColorEnabled(out),
+			Theme:   CurrentTheme(),
+		})
+	}
+	return NewRedactHandler(h)
+}
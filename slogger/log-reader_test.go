@@ -0,0 +1,87 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// attrMap flattens the top level attributes of a record
+func attrMap(rec slog.Record) map[string]slog.Value {
+	m := map[string]slog.Value{}
+	rec.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+func TestLogReader(t *testing.T) {
+
+	Convey("json handler lines should be read back into records", t, func() {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slogger.LevelTrace, AddSource: true}))
+		logger.Log(context.Background(), slogger.LevelSuccess, "deployed", "env", "prod", slog.Group("k8s", "replicas", 3))
+		logger.Log(context.Background(), slogger.LevelTrace, "detail")
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		rec, err := slogger.ParseJSONRecord(lines[0])
+		So(err, ShouldBeNil)
+		So(rec.Level, ShouldEqual, slogger.LevelSuccess)
+		So(rec.Message, ShouldEqual, "deployed")
+		So(time.Since(rec.Time), ShouldBeLessThan, time.Minute)
+		attrs := attrMap(rec)
+		So(attrs["env"].String(), ShouldEqual, "prod")
+		So(attrs["source"].String(), ShouldContainSubstring, "log-reader_test.go:")
+		So(attrs["k8s"].Kind(), ShouldEqual, slog.KindGroup)
+
+		rec, err = slogger.ParseJSONRecord(lines[1])
+		So(err, ShouldBeNil)
+		So(rec.Level, ShouldEqual, slogger.LevelTrace)
+
+		_, err = slogger.ParseJSONRecord([]byte("plain text"))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("job handler lines should keep the event & job", t, func() {
+		rec, err := slogger.ParseJSONRecord([]byte(`{"time":"2025-06-01T10:00:00.000Z","level":"JOB_END","levelCode":400,"msg":"done","job":{"id":"job1","status":"FAILED"},"attrs":{"host":"a"}}`))
+		So(err, ShouldBeNil)
+		So(rec.Level, ShouldEqual, slogger.LevelError)
+		attrs := attrMap(rec)
+		So(attrs["event"].String(), ShouldEqual, "JOB_END")
+		So(attrs["host"].String(), ShouldEqual, "a")
+		So(attrs["job"].Kind(), ShouldEqual, slog.KindGroup)
+	})
+
+	Convey("a filter should select by level, time & attributes", t, func() {
+		rec, _ := slogger.ParseJSONRecord([]byte(`{"time":"2025-06-01T10:00:00Z","level":"WARN","msg":"m","env":"prod","job":{"status":"RUNNING"}}`))
+		where := func(exprs ...string) slogger.LogFilter {
+			f := slogger.LogFilter{Min: slogger.LevelTrace}
+			for _, e := range exprs {
+				c, err := slogger.ParseWhere(e)
+				So(err, ShouldBeNil)
+				f.Where = append(f.Where, c)
+			}
+			return f
+		}
+		So(where("env=prod").Match(rec), ShouldBeTrue)
+		So(where("env!=prod").Match(rec), ShouldBeFalse)
+		So(where("job.status~^RUN", "missing!=x").Match(rec), ShouldBeTrue)
+		So(where("env=dev").Match(rec), ShouldBeFalse)
+		So(slogger.LogFilter{Min: slogger.LevelError}.Match(rec), ShouldBeFalse)
+
+		since, err := slogger.ParseTimeBound("2025-06-01 11:00:00", time.Now().UTC())
+		So(err, ShouldBeNil)
+		So(slogger.LogFilter{Since: since}.Match(rec), ShouldBeFalse)
+		_, err = slogger.ParseWhere("novalue")
+		So(err, ShouldNotBeNil)
+	})
+}
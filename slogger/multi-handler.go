@@ -0,0 +1,79 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// MultiHandler fans out every record to a list of handlers. Each handler
+// keeps its own level so that, for example, the console can show Info while
+// a log file records Debug.
+type MultiHandler struct {
+	handlers []slog.Handler
+}
+
+var _ slog.Handler = (*MultiHandler)(nil)
+
+// NewMultiHandler creates a handler that sends records to all the handlers.
+// nil handlers are ignored.
+func NewMultiHandler(handlers ...slog.Handler) *MultiHandler {
+	h := &MultiHandler{}
+	for _, handler := range handlers {
+		if handler != nil {
+			h.handlers = append(h.handlers, handler)
+		}
+	}
+	return h
+}
+
+// Handlers returns the handlers that records are sent to
+func (h *MultiHandler) Handlers() []slog.Handler {
+	return h.handlers
+}
+
+// Enabled implements slog.Handler. A record is enabled if any handler wants it.
+func (h *MultiHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler. Every handler that is enabled for the
+// record's level gets a copy of the record. All errors are returned.
+func (h *MultiHandler) Handle(ctx context.Context, rec slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if !handler.Enabled(ctx, rec.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, rec.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+	return &MultiHandler{handlers: handlers}
+}
+
+// WithGroup implements slog.Handler.
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(h.handlers))
+	for _, handler := range h.handlers {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+	return &MultiHandler{handlers: handlers}
+}
@@ -0,0 +1,31 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMultiHandler(t *testing.T) {
+
+	Convey("MultiHandler should fan out with independent levels", t, func() {
+		info := bytes.NewBuffer(nil)
+		debug := bytes.NewBuffer(nil)
+		logger := slog.New(slogger.NewMultiHandler(
+			slogger.NewPrettyHandler(info, &slogger.PrettyHandlerOptions{Level: slogger.LevelInfo, NoColor: true}),
+			slogger.NewPrettyHandler(debug, &slogger.PrettyHandlerOptions{Level: slogger.LevelDebug, NoColor: true}),
+		))
+		logger.Debug("detail")
+		logger.Info("headline")
+		So(info.String(), ShouldNotContainSubstring, "detail")
+		So(info.String(), ShouldContainSubstring, "headline")
+		So(debug.String(), ShouldContainSubstring, "detail")
+		So(debug.String(), ShouldContainSubstring, "headline")
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// NotifyHandler sends records to chat, webhook & email targets. A handler
+// without targets uses the routes of the notifier to choose them. Delivery
+// happens in the log call so keep the level high (e.g. error) - a target
+// that is over its rate limit drops the record rather than waiting.
+// Failures are reported on stderr because they cannot be logged.
+type NotifyHandler struct {
+	notifier *Notifier
+	level    slog.Leveler
+	targets  []string
+	attrs    []groupedAttr
+	groups   []string
+}
+
+var _ slog.Handler = (*NotifyHandler)(nil)
+
+// NewNotifyHandler creates a handler for the named targets (or for the
+// routes if there are none)
+func NewNotifyHandler(n *Notifier, level slog.Leveler, targets ...string) *NotifyHandler {
+	if level == nil {
+		level = LevelError
+	}
+	return &NotifyHandler{notifier: n, level: level, targets: targets}
+}
+
+// Enabled implements slog.Handler.
+func (h *NotifyHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *NotifyHandler) Handle(ctx context.Context, rec slog.Record) error {
+	r := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	for _, ga := range h.attrs {
+		r.AddAttrs(nestAttr(ga.groups, ga.attr))
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		r.AddAttrs(nestAttr(h.groups, a))
+		return true
+	})
+	targets := h.targets
+	if len(targets) == 0 {
+		targets = h.notifier.Route(r)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	err := h.notifier.Send(ctx, NewNotification(r), targets, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "notify failed: %s\n", err.Error())
+	}
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *NotifyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]groupedAttr{}, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{groups: h.groups, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *NotifyHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// nestAttr puts an attribute inside its groups (outermost first)
+func nestAttr(groups []string, a slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+	return a
+}
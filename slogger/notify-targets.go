@@ -0,0 +1,230 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the time allowed to connect to an smtp server
+var NotifySMTPTimeout = 10 * time.Second
+
+// send makes one delivery attempt. The duration is the Retry-After of a
+// webhook response (if any).
+func (n *Notifier) send(ctx context.Context, t *notifyTarget, note Notification) (time.Duration, error) {
+	if t.Type == "email" {
+		return 0, sendMail(ctx, t, note)
+	}
+	body, contentType, err := t.payload(note)
+	if err != nil {
+		return 0, &permanentError{err}
+	}
+	url := t.URL
+	if len(url) == 0 {
+		url = t.Secret
+	}
+	method := http.MethodPost
+	if len(t.Method) > 0 {
+		method = strings.ToUpper(t.Method)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, &permanentError{err}
+	}
+	req.Header.Set("Content-Type", contentType)
+	if t.Type == "webhook" && len(t.Secret) > 0 {
+		req.Header.Set("Authorization", "Bearer "+t.Secret)
+	}
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		// the url of a slack or teams hook is a secret
+		return 0, fmt.Errorf("%s", Redact(err.Error()))
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return 0, nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return retryAfter(res), fmt.Errorf("%s", res.Status)
+	}
+	return 0, &permanentError{fmt.Errorf("%s", res.Status)}
+}
+
+// payload is the request body for a slack, teams or webhook target
+func (t *notifyTarget) payload(note Notification) ([]byte, string, error) {
+	switch t.Type {
+	case "slack":
+		b, err := json.Marshal(map[string]string{"text": slackText(note)})
+		return b, "application/json", err
+	case "teams":
+		b, err := json.Marshal(teamsCard(note))
+		return b, "application/json", err
+	}
+	if t.body == nil {
+		b, err := json.Marshal(map[string]any{
+			"time":  note.Time.Format(time.RFC3339Nano),
+			"level": note.Level,
+			"msg":   note.Msg,
+			"app":   note.App,
+			"attrs": note.Attrs,
+		})
+		return b, "application/json", err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.body.Execute(buf, note); err != nil {
+		return nil, "", fmt.Errorf("body: %w", err)
+	}
+	contentType := "text/plain; charset=utf-8"
+	if json.Valid(buf.Bytes()) {
+		contentType = "application/json"
+	}
+	return buf.Bytes(), contentType, nil
+}
+
+// the emoji of each level in a slack message
+var slackEmoji = map[string]string{
+	strTrace:     ":mag:",
+	strDebug:     ":mag:",
+	strInfo:      ":information_source:",
+	strSuccess:   ":white_check_mark:",
+	strWarn:      ":warning:",
+	strError:     ":x:",
+	strFatal:     ":rotating_light:",
+	strEmergency: ":rotating_light:",
+}
+
+// slackText is the mrkdwn of a slack message
+func slackText(note Notification) string {
+	text := fmt.Sprintf("%s *%s* %s: %s", slackEmoji[note.Level], note.Level, note.App, note.Msg)
+	for _, k := range sortedKeys(note.Attrs) {
+		text += fmt.Sprintf("\n• %s: `%s`", k, note.Attrs[k])
+	}
+	return strings.TrimSpace(text)
+}
+
+// teamsCard is an adaptive card for a teams incoming webhook
+func teamsCard(note Notification) map[string]any {
+	color := "Default"
+	switch {
+	case note.level >= LevelError:
+		color = "Attention"
+	case note.level >= LevelWarn:
+		color = "Warning"
+	case note.level >= LevelSuccess:
+		color = "Good"
+	}
+	facts := []map[string]string{}
+	for _, k := range sortedKeys(note.Attrs) {
+		facts = append(facts, map[string]string{"title": k, "value": note.Attrs[k]})
+	}
+	body := []map[string]any{
+		{"type": "TextBlock", "text": note.Level + " " + note.App, "weight": "Bolder", "color": color},
+		{"type": "TextBlock", "text": note.Msg, "wrap": true},
+	}
+	if len(facts) > 0 {
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// sendMail sends a plain text email. STARTTLS is used if the server offers
+// it and the login is the username & secret of the target.
+func sendMail(ctx context.Context, t *notifyTarget, note Notification) error {
+	subject := &bytes.Buffer{}
+	if err := t.subject.Execute(subject, note); err != nil {
+		return &permanentError{fmt.Errorf("subject: %w", err)}
+	}
+	host, _, err := net.SplitHostPort(t.SMTP)
+	if err != nil {
+		return &permanentError{err}
+	}
+	dialer := net.Dialer{Timeout: NotifySMTPTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", t.SMTP)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if len(t.Username) > 0 {
+		if err := c.Auth(smtp.PlainAuth("", t.Username, t.Secret, host)); err != nil {
+			return &permanentError{err}
+		}
+	}
+	if err := c.Mail(t.From); err != nil {
+		return err
+	}
+	for _, to := range t.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	msg := &bytes.Buffer{}
+	fmt.Fprintf(msg, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n",
+		t.From, strings.Join(t.To, ", "), strings.ReplaceAll(subject.String(), "\n", " "), note.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(msg, "MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", note.Msg)
+	if len(note.Attrs) > 0 {
+		msg.WriteString("\r\n")
+		for _, k := range sortedKeys(note.Attrs) {
+			fmt.Fprintf(msg, "%s: %s\r\n", k, note.Attrs[k])
+		}
+	}
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
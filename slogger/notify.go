@@ -0,0 +1,380 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/mrmxf/clog/semver"
+)
+
+// NotifyConfig is the clog.notify config
+//
+//	clog:
+//	  env:
+//	    slack: {webhook: HOOK_SLACK}
+//	  notify:
+//	    targets:
+//	      team:   {type: slack, env: slack.webhook, rate: 20/m}
+//	      oncall: {type: email, smtp: mail.example.com:587, from: ci@example.com, to: [oncall@example.com]}
+//	    routes:
+//	      - {min: error, where: [env=prod], targets: [team, oncall]}
+//	      - {min: success, targets: [team]}
+type NotifyConfig struct {
+	Targets map[string]NotifyTarget `json:"targets"`
+	Routes  []NotifyRoute           `json:"routes"`
+}
+
+// NotifyTarget is a chat channel, webhook or mailbox
+type NotifyTarget struct {
+	Type     string            `json:"type"`     // slack | teams | webhook | email
+	URL      string            `json:"url"`      // incoming webhook (or use env)
+	Env      string            `json:"env"`      // clog.env key of the secret - see Secret
+	Method   string            `json:"method"`   // webhook http method (default POST)
+	Headers  map[string]string `json:"headers"`  // webhook http headers
+	Body     string            `json:"body"`     // webhook body template (default json)
+	SMTP     string            `json:"smtp"`     // email server host:port
+	From     string            `json:"from"`     // email sender
+	To       []string          `json:"to"`       // email recipients
+	Username string            `json:"username"` // email login (the password is the secret)
+	Subject  string            `json:"subject"`  // email subject template
+	Retries  int               `json:"retries"`  // retries after a failure (default 3, -1 for none)
+	Rate     string            `json:"rate"`     // the most notifications per period e.g. 10/m
+	// Secret is resolved from Env by the caller. It is the webhook URL for
+	// slack & teams (if there is no URL), the bearer token of a webhook and
+	// the smtp password of an email.
+	Secret string `json:"-"`
+}
+
+// NotifyRoute sends the records that pass its filter to its targets
+type NotifyRoute struct {
+	Min     string   `json:"min"`   // lowest level (default info)
+	Where   []string `json:"where"` // attribute filters - see ParseWhere
+	Targets []string `json:"targets"`
+}
+
+// Notification is what is sent to a target. It is the data of body &
+// subject templates e.g. {{.Level}} {{.Msg}} {{index .Attrs "env"}}
+type Notification struct {
+	Time  time.Time
+	Level string // slogger level name e.g. ERROR
+	Msg   string
+	App   string
+	Attrs map[string]string // group attributes have dotted keys
+
+	level slog.Level
+}
+
+// Notifier delivers notifications to the configured targets with retries &
+// rate limits
+type Notifier struct {
+	targets map[string]*notifyTarget
+	routes  []notifyRoute
+	client  *http.Client
+}
+
+type notifyTarget struct {
+	NotifyTarget
+	name    string
+	body    *template.Template
+	subject *template.Template
+	limiter *rateLimiter
+}
+
+type notifyRoute struct {
+	filter  LogFilter
+	targets []string
+}
+
+// the delay before the first retry. It doubles for each retry.
+var NotifyBackoff = 500 * time.Millisecond
+
+// the longest Retry-After that is honoured
+var notifyMaxRetryAfter = 30 * time.Second
+
+// the notifier used by notify sinks & clog Notify
+var notifier *Notifier
+
+// SetNotifier sets the notifier used by notify sinks
+func SetNotifier(n *Notifier) {
+	notifier = n
+}
+
+// DefaultNotifier returns the notifier set by SetNotifier (or nil)
+func DefaultNotifier() *Notifier {
+	return notifier
+}
+
+// template functions for webhook bodies & email subjects
+var notifyFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewNotifier checks the config & creates a notifier
+func NewNotifier(cfg NotifyConfig) (*Notifier, error) {
+	n := &Notifier{
+		targets: map[string]*notifyTarget{},
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+	errs := []error{}
+	for name, t := range cfg.Targets {
+		target, err := newNotifyTarget(name, t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n.targets[name] = target
+	}
+	for i, r := range cfg.Routes {
+		route := notifyRoute{filter: LogFilter{Min: LevelInfo}, targets: r.Targets}
+		if len(r.Min) > 0 {
+			level, err := ParseLevel(r.Min)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("route #%d: %w", i, err))
+				continue
+			}
+			route.filter.Min = level
+		}
+		for _, expr := range r.Where {
+			c, err := ParseWhere(expr)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("route #%d: %w", i, err))
+				continue
+			}
+			route.filter.Where = append(route.filter.Where, c)
+		}
+		for _, name := range r.Targets {
+			if _, known := cfg.Targets[name]; !known {
+				errs = append(errs, fmt.Errorf("route #%d: unknown target (%s)", i, name))
+			}
+		}
+		n.routes = append(n.routes, route)
+	}
+	return n, errors.Join(errs...)
+}
+
+func newNotifyTarget(name string, t NotifyTarget) (*notifyTarget, error) {
+	target := &notifyTarget{NotifyTarget: t, name: name}
+	switch {
+	case t.Retries == 0:
+		target.Retries = 3
+	case t.Retries < 0:
+		target.Retries = 0
+	}
+	switch t.Type {
+	case "slack", "teams", "webhook":
+		if len(t.URL) == 0 && (t.Type == "webhook" || len(t.Secret) == 0) {
+			return nil, fmt.Errorf("target %s: %s needs a url", name, t.Type)
+		}
+	case "email":
+		if len(t.SMTP) == 0 || len(t.From) == 0 || len(t.To) == 0 {
+			return nil, fmt.Errorf("target %s: email needs smtp, from & to", name)
+		}
+	default:
+		return nil, fmt.Errorf("target %s: unknown type (%s) use slack | teams | webhook | email", name, t.Type)
+	}
+	var err error
+	if len(t.Body) > 0 {
+		if target.body, err = template.New(name).Funcs(notifyFuncs).Parse(t.Body); err != nil {
+			return nil, fmt.Errorf("target %s: body: %w", name, err)
+		}
+	}
+	subject := t.Subject
+	if len(subject) == 0 {
+		subject = "[{{.Level}}] {{.App}}: {{.Msg}}"
+	}
+	if target.subject, err = template.New(name).Funcs(notifyFuncs).Parse(subject); err != nil {
+		return nil, fmt.Errorf("target %s: subject: %w", name, err)
+	}
+	if len(t.Rate) > 0 {
+		if target.limiter, err = newRateLimiter(t.Rate); err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+	}
+	return target, nil
+}
+
+// Targets returns the names of the configured targets
+func (n *Notifier) Targets() []string {
+	names := []string{}
+	for name := range n.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Route returns the targets of all the routes that match the record
+func (n *Notifier) Route(rec slog.Record) []string {
+	targets := []string{}
+	for _, r := range n.routes {
+		if !r.filter.Match(rec) {
+			continue
+		}
+		for _, t := range r.targets {
+			if !slices.Contains(targets, t) {
+				targets = append(targets, t)
+			}
+		}
+	}
+	return targets
+}
+
+// NewNotification converts a record. Secrets are redacted when it is sent.
+func NewNotification(rec slog.Record) Notification {
+	app := semver.Info().AppName
+	if len(app) == 0 {
+		app = "clog"
+	}
+	note := Notification{
+		Time:  rec.Time,
+		Level: LevelName(rec.Level),
+		Msg:   rec.Message,
+		App:   app,
+		Attrs: map[string]string{},
+		level: rec.Level,
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		flattenAttr(note.Attrs, "", a)
+		return true
+	})
+	return note
+}
+
+// Send delivers the notification to the targets. A target that is over its
+// rate limit is skipped unless wait is true. All errors are returned.
+func (n *Notifier) Send(ctx context.Context, note Notification, targets []string, wait bool) error {
+	note.Msg = Redact(note.Msg)
+	for k, v := range note.Attrs {
+		note.Attrs[k] = Redact(v)
+	}
+	errs := []error{}
+	for _, name := range targets {
+		t, known := n.targets[name]
+		if !known {
+			errs = append(errs, fmt.Errorf("unknown notify target (%s)", name))
+			continue
+		}
+		if t.limiter != nil && !t.limiter.take(ctx, wait) {
+			errs = append(errs, fmt.Errorf("notify target %s: over the rate limit (%s)", name, t.Rate))
+			continue
+		}
+		if err := n.deliver(ctx, t, note); err != nil {
+			errs = append(errs, fmt.Errorf("notify target %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver sends with retries. Network errors, 429 & 5xx responses are
+// retried with a doubling backoff or after the Retry-After of the response.
+func (n *Notifier) deliver(ctx context.Context, t *notifyTarget, note Notification) error {
+	var err error
+	delay := NotifyBackoff
+	for attempt := 0; attempt <= t.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		var retryAfter time.Duration
+		retryAfter, err = n.send(ctx, t, note)
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return err
+		}
+		if retryAfter > 0 {
+			delay = min(retryAfter, notifyMaxRetryAfter)
+		}
+	}
+	return err
+}
+
+// permanentError is a failure that is not worth retrying e.g. a 404
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// retryAfter reads the Retry-After seconds of a response
+func retryAfter(res *http.Response) time.Duration {
+	if s, err := strconv.Atoi(strings.TrimSpace(res.Header.Get("Retry-After"))); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return 0
+}
+
+// rateLimiter is a token bucket that holds up to n tokens & refills n tokens
+// every period
+type rateLimiter struct {
+	mu     sync.Mutex
+	n      float64
+	period time.Duration
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter parses a rate like 10/m, 1/s or 100/h
+func newRateLimiter(rate string) (*rateLimiter, error) {
+	count, unit, found := strings.Cut(rate, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if !found || err != nil || n < 1 {
+		return nil, fmt.Errorf("rate must be like 10/m (%s)", rate)
+	}
+	periods := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour, "d": 24 * time.Hour}
+	period, known := periods[strings.TrimSpace(unit)]
+	if !known {
+		return nil, fmt.Errorf("rate period must be s, m, h or d (%s)", rate)
+	}
+	return &rateLimiter{n: float64(n), period: period, tokens: float64(n), last: time.Now()}, nil
+}
+
+// take uses a token. If there is none then it waits for one (if wait is
+// true) or returns false.
+func (l *rateLimiter) take(ctx context.Context, wait bool) bool {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = min(l.n, l.tokens+now.Sub(l.last).Seconds()*l.n/l.period.Seconds())
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return true
+		}
+		need := time.Duration((1 - l.tokens) * float64(l.period) / l.n)
+		l.mu.Unlock()
+		if !wait {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(need):
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// hookServer records the bodies it receives. The first failures requests
+// get a 503.
+type hookServer struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	failures int
+	status   int
+}
+
+func (s *hookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(body))
+	s.headers = append(s.headers, r.Header.Clone())
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+	}
+}
+
+// smtpServer is a minimal SMTP stand-in that returns the DATA of one mail
+func smtpServer(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail := make(chan string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "DATA":
+				reply("354 go ahead")
+				data := ""
+				for {
+					l, _ := r.ReadString('\n')
+					if l == ".\r\n" || l == "" {
+						break
+					}
+					data += l
+				}
+				mail <- data
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return l.Addr().String(), mail
+}
+
+func TestNotify(t *testing.T) {
+	backoff := slogger.NotifyBackoff
+	slogger.NotifyBackoff = time.Millisecond
+	defer func() { slogger.NotifyBackoff = backoff }()
+	r := slogger.DefaultRedactor()
+	defer r.Reset()
+	ctx := context.Background()
+	rec := func(level slog.Level, msg string, args ...any) slog.Record {
+		rec := slog.NewRecord(time.Now(), level, msg, 0)
+		rec.Add(args...)
+		return rec
+	}
+
+	Convey("slack, teams & webhook targets should get their payloads", t, func() {
+		r.Reset()
+		hook := &hookServer{}
+		srv := httptest.NewServer(hook)
+		defer srv.Close()
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{Targets: map[string]slogger.NotifyTarget{
+			"slack": {Type: "slack", Secret: srv.URL + "/slack"},
+			"teams": {Type: "teams", URL: srv.URL + "/teams"},
+			"hook":  {Type: "webhook", URL: srv.URL, Secret: "tok3n-value", Body: `{"text": {{printf "%s %s" .Level .Msg | json}}, "env": {{index .Attrs "env" | json}}}`},
+			"raw":   {Type: "webhook", URL: srv.URL},
+		}})
+		So(err, ShouldBeNil)
+		slogger.MarkSecret("tok3n-value")
+
+		note := slogger.NewNotification(rec(slogger.LevelError, "failed with tok3n-value", "env", "prod"))
+		So(n.Send(ctx, note, []string{"slack", "teams", "hook", "raw"}, true), ShouldBeNil)
+		So(hook.bodies, ShouldHaveLength, 4)
+		So(hook.bodies[0], ShouldContainSubstring, `*ERROR* clog: failed with [REDACTED]`)
+		So(hook.bodies[0], ShouldContainSubstring, "env: `prod`")
+		So(hook.bodies[1], ShouldContainSubstring, `"AdaptiveCard"`)
+		So(hook.bodies[1], ShouldContainSubstring, `"Attention"`)
+		So(hook.bodies[2], ShouldEqual, `{"text": "ERROR failed with [REDACTED]", "env": "prod"}`)
+		So(hook.headers[2].Get("Authorization"), ShouldEqual, "Bearer tok3n-value")
+		raw := map[string]any{}
+		So(json.Unmarshal([]byte(hook.bodies[3]), &raw), ShouldBeNil)
+		So(raw["level"], ShouldEqual, "ERROR")
+		So(raw["attrs"], ShouldResemble, map[string]any{"env": "prod"})
+	})
+
+	Convey("failures should be retried & bad targets reported", t, func() {
+		hook := &hookServer{failures: 2}
+		srv := httptest.NewServer(hook)
+		defer srv.Close()
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{Targets: map[string]slogger.NotifyTarget{
+			"hook": {Type: "webhook", URL: srv.URL},
+			"once": {Type: "webhook", URL: srv.URL, Retries: -1},
+		}})
+		So(err, ShouldBeNil)
+		note := slogger.NewNotification(rec(slogger.LevelInfo, "hi"))
+		So(n.Send(ctx, note, []string{"hook"}, true), ShouldBeNil)
+		So(hook.bodies, ShouldHaveLength, 3)
+
+		hook.failures = 1
+		So(n.Send(ctx, note, []string{"once"}, true), ShouldNotBeNil)
+
+		hook.status = http.StatusNotFound
+		hook.bodies = nil
+		So(n.Send(ctx, note, []string{"hook"}, true), ShouldNotBeNil)
+		So(hook.bodies, ShouldHaveLength, 1)
+
+		So(n.Send(ctx, note, []string{"nobody"}, true).Error(), ShouldContainSubstring, "unknown")
+		_, err = slogger.NewNotifier(slogger.NotifyConfig{
+			Targets: map[string]slogger.NotifyTarget{"x": {Type: "pager"}},
+			Routes:  []slogger.NotifyRoute{{Min: "loud", Targets: []string{"y"}}},
+		})
+		So(err.Error(), ShouldContainSubstring, "unknown type")
+		So(err.Error(), ShouldContainSubstring, "route #0")
+	})
+
+	Convey("a target over its rate limit should drop unless waiting", t, func() {
+		hook := &hookServer{}
+		srv := httptest.NewServer(hook)
+		defer srv.Close()
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{Targets: map[string]slogger.NotifyTarget{
+			"hook": {Type: "webhook", URL: srv.URL, Rate: "2/h"},
+		}})
+		So(err, ShouldBeNil)
+		note := slogger.NewNotification(rec(slogger.LevelInfo, "hi"))
+		So(n.Send(ctx, note, []string{"hook"}, false), ShouldBeNil)
+		So(n.Send(ctx, note, []string{"hook"}, false), ShouldBeNil)
+		So(n.Send(ctx, note, []string{"hook"}, false).Error(), ShouldContainSubstring, "rate limit")
+		short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		So(n.Send(short, note, []string{"hook"}, true), ShouldNotBeNil)
+		So(hook.bodies, ShouldHaveLength, 2)
+
+		_, err = slogger.NewNotifier(slogger.NotifyConfig{Targets: map[string]slogger.NotifyTarget{
+			"hook": {Type: "webhook", URL: srv.URL, Rate: "often"},
+		}})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("routes should choose targets by level & attributes", t, func() {
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{
+			Targets: map[string]slogger.NotifyTarget{
+				"team":   {Type: "slack", URL: "http://localhost/team"},
+				"oncall": {Type: "slack", URL: "http://localhost/oncall"},
+			},
+			Routes: []slogger.NotifyRoute{
+				{Min: "error", Where: []string{"env=prod"}, Targets: []string{"team", "oncall"}},
+				{Min: "success", Targets: []string{"team"}},
+			},
+		})
+		So(err, ShouldBeNil)
+		So(n.Route(rec(slogger.LevelError, "x", "env", "prod")), ShouldResemble, []string{"team", "oncall"})
+		So(n.Route(rec(slogger.LevelError, "x", "env", "dev")), ShouldResemble, []string{"team"})
+		So(n.Route(rec(slogger.LevelInfo, "x", "env", "prod")), ShouldBeEmpty)
+	})
+
+	Convey("an email should be sent to the smtp server", t, func() {
+		addr, mail := smtpServer(t)
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{Targets: map[string]slogger.NotifyTarget{
+			"oncall": {Type: "email", SMTP: addr, From: "ci@example.com", To: []string{"a@example.com", "b@example.com"}},
+		}})
+		So(err, ShouldBeNil)
+		note := slogger.NewNotification(rec(slogger.LevelFatal, "disk full", slog.Group("host", "name", "db1")))
+		So(n.Send(ctx, note, []string{"oncall"}, true), ShouldBeNil)
+		data := <-mail
+		So(data, ShouldContainSubstring, "Subject: [FATAL] clog: disk full\r\n")
+		So(data, ShouldContainSubstring, "To: a@example.com, b@example.com\r\n")
+		So(data, ShouldContainSubstring, "host.name: db1\r\n")
+	})
+
+	Convey("a notify sink should route the records of a logger", t, func() {
+		hook := &hookServer{}
+		srv := httptest.NewServer(hook)
+		defer srv.Close()
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{
+			Targets: map[string]slogger.NotifyTarget{"hook": {Type: "webhook", URL: srv.URL}},
+			Routes:  []slogger.NotifyRoute{{Where: []string{"deploy.env=prod"}, Targets: []string{"hook"}}},
+		})
+		So(err, ShouldBeNil)
+		slogger.SetNotifier(n)
+		defer slogger.SetNotifier(nil)
+
+		h, _, _, err := slogger.NewSinkHandler(slogger.Sink{Style: "notify", Level: "warn"}, slogger.LevelInfo)
+		So(err, ShouldBeNil)
+		logger := slog.New(h).WithGroup("deploy").With("env", "prod")
+		logger.Info("ignored")
+		logger.Warn("slow rollout", "step", 3)
+		slog.New(h).Warn("no env")
+		So(hook.bodies, ShouldHaveLength, 1)
+		So(hook.bodies[0], ShouldContainSubstring, `"deploy.step":"3"`)
+
+		_, _, _, err = slogger.NewSinkHandler(slogger.Sink{Style: "notify", Target: "hook, nobody"}, slogger.LevelInfo)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("a notify sink without a level should only send errors", t, func() {
+		hook := &hookServer{}
+		srv := httptest.NewServer(hook)
+		defer srv.Close()
+		n, err := slogger.NewNotifier(slogger.NotifyConfig{
+			Targets: map[string]slogger.NotifyTarget{"hook": {Type: "webhook", URL: srv.URL}},
+		})
+		So(err, ShouldBeNil)
+		slogger.SetNotifier(n)
+		defer slogger.SetNotifier(nil)
+
+		h, level, _, err := slogger.NewSinkHandler(slogger.Sink{Style: "notify", Target: "hook"}, slogger.NewLevelSet(slogger.LevelDebug))
+		So(err, ShouldBeNil)
+		So(level, ShouldEqual, slogger.LevelError)
+		logger := slog.New(h)
+		logger.Info("deployed")
+		logger.Warn("slow rollout")
+		logger.Error("rollback")
+		So(hook.bodies, ShouldHaveLength, 1)
+		So(hook.bodies[0], ShouldContainSubstring, "rollback")
+	})
+}
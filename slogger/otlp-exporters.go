@@ -0,0 +1,96 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLPFileExporter appends one ExportLogsServiceRequest per line to a writer.
+// This is the OTLP JSON file format read by the collector's otlpjsonfile
+// receiver.
+type OTLPFileExporter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewOTLPFileExporter creates an exporter that writes to out
+func NewOTLPFileExporter(out io.Writer) *OTLPFileExporter {
+	return &OTLPFileExporter{out: out}
+}
+
+// Export implements OTLPExporter.
+func (e *OTLPFileExporter) Export(_ context.Context, body []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.out.Write(append(body, '\n'))
+	return err
+}
+
+// OTLPHTTPExporter POSTs OTLP/JSON to a collector's OTLP/HTTP endpoint
+type OTLPHTTPExporter struct {
+	Endpoint string            // e.g. http://localhost:4318/v1/logs
+	Headers  map[string]string // e.g. an Authorization header
+	Client   *http.Client
+}
+
+// the time allowed for one export to a collector
+var OTLPTimeout = 5 * time.Second
+
+// NewOTLPHTTPExporter creates an exporter for endpoint. An endpoint without a
+// path (e.g. http://localhost:4318) gets the default /v1/logs path.
+func NewOTLPHTTPExporter(endpoint string, headers map[string]string) (*OTLPHTTPExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("otlp endpoint must be http or https (%s)", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/logs"
+	}
+	return &OTLPHTTPExporter{
+		Endpoint: u.String(),
+		Headers:  headers,
+		Client:   &http.Client{Timeout: OTLPTimeout},
+	}, nil
+}
+
+// Export implements OTLPExporter. Any status other than 2xx is an error.
+func (e *OTLPHTTPExporter) Export(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	res, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("%s: %s %s", e.Endpoint, res.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// isOTLPEndpoint is true if a sink target is a URL rather than a file
+func isOTLPEndpoint(target string) bool {
+	t := strings.ToLower(target)
+	return strings.HasPrefix(t, "http://") || strings.HasPrefix(t, "https://")
+}
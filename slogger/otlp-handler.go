@@ -0,0 +1,347 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrmxf/clog/semver"
+)
+
+// OTLPHandler is a bridge from slog to OpenTelemetry logs. Every record is
+// converted to an OTLP LogRecord and exported as an OTLP/JSON
+// ExportLogsServiceRequest - either POSTed to a collector (OTLP/HTTP) or
+// appended to a file in the format read by the collector's otlpjsonfile
+// receiver. The custom slogger levels map to the OTel severity numbers:
+//
+//	TRACE 1  DEBUG 5  INFO 9  SUCCESS 10  WARN 13  ERROR 17  FATAL 21  EMERGENCY 24
+//
+// Groups are flattened to dotted attribute keys (e.g. http.method) and the
+// source, if requested, uses the OTel code.* attribute names.
+type OTLPHandler struct {
+	opts   OTLPOptions
+	state  *otlpState
+	attrs  []groupedAttr
+	groups []string
+}
+
+// OTLPOptions configure an OTLPHandler
+type OTLPOptions struct {
+	Level     slog.Leveler // minimum level - default Info
+	AddSource bool         // add code.filepath, code.lineno & code.function
+	// Resource attributes of every export. If nil then ServiceResource() is
+	// used at export time so that the version is known.
+	Resource []slog.Attr
+	// Scope is the instrumentation scope name - default the slogger package
+	Scope string
+	// BatchSize is the number of records buffered before an export. The
+	// default of 1 exports every record because a CLI can exit at any time.
+	// Use Flush or Close to export a partial batch.
+	BatchSize int
+}
+
+// OTLPExporter sends one OTLP/JSON ExportLogsServiceRequest
+type OTLPExporter interface {
+	Export(ctx context.Context, body []byte) error
+}
+
+// otlpState is shared between the handlers derived with WithAttrs and
+// WithGroup so that they share the batch & the exporter
+type otlpState struct {
+	mu       sync.Mutex
+	exporter OTLPExporter
+	batch    []otlpLogRecord
+	failed   bool
+}
+
+// the OTLP/JSON shapes - see opentelemetry-proto logs/v1 & common/v1. 64 bit
+// integers are strings in the proto3 JSON mapping.
+type otlpValue map[string]any
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpValue      `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+var _ slog.Handler = (*OTLPHandler)(nil)
+
+// NewOTLPHandler creates a handler that exports records with exporter
+func NewOTLPHandler(exporter OTLPExporter, opts *OTLPOptions) *OTLPHandler {
+	if opts == nil {
+		opts = &OTLPOptions{}
+	}
+	h := &OTLPHandler{
+		opts:  *opts,
+		state: &otlpState{exporter: exporter},
+	}
+	if h.opts.BatchSize < 1 {
+		h.opts.BatchSize = 1
+	}
+	if len(h.opts.Scope) == 0 {
+		h.opts.Scope = "github.com/mrmxf/clog/slogger"
+	}
+	return h
+}
+
+// OTLPSeverity maps a slog level to the OTel severity number & text
+func OTLPSeverity(l slog.Level) (int, string) {
+	switch {
+	case l >= LevelEmergency:
+		return 24, strEmergency
+	case l >= LevelFatal:
+		return 21, strFatal
+	case l >= LevelError:
+		return 17, strError
+	case l >= LevelWarn:
+		return 13, strWarn
+	case l >= LevelSuccess:
+		return 10, strSuccess
+	case l >= LevelInfo:
+		return 9, strInfo
+	case l >= LevelDebug:
+		return 5, strDebug
+	}
+	return 1, strTrace
+}
+
+// ServiceResource returns the OTel resource attributes of the app from
+// semver.Info(). Empty values are left out.
+func ServiceResource() []slog.Attr {
+	info := semver.Info()
+	name := info.AppName
+	if len(name) == 0 {
+		name = "clog"
+	}
+	attrs := []slog.Attr{slog.String("service.name", name)}
+	if len(info.Short) > 0 {
+		attrs = append(attrs, slog.String("service.version", info.Short))
+	}
+	if len(info.CommitId) > 0 {
+		attrs = append(attrs, slog.String("vcs.ref.head.revision", info.CommitId))
+	}
+	return attrs
+}
+
+// Enabled implements slog.Handler.
+func (h *OTLPHandler) Enabled(_ context.Context, l slog.Level) bool {
+	min := LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return l >= min
+}
+
+// Handle implements slog.Handler. The record is added to the batch which is
+// exported when it is full.
+func (h *OTLPHandler) Handle(ctx context.Context, rec slog.Record) error {
+	number, text := OTLPSeverity(rec.Level)
+	r := otlpLogRecord{
+		TimeUnixNano:         unixNano(rec.Time),
+		ObservedTimeUnixNano: unixNano(time.Now()),
+		SeverityNumber:       number,
+		SeverityText:         text,
+		Body:                 otlpValue{"stringValue": rec.Message},
+	}
+	for _, ga := range h.attrs {
+		r.Attributes = appendOTLPAttr(r.Attributes, ga.groups, ga.attr)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		r.Attributes = appendOTLPAttr(r.Attributes, h.groups, a)
+		return true
+	})
+	if h.opts.AddSource && rec.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		r.Attributes = append(r.Attributes,
+			otlpKeyValue{"code.filepath", otlpValue{"stringValue": frame.File}},
+			otlpKeyValue{"code.lineno", otlpValue{"intValue": strconv.Itoa(frame.Line)}},
+			otlpKeyValue{"code.function", otlpValue{"stringValue": frame.Function}})
+	}
+
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	h.state.batch = append(h.state.batch, r)
+	if len(h.state.batch) < h.opts.BatchSize {
+		return nil
+	}
+	return h.export(ctx)
+}
+
+// Flush exports any buffered records
+func (h *OTLPHandler) Flush() error {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+	return h.export(context.Background())
+}
+
+// Close flushes the handler. It implements io.Closer so that the handler can
+// be closed with the sinks.
+func (h *OTLPHandler) Close() error {
+	return h.Flush()
+}
+
+// export sends the batch. The first failure is reported on stderr and then
+// the handler stops exporting so that a missing collector does not slow
+// down every log call. The state must be locked.
+func (h *OTLPHandler) export(ctx context.Context) error {
+	if len(h.state.batch) == 0 {
+		return nil
+	}
+	batch := h.state.batch
+	h.state.batch = nil
+	if h.state.failed {
+		return nil
+	}
+	resource := h.opts.Resource
+	if resource == nil {
+		resource = ServiceResource()
+	}
+	req := otlpRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource: otlpResource{Attributes: appendOTLPAttrs(nil, resource)},
+		ScopeLogs: []otlpScopeLogs{{
+			Scope:      otlpScope{Name: h.opts.Scope, Version: semver.Info().Short},
+			LogRecords: batch,
+		}},
+	}}}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("otlp handler: %w", err)
+	}
+	if err := h.state.exporter.Export(ctx, body); err != nil {
+		h.state.failed = true
+		fmt.Fprintf(os.Stderr, "otlp log export failed - no more records will be exported: %s\n", err.Error())
+		return fmt.Errorf("otlp handler: %w", err)
+	}
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *OTLPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]groupedAttr{}, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{groups: h.groups, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *OTLPHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+func unixNano(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// appendOTLPAttrs converts attributes without groups
+func appendOTLPAttrs(kvs []otlpKeyValue, attrs []slog.Attr) []otlpKeyValue {
+	for _, a := range attrs {
+		kvs = appendOTLPAttr(kvs, nil, a)
+	}
+	return kvs
+}
+
+// appendOTLPAttr appends a resolved attribute with its key prefixed by the
+// groups. Group values are flattened in the same way.
+func appendOTLPAttr(kvs []otlpKeyValue, groups []string, a slog.Attr) []otlpKeyValue {
+	value := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	if value.Kind() == slog.KindGroup {
+		if len(a.Key) > 0 {
+			groups = append(append([]string{}, groups...), a.Key)
+		}
+		for _, ga := range value.Group() {
+			kvs = appendOTLPAttr(kvs, groups, ga)
+		}
+		return kvs
+	}
+	key := strings.Join(append(append([]string{}, groups...), a.Key), ".")
+	return append(kvs, otlpKeyValue{Key: key, Value: otlpAnyValue(value)})
+}
+
+// otlpAnyValue converts a resolved slog value into an OTLP AnyValue
+func otlpAnyValue(v slog.Value) otlpValue {
+	switch v.Kind() {
+	case slog.KindString:
+		return otlpValue{"stringValue": v.String()}
+	case slog.KindBool:
+		return otlpValue{"boolValue": v.Bool()}
+	case slog.KindInt64:
+		return otlpValue{"intValue": strconv.FormatInt(v.Int64(), 10)}
+	case slog.KindUint64:
+		return otlpValue{"intValue": strconv.FormatUint(v.Uint64(), 10)}
+	case slog.KindFloat64:
+		return otlpValue{"doubleValue": v.Float64()}
+	case slog.KindTime:
+		return otlpValue{"stringValue": v.Time().Format(time.RFC3339Nano)}
+	case slog.KindDuration:
+		return otlpValue{"stringValue": v.Duration().String()}
+	}
+	switch x := v.Any().(type) {
+	case error:
+		return otlpValue{"stringValue": x.Error()}
+	case []string:
+		values := []otlpValue{}
+		for _, s := range x {
+			values = append(values, otlpValue{"stringValue": s})
+		}
+		return otlpValue{"arrayValue": map[string]any{"values": values}}
+	case []byte:
+		return otlpValue{"bytesValue": x} // base64 via encoding/json
+	case fmt.Stringer:
+		return otlpValue{"stringValue": x.String()}
+	}
+	return otlpValue{"stringValue": fmt.Sprintf("%+v", v.Any())}
+}
@@ -0,0 +1,181 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// otlpRequest is the part of an ExportLogsServiceRequest that is checked
+type otlpRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			LogRecords []struct {
+				SeverityNumber int            `json:"severityNumber"`
+				SeverityText   string         `json:"severityText"`
+				Body           map[string]any `json:"body"`
+				Attributes     []otlpKeyValue `json:"attributes"`
+			} `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+func attrValue(kvs []otlpKeyValue, key string) any {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			for _, v := range kv.Value {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+func TestOTLPHandler(t *testing.T) {
+
+	Convey("custom levels should map to OTel severity numbers", t, func() {
+		for level, want := range map[slog.Level]int{
+			slogger.LevelTrace:     1,
+			slogger.LevelDebug:     5,
+			slogger.LevelInfo:      9,
+			slogger.LevelSuccess:   10,
+			slogger.LevelWarn:      13,
+			slogger.LevelError:     17,
+			slogger.LevelFatal:     21,
+			slogger.LevelEmergency: 24,
+		} {
+			number, _ := slogger.OTLPSeverity(level)
+			So(number, ShouldEqual, want)
+		}
+		_, text := slogger.OTLPSeverity(slogger.LevelSuccess)
+		So(text, ShouldEqual, "SUCCESS")
+	})
+
+	Convey("a file exporter should write one request per line", t, func() {
+		out := &bytes.Buffer{}
+		h := slogger.NewOTLPHandler(slogger.NewOTLPFileExporter(out), &slogger.OTLPOptions{
+			Level:     slogger.LevelTrace,
+			AddSource: true,
+			Resource:  []slog.Attr{slog.String("service.name", "test")},
+		})
+		logger := slog.New(h).With("run", 7).WithGroup("http")
+		logger.Log(context.Background(), slogger.LevelFatal, "down", "method", "GET", "ok", false)
+		logger.Log(context.Background(), slogger.LevelTrace, "detail")
+
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		So(lines, ShouldHaveLength, 2)
+		req := otlpRequest{}
+		So(json.Unmarshal(lines[0], &req), ShouldBeNil)
+		So(attrValue(req.ResourceLogs[0].Resource.Attributes, "service.name"), ShouldEqual, "test")
+		rec := req.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+		So(rec.SeverityNumber, ShouldEqual, 21)
+		So(rec.SeverityText, ShouldEqual, "FATAL")
+		So(rec.Body["stringValue"], ShouldEqual, "down")
+		So(attrValue(rec.Attributes, "run"), ShouldEqual, "7")
+		So(attrValue(rec.Attributes, "http.method"), ShouldEqual, "GET")
+		So(attrValue(rec.Attributes, "http.ok"), ShouldEqual, false)
+		So(attrValue(rec.Attributes, "code.filepath"), ShouldEndWith, "otlp-handler_test.go")
+	})
+
+	Convey("records should be batched until flushed", t, func() {
+		out := &bytes.Buffer{}
+		h := slogger.NewOTLPHandler(slogger.NewOTLPFileExporter(out), &slogger.OTLPOptions{BatchSize: 10})
+		logger := slog.New(h)
+		logger.Info("one")
+		logger.Warn("two")
+		So(out.Len(), ShouldEqual, 0)
+		So(h.Close(), ShouldBeNil)
+		req := otlpRequest{}
+		So(json.Unmarshal(out.Bytes(), &req), ShouldBeNil)
+		So(req.ResourceLogs[0].ScopeLogs[0].LogRecords, ShouldHaveLength, 2)
+		So(attrValue(req.ResourceLogs[0].Resource.Attributes, "service.name"), ShouldNotBeNil)
+	})
+
+	Convey("an otlp sink should POST to a collector", t, func() {
+		var mu sync.Mutex
+		bodies := [][]byte{}
+		headers := http.Header{}
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.URL.Path != "/v1/logs" || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, body)
+			headers = r.Header.Clone()
+			w.Write([]byte("{}"))
+		}))
+		defer collector.Close()
+
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "otlp.jsonl")
+		defer slogger.SaveLoggerState()()
+		os.Setenv("CLOG_TEST_OTLP_TOKEN", "sesame")
+		defer os.Unsetenv("CLOG_TEST_OTLP_TOKEN")
+
+		err := slogger.UseTeeLogger(slogger.LevelInfo, []slogger.Sink{
+			{Style: "otlp", Target: collector.URL, Headers: map[string]string{"Authorization": "Bearer $CLOG_TEST_OTLP_TOKEN"}},
+			{Style: "otlp", Target: filePath, Level: "warn"},
+		})
+		So(err, ShouldBeNil)
+		slogger.Success("deployed", "env", "prod")
+		slogger.Error("broken")
+		slogger.CloseSinks()
+
+		mu.Lock()
+		defer mu.Unlock()
+		So(bodies, ShouldHaveLength, 2)
+		So(headers.Get("Authorization"), ShouldEqual, "Bearer sesame")
+		req := otlpRequest{}
+		So(json.Unmarshal(bodies[0], &req), ShouldBeNil)
+		rec := req.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+		So(rec.SeverityNumber, ShouldEqual, 10)
+		So(attrValue(rec.Attributes, "env"), ShouldEqual, "prod")
+
+		f, _ := os.Open(filePath)
+		defer f.Close()
+		lines := 0
+		for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+			So(scanner.Text(), ShouldContainSubstring, `"severityNumber":17`)
+		}
+		So(lines, ShouldEqual, 1)
+	})
+
+	Convey("a failing collector should be reported", t, func() {
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "no logs today", http.StatusServiceUnavailable)
+		}))
+		defer collector.Close()
+		exporter, err := slogger.NewOTLPHTTPExporter(collector.URL+"/custom/logs", nil)
+		So(err, ShouldBeNil)
+		So(exporter.Endpoint, ShouldEndWith, "/custom/logs")
+		So(exporter.Export(context.Background(), []byte("{}")), ShouldNotBeNil)
+		_, err = slogger.NewOTLPHTTPExporter("ftp://example.com", nil)
+		So(err, ShouldNotBeNil)
+	})
+}
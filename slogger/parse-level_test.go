@@ -0,0 +1,34 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseLevel(t *testing.T) {
+
+	Convey("ParseLevel should understand config level names", t, func() {
+		tests := map[string]slog.Level{
+			"trace":   slogger.LevelTrace,
+			"DEBUG":   slogger.LevelDebug,
+			"info":    slogger.LevelInfo,
+			"success": slogger.LevelSuccess,
+			"wrn":     slogger.LevelWarn,
+			"error":   slogger.LevelError,
+			"-4":      slogger.LevelDebug,
+		}
+		for name, want := range tests {
+			got, err := slogger.ParseLevel(name)
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, want)
+		}
+		_, err := slogger.ParseLevel("loud")
+		So(err, ShouldNotBeNil)
+	})
+}
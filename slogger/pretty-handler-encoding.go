@@ -18,6 +18,9 @@ func (e encoder) NewLine(buf *buffer) {
 }
 
 func (e encoder) ColorOff(buf *buffer) {
+	if e.opts.NoColor {
+		return
+	}
 	buf.AppendString("\x1b[0m")
 }
 
@@ -0,0 +1,252 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mrmxf/clog/crayon"
+)
+
+// ColorMode is the colour policy for pretty output
+type ColorMode int
+
+const (
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+// the policy & theme used by the pretty loggers
+var colorMode = ColorAuto
+var prettyTheme Theme
+
+// ParseColorMode converts clog.log.color (auto | always | never) into a mode
+func ParseColorMode(name string) (ColorMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return ColorAuto, nil
+	case "always", "on", "true":
+		return ColorAlways, nil
+	case "never", "off", "false":
+		return ColorNever, nil
+	}
+	return ColorAuto, fmt.Errorf("unknown color mode (%s) use auto | always | never", name)
+}
+
+// SetColorMode sets the colour policy for loggers created afterwards
+func SetColorMode(mode ColorMode) {
+	colorMode = mode
+}
+
+// SetTheme sets the theme for pretty loggers created afterwards. nil selects
+// the default light or dark theme.
+func SetTheme(t Theme) {
+	prettyTheme = t
+}
+
+// ColorEnabled decides if colour should be written to f:
+//
+//  1. NO_COLOR (any value) turns colour off
+//  2. FORCE_COLOR or CLICOLOR_FORCE (not 0) turn colour on
+//  3. clog.log.color always | never
+//  4. CLOG_COLOR always | never (set by a parent clog - see InheritColor)
+//  5. CLICOLOR=0 turns colour off
+//  6. otherwise colour is on if f is a terminal
+func ColorEnabled(f *os.File) bool {
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	for _, key := range []string{"FORCE_COLOR", "CLICOLOR_FORCE"} {
+		if v := os.Getenv(key); len(v) > 0 && v != "0" && v != "false" {
+			return true
+		}
+	}
+	switch colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if mode, err := ParseColorMode(os.Getenv("CLOG_COLOR")); err == nil && mode != ColorAuto {
+		return mode == ColorAlways
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return false
+	}
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// InheritColor passes the colour decision for stderr to child processes so
+// that `clog Log` in a script run by clog (whose stderr is a pipe) matches
+// the parent. Only clog reads CLOG_COLOR so other tools are not affected.
+func InheritColor() {
+	if len(os.Getenv("CLOG_COLOR")) > 0 {
+		return
+	}
+	if ColorEnabled(os.Stderr) {
+		os.Setenv("CLOG_COLOR", "always")
+	} else {
+		os.Setenv("CLOG_COLOR", "never")
+	}
+}
+
+// CurrentTheme is the configured theme or the default for the terminal's
+// light or dark background
+func CurrentTheme() Theme {
+	if prettyTheme != nil {
+		return prettyTheme
+	}
+	if crayon.DetectDarkMode() {
+		return NewBrightTheme()
+	}
+	return NewDefaultTheme()
+}
+
+// ThemeConfig is the clog.log.theme config. The keys of the colour maps are
+// the ThemeDef fields in kebab case (e.g. level-info, attr-key) and the values
+// are crayon roles, ANSI names, SGR codes or hex colours:
+//
+//	clog:
+//	  log:
+//	    theme:
+//	      mode: auto                      # auto | light | dark
+//	      base: default                   # default | bright
+//	      colors: {attr-key: url, level-info: "bold #ffd700"}
+//	      dark:   {message: white}
+//	      light:  {message: "30"}
+type ThemeConfig struct {
+	Mode   string            `json:"mode"`
+	Base   string            `json:"base"`
+	Colors map[string]string `json:"colors"`
+	Light  map[string]string `json:"light"`
+	Dark   map[string]string `json:"dark"`
+}
+
+// NewConfigTheme builds a theme from the config. Light themes start from the
+// default theme and dark themes from the bright theme unless base is set.
+func NewConfigTheme(c ThemeConfig) (Theme, error) {
+	dark := false
+	switch strings.ToLower(c.Mode) {
+	case "", "auto":
+		dark = crayon.DetectDarkMode()
+	case "dark":
+		dark = true
+	case "light":
+	default:
+		return nil, fmt.Errorf("unknown theme mode (%s) use auto | light | dark", c.Mode)
+	}
+	var t ThemeDef
+	switch strings.ToLower(c.Base) {
+	case "":
+		if dark {
+			t = NewBrightTheme().(ThemeDef)
+		} else {
+			t = NewDefaultTheme().(ThemeDef)
+		}
+	case "default":
+		t = NewDefaultTheme().(ThemeDef)
+	case "bright":
+		t = NewBrightTheme().(ThemeDef)
+	default:
+		return nil, fmt.Errorf("unknown theme base (%s) use default | bright", c.Base)
+	}
+	t.name = "Config"
+	overrides := []map[string]string{c.Colors, c.Light}
+	if dark {
+		overrides[1] = c.Dark
+	}
+	for _, o := range overrides {
+		for key, spec := range o {
+			if err := t.set(key, spec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
+
+// set one field of the theme from a colour spec
+func (t *ThemeDef) set(key string, spec string) error {
+	fields := map[string]*ANSIMod{
+		"timestamp":        &t.timestamp,
+		"source":           &t.source,
+		"message":          &t.message,
+		"message-debug":    &t.messageDebug,
+		"attr-key":         &t.attrKey,
+		"attr-value":       &t.attrValue,
+		"attr-value-error": &t.attrValueError,
+		"level-emergency":  &t.levelEmergency,
+		"level-fatal":      &t.levelFatal,
+		"level-error":      &t.levelError,
+		"level-warn":       &t.levelWarn,
+		"level-success":    &t.levelSuccess,
+		"level-info":       &t.levelInfo,
+		"level-debug":      &t.levelDebug,
+		"level-trace":      &t.levelTrace,
+	}
+	field, ok := fields[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("unknown theme key (%s)", key)
+	}
+	mod, err := ParseColor(spec)
+	if err != nil {
+		return fmt.Errorf("theme %s: %s", key, err.Error())
+	}
+	*field = mod
+	return nil
+}
+
+// ANSI names that can be used in a colour spec
+var ansiNames = map[string]int{
+	"bold": Bold, "faint": Faint, "italic": Italic, "underline": Underline, "crossed-out": CrossedOut,
+	"black": Black, "red": Red, "green": Green, "yellow": Yellow,
+	"blue": Blue, "magenta": Magenta, "cyan": Cyan, "gray": Gray,
+	"bright-black": BrightBlack, "bright-red": BrightRed, "bright-green": BrightGreen,
+	"bright-yellow": BrightYellow, "bright-blue": BrightBlue, "bright-magenta": BrightMagenta,
+	"bright-cyan": BrightCyan, "white": White,
+}
+
+var reHexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{6})$`)
+var reSGR = regexp.MustCompile(`^[0-9]+(;[0-9]+)*$`)
+
+// ParseColor converts a space separated colour spec into an ANSI escape. Each
+// word may be a crayon role (info), an ANSI name (bold, bright-red), SGR
+// codes (1;31) or a hex colour (#ff8800). "none" or "" is no colour.
+func ParseColor(spec string) (ANSIMod, error) {
+	codes := []string{}
+	for _, word := range strings.Fields(spec) {
+		lower := strings.ToLower(word)
+		switch {
+		case lower == "none" || lower == "plain":
+		case reHexColor.MatchString(word):
+			rgb, _ := strconv.ParseUint(reHexColor.FindStringSubmatch(word)[1], 16, 32)
+			codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", rgb>>16, (rgb>>8)&0xff, rgb&0xff))
+		case reSGR.MatchString(word):
+			codes = append(codes, word)
+		default:
+			if code, ok := ansiNames[lower]; ok {
+				codes = append(codes, strconv.Itoa(code))
+			} else if role, ok := crayon.Role(lower); ok {
+				if len(role) > 0 {
+					codes = append(codes, strings.TrimSuffix(strings.TrimPrefix(role, "\x1b["), "m"))
+				}
+			} else {
+				return "", fmt.Errorf("unknown colour (%s)", word)
+			}
+		}
+	}
+	if len(codes) == 0 {
+		return "", nil
+	}
+	return ANSIMod("\x1b[" + strings.Join(codes, ";") + "m"), nil
+}
@@ -0,0 +1,78 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestThemeConfig(t *testing.T) {
+
+	Convey("colour specs should parse", t, func() {
+		tests := map[string]slogger.ANSIMod{
+			"":                 "",
+			"none":             "",
+			"bold red":         "\x1b[1;31m",
+			"bright-cyan":      "\x1b[96m",
+			"1;33":             "\x1b[1;33m",
+			"#ff8800":          "\x1b[38;2;255;136;0m",
+			"underline 0000FF": "\x1b[4;38;2;0;0;255m",
+		}
+		for spec, want := range tests {
+			got, err := slogger.ParseColor(spec)
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, want)
+		}
+		_, err := slogger.ParseColor("purple")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("config themes should override the light or dark base", t, func() {
+		t.Setenv("CLOG_DARKMODE", "")
+		t.Setenv("COLORFGBG", "")
+		theme, err := slogger.NewConfigTheme(slogger.ThemeConfig{
+			Mode:   "dark",
+			Colors: map[string]string{"level-info": "bold"},
+			Dark:   map[string]string{"message": "red"},
+			Light:  map[string]string{"message": "blue"},
+		})
+		So(err, ShouldBeNil)
+		So(theme.LevelInfo(), ShouldEqual, slogger.ANSIMod("\x1b[1m"))
+		So(theme.Message(), ShouldEqual, slogger.ANSIMod("\x1b[31m"))
+		So(theme.AttrKey(), ShouldEqual, slogger.NewBrightTheme().AttrKey())
+
+		theme, err = slogger.NewConfigTheme(slogger.ThemeConfig{Light: map[string]string{"message": "blue"}})
+		So(err, ShouldBeNil)
+		So(theme.Message(), ShouldEqual, slogger.ANSIMod("\x1b[34m"))
+		So(theme.AttrKey(), ShouldEqual, slogger.NewDefaultTheme().AttrKey())
+
+		_, err = slogger.NewConfigTheme(slogger.ThemeConfig{Colors: map[string]string{"banner": "red"}})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("the colour policy should follow the standard env vars", t, func() {
+		for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE", "CLOG_COLOR", "CLICOLOR"} {
+			t.Setenv(key, "")
+		}
+		file, err := os.Create(t.TempDir() + "/log.txt")
+		So(err, ShouldBeNil)
+		defer file.Close()
+		So(slogger.ColorEnabled(file), ShouldBeFalse) // files are not terminals
+		t.Setenv("FORCE_COLOR", "1")
+		So(slogger.ColorEnabled(nil), ShouldBeTrue)
+		t.Setenv("NO_COLOR", "1")
+		So(slogger.ColorEnabled(nil), ShouldBeFalse)
+		t.Setenv("NO_COLOR", "")
+		t.Setenv("FORCE_COLOR", "")
+		t.Setenv("CLOG_COLOR", "always")
+		So(slogger.ColorEnabled(nil), ShouldBeTrue)
+		slogger.SetColorMode(slogger.ColorNever)
+		So(slogger.ColorEnabled(nil), ShouldBeFalse)
+		slogger.SetColorMode(slogger.ColorAuto)
+	})
+}
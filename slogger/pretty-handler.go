@@ -101,7 +101,7 @@ func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
 	h.enc.writeMessage(buf, rec.Level, rec.Message)
 	buf.copy(&h.context)
 	rec.Attrs(func(a slog.Attr) bool {
-		h.enc.writeAttr(buf, a, h.group)
+		h.writeAttr(buf, a, h.group)
 		return true
 	})
 	h.enc.ColorOff(buf)
@@ -119,7 +119,7 @@ func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	newCtx := h.context
 	for _, a := range attrs {
-		h.enc.writeAttr(&newCtx, a, h.group)
+		h.writeAttr(&newCtx, a, h.group)
 	}
 	newCtx.Clip()
 	return &Handler{
@@ -131,6 +131,18 @@ func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	}
 }
 
+// writeAttr writes an attribute. The run is for structured sinks - it would
+// clutter the console so only its profile (e.g. profile=prod) is shown.
+func (h *Handler) writeAttr(buf *buffer, a slog.Attr, group string) {
+	if !isRunAttr(a) {
+		h.enc.writeAttr(buf, a, group)
+		return
+	}
+	if profile := a.Value.Any().(RunInfo).Profile; len(profile) > 0 {
+		h.enc.writeAttr(buf, slog.String("profile", profile), "")
+	}
+}
+
 // WithGroup implements slog.Handler.
 func (h *Handler) WithGroup(name string) slog.Handler {
 	name = strings.TrimSpace(name)
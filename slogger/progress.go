@@ -0,0 +1,213 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress is a bar (if there is a total) or a spinner for a long running
+// step. On an interactive terminal it is redrawn in place on stderr. In CI or
+// when stderr is not a terminal it logs a plain line when it starts, at most
+// every ProgressLogInterval while it runs and when it is done.
+//
+//	p := slogger.NewProgress("Uploading", 120)
+//	for range files {
+//		p.Add(1)
+//	}
+//	p.Done()
+//
+// The exported fields are the state so that a progress can be saved & loaded
+// by separate processes e.g. clog Progress start, tick & done.
+type Progress struct {
+	Name    string    `json:"name"`
+	Total   int64     `json:"total"` // 0 for a spinner
+	Current int64     `json:"current"`
+	Start   time.Time `json:"start"`
+	Frame   int       `json:"frame"`  // the spinner frame
+	Logged  time.Time `json:"logged"` // the last plain line
+	mu      sync.Mutex
+	out     io.Writer
+	live    bool
+	drawn   time.Time // the last live redraw
+}
+
+// the least time between the plain lines of a progress that is not live
+var ProgressLogInterval = 10 * time.Second
+
+// the least time between live redraws in a process
+var progressRedrawInterval = 50 * time.Millisecond
+
+// the number of characters in a bar
+var ProgressWidth = 30
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// NewProgress starts a progress on stderr. A total of 0 shows a spinner.
+func NewProgress(name string, total int64) *Progress {
+	return NewProgressTo(os.Stderr, ProgressLive(os.Stderr), name, total)
+}
+
+// NewProgressTo starts a progress that is drawn on w if live is true,
+// otherwise it is logged
+func NewProgressTo(w io.Writer, live bool, name string, total int64) *Progress {
+	p := &Progress{Name: name, Total: total, Start: time.Now(), out: w, live: live}
+	p.begin()
+	return p
+}
+
+// TTYEnv tells a clog started by clog if the stderr of the top level clog is
+// a terminal (1 or 0). The stderr of a nested clog is a pipe that its parent
+// copies to the terminal (e.g. a script run by clog) so it cannot tell.
+const TTYEnv = "CLOG_TTY"
+
+// ExportTTY sets TTYEnv for the processes started by this clog unless it was
+// set by a parent clog
+func ExportTTY() {
+	if _, set := os.LookupEnv(TTYEnv); set {
+		return
+	}
+	tty := "0"
+	if isTerminal(os.Stderr) {
+		tty = "1"
+	}
+	os.Setenv(TTYEnv, tty)
+}
+
+// ProgressLive is true if a progress can be redrawn in place on f i.e. f is
+// a terminal, clog is not in CI and TERM is not dumb. For stderr, TTYEnv (if
+// set) says if it ends up on a terminal.
+func ProgressLive(f *os.File) bool {
+	if f == nil || DetectCI() != CINone || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if tty := os.Getenv(TTYEnv); f == os.Stderr && len(tty) > 0 {
+		return tty == "1"
+	}
+	return isTerminal(f)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetOutput sets where a loaded progress is drawn (default stderr)
+func (p *Progress) SetOutput(w io.Writer, live bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out, p.live = w, live
+}
+
+// output sets the default output of a new or loaded progress. p must be
+// locked.
+func (p *Progress) output() {
+	if p.out == nil {
+		p.out, p.live = os.Stderr, ProgressLive(os.Stderr)
+	}
+}
+
+// begin draws or logs the start of a new progress
+func (p *Progress) begin() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.output()
+	if p.live {
+		p.draw(true)
+		return
+	}
+	p.Logged = time.Now()
+	if p.Total > 0 {
+		Info(p.Name, "total", p.Total)
+	} else {
+		Info(p.Name)
+	}
+}
+
+// Add moves the progress on by n
+func (p *Progress) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.update(p.Current + n)
+}
+
+// Set moves the progress to n
+func (p *Progress) Set(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.update(n)
+}
+
+// update redraws a live progress or logs a plain line if it is time to.
+// p must be locked.
+func (p *Progress) update(n int64) {
+	p.output()
+	p.Current = n
+	p.Frame = (p.Frame + 1) % len(spinnerFrames)
+	if p.live {
+		p.draw(p.Total > 0 && n >= p.Total)
+		return
+	}
+	if time.Since(p.Logged) < ProgressLogInterval {
+		return
+	}
+	p.Logged = time.Now()
+	if p.Total > 0 {
+		Info(p.Name, "progress", fmt.Sprintf("%d%%", p.percent()), "count", fmt.Sprintf("%d/%d", p.Current, p.Total))
+	} else {
+		Info(p.Name, "count", p.Current, "elapsed", p.elapsed())
+	}
+}
+
+// Done clears a live progress and logs the count & duration
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.output()
+	if p.live {
+		io.WriteString(p.out, "\r\033[2K")
+	}
+	if p.Current > 0 {
+		Success(p.Name+" done", "count", p.Current, "duration", p.elapsed())
+	} else {
+		Success(p.Name+" done", "duration", p.elapsed())
+	}
+}
+
+// draw overwrites the line on a live output. p must be locked.
+func (p *Progress) draw(force bool) {
+	if !force && time.Since(p.drawn) < progressRedrawInterval {
+		return
+	}
+	p.drawn = time.Now()
+	io.WriteString(p.out, "\r\033[2K"+p.String())
+}
+
+// String is the text of the bar or spinner without colour e.g.
+//
+//	Uploading [███████████████···············]  50% 60/120 12s
+func (p *Progress) String() string {
+	if p.Total <= 0 {
+		return fmt.Sprintf("%s %s %d %s", spinnerFrames[p.Frame%len(spinnerFrames)], p.Name, p.Current, p.elapsed())
+	}
+	filled := int(int64(ProgressWidth) * min(p.Current, p.Total) / p.Total)
+	bar := strings.Repeat("█", filled) + strings.Repeat("·", ProgressWidth-filled)
+	return fmt.Sprintf("%s [%s] %3d%% %d/%d %s", p.Name, bar, p.percent(), p.Current, p.Total, p.elapsed())
+}
+
+func (p *Progress) percent() int64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return min(100, max(0, p.Current*100/p.Total))
+}
+
+func (p *Progress) elapsed() time.Duration {
+	return time.Since(p.Start).Round(time.Second)
+}
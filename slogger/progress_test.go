@@ -0,0 +1,97 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProgress(t *testing.T) {
+	console := slog.Default()
+	defer slog.SetDefault(console)
+	logged := &bytes.Buffer{}
+	slog.SetDefault(slog.New(slogger.NewPrettyHandler(logged, &slogger.PrettyHandlerOptions{NoColor: true})))
+
+	Convey("a live bar should be redrawn in place", t, func() {
+		logged.Reset()
+		out := &bytes.Buffer{}
+		p := slogger.NewProgressTo(out, true, "Uploading", 4)
+		p.Add(2)
+		So(p.String(), ShouldContainSubstring, " 50% 2/4 ")
+		So(p.String(), ShouldContainSubstring, strings.Repeat("█", slogger.ProgressWidth/2)+"·")
+		p.Set(4)
+		So(out.String(), ShouldStartWith, "\r\033[2KUploading [")
+		So(out.String(), ShouldContainSubstring, "100% 4/4")
+		So(logged.String(), ShouldBeEmpty)
+
+		p.Done()
+		So(out.String(), ShouldEndWith, "\r\033[2K")
+		So(logged.String(), ShouldContainSubstring, "Uploading done count=4")
+	})
+
+	Convey("a nested clog should be live if the top level clog is on a terminal", t, func() {
+		t.Setenv("GITHUB_ACTIONS", "")
+		t.Setenv("GITLAB_CI", "")
+		t.Setenv("TERM", "xterm")
+		t.Setenv(slogger.TTYEnv, "1")
+		So(slogger.ProgressLive(os.Stderr), ShouldBeTrue)
+		slogger.ExportTTY()
+		So(os.Getenv(slogger.TTYEnv), ShouldEqual, "1")
+		t.Setenv(slogger.TTYEnv, "0")
+		So(slogger.ProgressLive(os.Stderr), ShouldBeFalse)
+		t.Setenv("TERM", "dumb")
+		t.Setenv(slogger.TTYEnv, "1")
+		So(slogger.ProgressLive(os.Stderr), ShouldBeFalse)
+	})
+
+	Convey("a redraw should not be held back by a redact writer", t, func() {
+		r := slogger.DefaultRedactor()
+		defer r.Reset()
+		So(r.AddPatterns("jwt", "bearer", "github-token"), ShouldBeNil)
+		out := &bytes.Buffer{}
+		p := slogger.NewProgressTo(slogger.NewRedactWriter(out), true, "Uploading", 3)
+		So(out.String(), ShouldEndWith, "  0% 0/3 0s")
+		p.Add(3)
+		So(out.String(), ShouldEndWith, "100% 3/3 0s")
+	})
+
+	Convey("a progress that is not live should log plain lines", t, func() {
+		logged.Reset()
+		interval := slogger.ProgressLogInterval
+		defer func() { slogger.ProgressLogInterval = interval }()
+		out := &bytes.Buffer{}
+		p := slogger.NewProgressTo(out, false, "Waiting", 0)
+		p.Add(1)
+		So(strings.Count(logged.String(), "\n"), ShouldEqual, 1)
+		slogger.ProgressLogInterval = 0
+		p.Add(1)
+		So(logged.String(), ShouldContainSubstring, "Waiting count=2")
+		So(out.String(), ShouldBeEmpty)
+	})
+
+	Convey("the state should survive a save & load", t, func() {
+		logged.Reset()
+		p := slogger.NewProgressTo(&bytes.Buffer{}, false, "Copying", 10)
+		p.Add(3)
+		body, err := json.Marshal(p)
+		So(err, ShouldBeNil)
+		loaded := &slogger.Progress{}
+		So(json.Unmarshal(body, loaded), ShouldBeNil)
+		So(loaded.Current, ShouldEqual, 3)
+		So(time.Since(loaded.Start), ShouldBeLessThan, time.Minute)
+		out := &bytes.Buffer{}
+		loaded.SetOutput(out, true)
+		loaded.Add(2)
+		So(out.String(), ShouldContainSubstring, " 50% 5/10 ")
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RedactHandler masks secrets in the message & attributes of every record
+// before passing it on. The slogger loggers are always wrapped in one so
+// that every style & sink is redacted. LogValuers are passed on unresolved
+// so that handlers that recognise them (e.g. the job handler) still can.
+type RedactHandler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+var _ slog.Handler = (*RedactHandler)(nil)
+
+// NewRedactHandler wraps h with the default redactor. A RedactHandler is
+// returned unchanged.
+func NewRedactHandler(h slog.Handler) *RedactHandler {
+	if rh, ok := h.(*RedactHandler); ok {
+		return rh
+	}
+	return &RedactHandler{next: h, r: redactor}
+}
+
+// Unwrap returns the handler that records are passed to
+func (h *RedactHandler) Unwrap() slog.Handler {
+	return h.next
+}
+
+// Enabled implements slog.Handler.
+func (h *RedactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+// Handle implements slog.Handler.
+func (h *RedactHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if !h.r.Active() {
+		return h.next.Handle(ctx, rec)
+	}
+	r2 := slog.NewRecord(rec.Time, rec.Level, h.r.Redact(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		r2.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, r2)
+}
+
+// redactAttr masks the secrets in string values, errors & groups
+func (h *RedactHandler) redactAttr(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(h.r.Redact(a.Value.String()))
+	case slog.KindGroup:
+		attrs := []slog.Attr{}
+		for _, ga := range a.Value.Group() {
+			attrs = append(attrs, h.redactAttr(ga))
+		}
+		a.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			if msg := h.r.Redact(err.Error()); msg != err.Error() {
+				a.Value = slog.StringValue(msg)
+			}
+		}
+	}
+	return a
+}
+
+// WithAttrs implements slog.Handler.
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, h.redactAttr(a))
+	}
+	return &RedactHandler{next: h.next.WithAttrs(redacted), r: h.r}
+}
+
+// WithGroup implements slog.Handler.
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name), r: h.r}
+}
@@ -0,0 +1,76 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+// partialRegexp compiles a regexp that matches the end of some output if it
+// could be the start of a match of expr. A RedactWriter holds back the end
+// of a partial line from there and writes the rest e.g. a prompt.
+func partialRegexp(expr string) (*regexp.Regexp, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	tail := &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{
+		prefixOf(re.Simplify()),
+		{Op: syntax.OpEndText},
+	}}
+	return regexp.Compile(tail.String())
+}
+
+// prefixOf returns a regexp that matches every prefix of a match of re. The
+// assertions at the end of a prefix (e.g. \b) are dropped because the output
+// that follows is not known yet.
+func prefixOf(re *syntax.Regexp) *syntax.Regexp {
+	empty := &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return re
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return empty
+	case syntax.OpLiteral:
+		if len(re.Rune) == 1 {
+			return &syntax.Regexp{Op: syntax.OpQuest, Sub: []*syntax.Regexp{re}}
+		}
+		// a longer literal is a sequence of single rune literals
+		subs := []*syntax.Regexp{}
+		for _, r := range re.Rune {
+			subs = append(subs, &syntax.Regexp{Op: syntax.OpLiteral, Flags: re.Flags, Rune: []rune{r}})
+		}
+		return prefixOfConcat(subs)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return &syntax.Regexp{Op: syntax.OpQuest, Sub: []*syntax.Regexp{re}}
+	case syntax.OpCapture, syntax.OpQuest:
+		return prefixOf(re.Sub[0])
+	case syntax.OpConcat:
+		return prefixOfConcat(re.Sub)
+	case syntax.OpAlternate:
+		alt := &syntax.Regexp{Op: syntax.OpAlternate}
+		for _, sub := range re.Sub {
+			alt.Sub = append(alt.Sub, prefixOf(sub))
+		}
+		return alt
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		// any number of whole repeats then the start of one more
+		star := &syntax.Regexp{Op: syntax.OpStar, Sub: re.Sub[:1]}
+		return &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{star, prefixOf(re.Sub[0])}}
+	}
+	// anything else could be the start of a match
+	return &syntax.Regexp{Op: syntax.OpStar, Flags: syntax.DotNL, Sub: []*syntax.Regexp{{Op: syntax.OpAnyChar}}}
+}
+
+// the prefixes of a b c are the prefixes of a or a followed by the prefixes
+// of b c
+func prefixOfConcat(subs []*syntax.Regexp) *syntax.Regexp {
+	if len(subs) == 0 {
+		return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+	}
+	rest := &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{subs[0], prefixOfConcat(subs[1:])}}
+	return &syntax.Regexp{Op: syntax.OpAlternate, Sub: []*syntax.Regexp{prefixOf(subs[0]), rest}}
+}
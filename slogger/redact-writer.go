@@ -0,0 +1,120 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// RedactWriter masks secrets in streamed output e.g. the stdout of a script.
+// A secret may be split across writes so the writer holds back:
+//
+//   - the end of the output if it could be the start of a literal secret
+//   - the end of an incomplete line if it could be the start of a pattern
+//   - an open multi-line block (e.g. a PEM key) until it is closed
+//
+// Held back output is written when it is safe, when more than
+// RedactMaxHold bytes are held or when Flush is called.
+type RedactWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	r       *Redactor
+	pending string
+}
+
+// the most output that is held back waiting for a line or a block to end
+var RedactMaxHold = 64 * 1024
+
+// NewRedactWriter wraps out with the default redactor
+func NewRedactWriter(out io.Writer) *RedactWriter {
+	return &RedactWriter{out: out, r: redactor}
+}
+
+// Write implements io.Writer. The length of p is returned on success even if
+// some of it is held back.
+func (w *RedactWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 && !w.r.Active() {
+		return w.out.Write(p)
+	}
+	w.pending += string(p)
+	w.r.mu.RLock()
+	cut := w.r.safeCut(w.pending)
+	safe := w.r.redact(w.pending[:cut])
+	w.r.mu.RUnlock()
+	w.pending = w.pending[cut:]
+	if len(safe) > 0 {
+		if _, err := io.WriteString(w.out, safe); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush redacts & writes any held back output. It does not close the
+// underlying writer.
+func (w *RedactWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return nil
+	}
+	safe := w.r.Redact(w.pending)
+	w.pending = ""
+	_, err := io.WriteString(w.out, safe)
+	return err
+}
+
+// safeCut returns the length of the start of s that can be redacted & written
+// without splitting a secret. The redactor must be read locked.
+func (r *Redactor) safeCut(s string) int {
+	cut := len(s)
+	// patterns match within lines so only the partial line can be the start
+	// of a match. A \r starts a new line too so that redraws (e.g. of a
+	// progress bar) are not held back.
+	line := strings.LastIndexAny(s, "\r\n") + 1
+	for _, p := range r.patterns {
+		if loc := p.partial.FindStringIndex(s[line:]); loc[0] < len(s)-line {
+			cut = min(cut, line+loc[0])
+		}
+	}
+	for _, p := range r.patterns {
+		if p.open == nil {
+			continue
+		}
+		opens := p.open.FindAllStringIndex(s, -1)
+		if len(opens) == 0 {
+			continue
+		}
+		if i := opens[len(opens)-1][0]; i < cut && !p.re.MatchString(s[i:]) {
+			cut = i
+		}
+	}
+	if len(s)-cut > RedactMaxHold {
+		cut = len(s)
+	}
+	// the end of s could be the start of a literal
+	for _, lit := range r.literals {
+		for k := min(len(lit)-1, len(s)); k > 0; k-- {
+			if strings.HasSuffix(s, lit[:k]) {
+				cut = min(cut, len(s)-k)
+				break
+			}
+		}
+	}
+	// never cut through a secret that is already complete
+	for moved := true; moved; {
+		moved = false
+		for _, m := range r.matches(s) {
+			if m[0] < cut && cut < m[1] {
+				cut = m[0]
+				moved = true
+			}
+		}
+	}
+	return cut
+}
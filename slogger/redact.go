@@ -0,0 +1,209 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redactor masks secret values & patterns in log records and captured
+// output. Literal secrets are the values of secret env vars and values that
+// are marked secret. Patterns match well known credential formats.
+type Redactor struct {
+	mu       sync.RWMutex
+	mask     string
+	minLen   int
+	literals []string // longest first so that overlapping secrets are masked
+	patterns []redactPattern
+}
+
+// redactPattern is a compiled pattern. If open is set then streamed output
+// is held back from an open marker until the pattern can match e.g. from
+// the BEGIN line of a PEM block to its END line. partial finds the end of a
+// partial line that could be the start of a match (see partialRegexp).
+type redactPattern struct {
+	name    string
+	re      *regexp.Regexp
+	open    *regexp.Regexp
+	partial *regexp.Regexp
+}
+
+// RedactPatterns are the built in patterns that can be named in
+// clog.redact.patterns
+var RedactPatterns = map[string]string{
+	"aws-access-key": `\b(AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[0-9A-Z]{16}\b`,
+	"jwt":            `\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+	"pem":            `(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----.*?-----END [A-Z0-9 ]*PRIVATE KEY-----`,
+	"github-token":   `\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	"gitlab-token":   `\bglpat-[A-Za-z0-9_-]{20,}`,
+	"bearer":         `(?i)\bbearer\s+[A-Za-z0-9._~+/-]{16,}=*`,
+}
+
+// the open markers of the built in multi-line patterns
+var redactPatternOpen = map[string]*regexp.Regexp{
+	"pem": regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`),
+}
+
+// the mask written in place of a secret
+const DefaultRedactMask = "[REDACTED]"
+
+// the shortest literal that is treated as a secret. Shorter values would
+// mask ordinary words in the output.
+const DefaultRedactMinLength = 4
+
+// NewRedactor creates an empty redactor
+func NewRedactor() *Redactor {
+	return &Redactor{mask: DefaultRedactMask, minLen: DefaultRedactMinLength}
+}
+
+// the redactor used by the default logger, NewRedactHandler & NewRedactWriter
+var redactor = NewRedactor()
+
+// DefaultRedactor returns the redactor of the default logger
+func DefaultRedactor() *Redactor {
+	return redactor
+}
+
+// MarkSecret adds literal secrets to the default redactor
+func MarkSecret(values ...string) {
+	redactor.AddSecrets(values...)
+}
+
+// Redact masks the secrets in s with the default redactor
+func Redact(s string) string {
+	return redactor.Redact(s)
+}
+
+// SetMask sets the text written in place of a secret and the shortest
+// literal that is treated as a secret
+func (r *Redactor) SetMask(mask string, minLength int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mask = mask
+	r.minLen = max(minLength, 1)
+}
+
+// AddSecrets adds literal secrets. Empty values & values shorter than the
+// minimum length are ignored.
+func (r *Redactor) AddSecrets(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < r.minLen || slices.Contains(r.literals, v) {
+			continue
+		}
+		r.literals = append(r.literals, v)
+	}
+	sort.SliceStable(r.literals, func(i, j int) bool { return len(r.literals[i]) > len(r.literals[j]) })
+}
+
+// AddPatterns adds patterns. Each one is the name of a built in pattern (see
+// RedactPatterns) or a regular expression.
+func (r *Redactor) AddPatterns(patterns ...string) error {
+	compiled := []redactPattern{}
+	for _, p := range patterns {
+		expr, builtin := RedactPatterns[p]
+		if !builtin {
+			expr = p
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("bad redact pattern (%s): %w", p, err)
+		}
+		partial, err := partialRegexp(expr)
+		if err != nil {
+			return fmt.Errorf("bad redact pattern (%s): %w", p, err)
+		}
+		compiled = append(compiled, redactPattern{name: p, re: re, open: redactPatternOpen[p], partial: partial})
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = append(r.patterns, compiled...)
+	return nil
+}
+
+// Reset removes all the secrets & patterns
+func (r *Redactor) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.literals = nil
+	r.patterns = nil
+}
+
+// Active is true if there is anything to redact
+func (r *Redactor) Active() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.literals) > 0 || len(r.patterns) > 0
+}
+
+// Mask returns the text written in place of a secret
+func (r *Redactor) Mask() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mask
+}
+
+// Redact returns s with every secret replaced by the mask
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.redact(s)
+}
+
+func (r *Redactor) redact(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	for _, lit := range r.literals {
+		s = strings.ReplaceAll(s, lit, r.mask)
+	}
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
+
+// matches returns the [start, end) ranges of all the secrets in s
+func (r *Redactor) matches(s string) [][2]int {
+	found := [][2]int{}
+	for _, lit := range r.literals {
+		for i := 0; ; {
+			j := strings.Index(s[i:], lit)
+			if j < 0 {
+				break
+			}
+			found = append(found, [2]int{i + j, i + j + len(lit)})
+			i += j + len(lit)
+		}
+	}
+	for _, p := range r.patterns {
+		for _, loc := range p.re.FindAllStringIndex(s, -1) {
+			found = append(found, [2]int{loc[0], loc[1]})
+		}
+	}
+	return found
+}
+
+// Secret is a string that is always masked when it is logged
+//
+//	slog.Info("login", "token", slogger.Secret(token))
+type Secret string
+
+// LogValue implements slog.LogValuer.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redactor.Mask())
+}
+
+// String implements fmt.Stringer so that a Secret is masked by fmt too.
+func (s Secret) String() string {
+	return redactor.Mask()
+}
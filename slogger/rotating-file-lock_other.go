@@ -0,0 +1,12 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+//go:build !unix
+
+package slogger
+
+// lockFile is a no-op where flock is not available. Rotation is then only
+// safe within a single process.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,320 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation controls the rotation & retention of a file sink. It is declared
+// per sink or as a default for all file sinks in clog.yaml:
+//
+//	clog:
+//	  log:
+//	    rotate: {max-size: 10MB, every: daily, compress: true, max-backups: 7, max-age: 30d}
+type Rotation struct {
+	MaxSize    string `json:"max-size"`    // e.g. 512KB, 10MB, 1GB. Empty for no size limit
+	Every      string `json:"every"`       // hourly | daily | weekly. Empty for no time rotation
+	Compress   bool   `json:"compress"`    // gzip rotated files
+	MaxBackups int    `json:"max-backups"` // rotated files to keep. 0 keeps all
+	MaxAge     string `json:"max-age"`     // e.g. 30d, 72h. Empty keeps all
+}
+
+// layout of the timestamp added to rotated file names
+const rotateTimeFormat = "20060102-150405"
+
+// the name of a backup after the base name of the file
+var reBackupSuffix = regexp.MustCompile(`^-\d{8}-\d{6}(-\d+)?\.`)
+
+// RotatingFile is an append-only log file that rotates itself. Several
+// processes (e.g. many short lived `clog Log` calls) may append to the same
+// file: every write takes an exclusive lock on <path>.lock so that only one
+// process rotates and nobody writes to a file while it is being renamed.
+type RotatingFile struct {
+	path     string
+	rotation Rotation
+	maxSize  int64
+	maxAge   time.Duration
+	mu       sync.Mutex
+	file     *os.File
+}
+
+// OpenRotatingFile opens path for appending with the given rotation
+func OpenRotatingFile(path string, rotation Rotation) (*RotatingFile, error) {
+	maxSize, err := ParseSize(rotation.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := ParseAge(rotation.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(rotation.Every) {
+	case "", "hourly", "daily", "weekly":
+	default:
+		return nil, fmt.Errorf("unknown rotation period (%s)", rotation.Every)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f := &RotatingFile{path: path, rotation: rotation, maxSize: maxSize, maxAge: maxAge}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	return nil
+}
+
+// Write implements io.Writer. The file is rotated before the write if it is
+// too big or was last written in an earlier period.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	unlock, err := lockFile(f.path + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err := f.reopenIfMoved(); err != nil {
+		return 0, err
+	}
+	if info, err := f.file.Stat(); err == nil && f.due(info, int64(len(p)), time.Now()) {
+		if err := f.rotate(info.ModTime()); err != nil {
+			return 0, err
+		}
+	}
+	return f.file.Write(p)
+}
+
+// another process may have rotated the file since we opened it
+func (f *RotatingFile) reopenIfMoved() error {
+	current, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	onDisk, err := os.Stat(f.path)
+	if err == nil && os.SameFile(current, onDisk) {
+		return nil
+	}
+	f.file.Close()
+	return f.open()
+}
+
+// due is true if writing n more bytes needs a rotation first
+func (f *RotatingFile) due(info os.FileInfo, n int64, now time.Time) bool {
+	if info.Size() == 0 {
+		return false
+	}
+	if f.maxSize > 0 && info.Size()+n > f.maxSize {
+		return true
+	}
+	if len(f.rotation.Every) > 0 {
+		return periodStart(info.ModTime(), f.rotation.Every) != periodStart(now, f.rotation.Every)
+	}
+	return false
+}
+
+// periodStart truncates t to the start of its rotation period
+func periodStart(t time.Time, every string) time.Time {
+	y, m, d := t.Date()
+	switch strings.ToLower(every) {
+	case "hourly":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case "weekly":
+		return time.Date(y, m, d-int(t.Weekday()), 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// rotate renames the file with the time it was last written, reopens the
+// path then compresses & prunes the backups. The caller holds the lock.
+func (f *RotatingFile) rotate(lastWrite time.Time) error {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext) + "-" + lastWrite.Format(rotateTimeFormat)
+	backup := base + ext
+	for i := 1; fileExists(backup) || fileExists(backup+".gz"); i++ {
+		backup = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	f.file.Close()
+	if err := os.Rename(f.path, backup); err != nil {
+		f.open()
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	if f.rotation.Compress {
+		// a failed compression leaves the uncompressed backup in place
+		if err := gzipFile(backup); err == nil {
+			os.Remove(backup)
+		}
+	}
+	f.prune()
+	return nil
+}
+
+// backups returns the rotated files, newest first
+func (f *RotatingFile) backups() []string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext)
+	matches, _ := filepath.Glob(base + "-*")
+	found := []string{}
+	for _, m := range matches {
+		// only our own backups e.g. clog-20250321-145021.log or .log.gz
+		if !reBackupSuffix.MatchString(strings.TrimPrefix(m, base)) {
+			continue
+		}
+		if strings.HasSuffix(m, ext) || strings.HasSuffix(m, ext+".gz") {
+			found = append(found, m)
+		}
+	}
+	modTime := map[string]time.Time{}
+	for _, b := range found {
+		if info, err := os.Stat(b); err == nil {
+			modTime[b] = info.ModTime()
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if modTime[found[i]].Equal(modTime[found[j]]) {
+			return found[i] > found[j]
+		}
+		return modTime[found[i]].After(modTime[found[j]])
+	})
+	return found
+}
+
+// prune removes backups beyond max-backups or older than max-age
+func (f *RotatingFile) prune() {
+	now := time.Now()
+	for i, b := range f.backups() {
+		tooMany := f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups
+		tooOld := false
+		if info, err := os.Stat(b); err == nil && f.maxAge > 0 {
+			tooOld = now.Sub(info.ModTime()) > f.maxAge
+		}
+		if tooMany || tooOld {
+			os.Remove(b)
+		}
+	}
+}
+
+// Close implements io.Closer
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// gzipFile writes path.gz keeping the modification time of path
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(path + ".gz.tmp")
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(path)
+	zw.ModTime = info.ModTime()
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".gz.tmp")
+		return err
+	}
+	os.Chtimes(path+".gz.tmp", info.ModTime(), info.ModTime())
+	return os.Rename(path+".gz.tmp", path+".gz")
+}
+
+// ParseSize converts sizes like 512KB, 10MB, 1GB or 1048576 into bytes. An
+// empty string is 0 (no limit).
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	if len(s) == 0 {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.bytes
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad size (%s) e.g. 10MB", size)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// ParseAge converts ages like 30d, 2w or any Go duration (72h) into a
+// duration. An empty string is 0 (keep forever).
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return 0, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, found := strings.CutSuffix(s, suffix); found {
+			days, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("bad age (%s) e.g. 30d", s)
+			}
+			return time.Duration(days * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad age (%s) e.g. 30d", s)
+	}
+	return d, nil
+}
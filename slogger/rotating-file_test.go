@@ -0,0 +1,84 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRotatingFile(t *testing.T) {
+
+	Convey("sizes and ages should parse", t, func() {
+		for in, want := range map[string]int64{"": 0, "100": 100, "4KB": 4096, "10MB": 10 << 20, "1.5k": 1536} {
+			got, err := slogger.ParseSize(in)
+			So(err, ShouldBeNil)
+			So(got, ShouldEqual, want)
+		}
+		_, err := slogger.ParseSize("lots")
+		So(err, ShouldNotBeNil)
+		age, err := slogger.ParseAge("2d")
+		So(err, ShouldBeNil)
+		So(age, ShouldEqual, 48*time.Hour)
+		age, err = slogger.ParseAge("90m")
+		So(err, ShouldBeNil)
+		So(age, ShouldEqual, 90*time.Minute)
+	})
+
+	Convey("a file should rotate by size, compress & keep max-backups", t, func() {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "clog.log")
+		// a file with a similar name that must never be pruned
+		other := filepath.Join(dir, "clog-hookhandler.log")
+		So(os.WriteFile(other, []byte("keep"), 0644), ShouldBeNil)
+
+		f, err := slogger.OpenRotatingFile(path, slogger.Rotation{MaxSize: "100", Compress: true, MaxBackups: 2})
+		So(err, ShouldBeNil)
+		line := strings.Repeat("x", 39) + "\n"
+		for i := 0; i < 12; i++ {
+			_, err := f.Write([]byte(line))
+			So(err, ShouldBeNil)
+		}
+		So(f.Close(), ShouldBeNil)
+
+		info, err := os.Stat(path)
+		So(err, ShouldBeNil)
+		So(info.Size(), ShouldBeLessThanOrEqualTo, 100)
+		backups, _ := filepath.Glob(filepath.Join(dir, "clog-2*.log.gz"))
+		So(backups, ShouldHaveLength, 2)
+		_, err = os.Stat(other)
+		So(err, ShouldBeNil)
+
+		gz, _ := os.Open(backups[0])
+		defer gz.Close()
+		zr, err := gzip.NewReader(gz)
+		So(err, ShouldBeNil)
+		body, _ := io.ReadAll(zr)
+		So(string(body), ShouldStartWith, line)
+	})
+
+	Convey("a file rotated by another process should be reopened", t, func() {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "shared.log")
+		a, err := slogger.OpenRotatingFile(path, slogger.Rotation{MaxSize: "50"})
+		So(err, ShouldBeNil)
+		b, err := slogger.OpenRotatingFile(path, slogger.Rotation{MaxSize: "50"})
+		So(err, ShouldBeNil)
+		a.Write([]byte(strings.Repeat("a", 40) + "\n"))
+		a.Write([]byte(strings.Repeat("a", 40) + "\n")) // a rotates
+		b.Write([]byte("b\n"))                          // b follows the rotation
+		a.Close()
+		b.Close()
+		body, _ := os.ReadFile(path)
+		So(string(body), ShouldEqual, strings.Repeat("a", 40)+"\nb\n")
+	})
+}
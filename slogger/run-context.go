@@ -0,0 +1,208 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// the env vars that carry a run into nested clog processes
+const (
+	RunIdEnv  = "CLOG_RUN_ID" // the id shared by every process of a run
+	ParentEnv = "CLOG_PARENT" // the spans of the parent processes e.g. 3f2a9c1e.b7d04e55
+)
+
+// RunKey is the key of the run group added to every record
+const RunKey = "run"
+
+// RunInfo identifies one clog process in a run of nested clog invocations
+// e.g. clog Check (depth 0) running clog Log (depth 1). Every record gets a
+// run group with the values - see RunHandler.
+type RunInfo struct {
+	ID      string // the run id, created by the top level clog
+	Span    string // the id of this process
+	Parent  string // the spans of the parent processes, outermost first
+	Command string // the command path e.g. clog Log
+	Depth   int    // 0 for the top level clog
+	Profile string // the config profile e.g. prod
+}
+
+var run RunInfo
+var runMutex sync.RWMutex
+
+type runContextKey struct{}
+
+// StartRun reads the run from CLOG_RUN_ID & CLOG_PARENT (or starts a new one)
+// and creates the span of this process. The env vars are updated so that any
+// clog started by this process is nested in it.
+func StartRun(command string) RunInfo {
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	run = RunInfo{
+		ID:      os.Getenv(RunIdEnv),
+		Span:    newRunId(4),
+		Parent:  os.Getenv(ParentEnv),
+		Command: command,
+	}
+	if len(run.ID) == 0 {
+		run.ID = newRunId(8)
+		run.Parent = ""
+	}
+	if len(run.Parent) > 0 {
+		run.Depth = strings.Count(run.Parent, ".") + 1
+	}
+	os.Setenv(RunIdEnv, run.ID)
+	os.Setenv(ParentEnv, strings.TrimPrefix(run.Parent+"."+run.Span, "."))
+	return run
+}
+
+// SetRun replaces the run of this process without changing the env vars
+func SetRun(info RunInfo) {
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	run = info
+}
+
+// SetRunCommand sets the command path once the command line has been parsed
+func SetRunCommand(command string) {
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	run.Command = command
+}
+
+// SetRunProfile sets the config profile once the config has been loaded
+func SetRunProfile(profile string) {
+	runMutex.Lock()
+	defer runMutex.Unlock()
+	run.Profile = profile
+}
+
+// CurrentRun returns the run of this process. It is empty before StartRun.
+func CurrentRun() RunInfo {
+	runMutex.RLock()
+	defer runMutex.RUnlock()
+	return run
+}
+
+// ContextWithRun returns a context that carries info. The *Context log
+// functions (e.g. InfoContext) use it rather than the current run.
+func ContextWithRun(ctx context.Context, info RunInfo) context.Context {
+	return context.WithValue(ctx, runContextKey{}, info)
+}
+
+// RunFromContext returns the run carried by ctx or the current run
+func RunFromContext(ctx context.Context) RunInfo {
+	if ctx != nil {
+		if info, ok := ctx.Value(runContextKey{}).(RunInfo); ok {
+			return info
+		}
+	}
+	return CurrentRun()
+}
+
+// LogValue implements slog.LogValuer.
+func (r RunInfo) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("id", r.ID), slog.String("span", r.Span)}
+	if len(r.Parent) > 0 {
+		attrs = append(attrs, slog.String("parent", r.Parent))
+	}
+	if len(r.Command) > 0 {
+		attrs = append(attrs, slog.String("cmd", r.Command))
+	}
+	if len(r.Profile) > 0 {
+		attrs = append(attrs, slog.String("profile", r.Profile))
+	}
+	return slog.GroupValue(append(attrs, slog.Int("depth", r.Depth))...)
+}
+
+// isRunAttr is true for the run group added by a RunHandler
+func isRunAttr(a slog.Attr) bool {
+	_, ok := a.Value.Any().(RunInfo)
+	return ok && a.Key == RunKey
+}
+
+func newRunId(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RunHandler adds the run group to every record. The run comes from the
+// context of the log call (see ContextWithRun) or is the current run. The
+// group is always at the top level, even in a WithGroup logger. The slogger
+// loggers are always wrapped in one. The pretty handler only shows the profile.
+type RunHandler struct {
+	next    slog.Handler                      // the handler without the run
+	ops     []func(slog.Handler) slog.Handler // WithAttrs & WithGroup calls
+	derived atomic.Pointer[runDerived]        // next with the run & ops applied
+}
+
+// runDerived is the handler derived for a run. It is built when the run of a
+// record changes rather than for every record.
+type runDerived struct {
+	run     RunInfo
+	handler slog.Handler
+}
+
+var _ slog.Handler = (*RunHandler)(nil)
+
+// NewRunHandler wraps h. A RunHandler is returned unchanged.
+func NewRunHandler(h slog.Handler) *RunHandler {
+	if rh, ok := h.(*RunHandler); ok {
+		return rh
+	}
+	return &RunHandler{next: h}
+}
+
+// Unwrap returns the handler that records are passed to
+func (h *RunHandler) Unwrap() slog.Handler {
+	return h.next
+}
+
+// Enabled implements slog.Handler.
+func (h *RunHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+// Handle implements slog.Handler. The run is added before the attributes &
+// groups of the logger so that it stays at the top level.
+func (h *RunHandler) Handle(ctx context.Context, rec slog.Record) error {
+	info := RunFromContext(ctx)
+	if d := h.derived.Load(); d != nil && d.run == info {
+		return d.handler.Handle(ctx, rec)
+	}
+	next := h.next
+	if len(info.ID) > 0 {
+		next = next.WithAttrs([]slog.Attr{slog.Any(RunKey, info)})
+	}
+	for _, op := range h.ops {
+		next = op(next)
+	}
+	h.derived.Store(&runDerived{run: info, handler: next})
+	return next.Handle(ctx, rec)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *RunHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+// WithGroup implements slog.Handler.
+func (h *RunHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *RunHandler) with(op func(slog.Handler) slog.Handler) *RunHandler {
+	return &RunHandler{next: h.next, ops: append(append([]func(slog.Handler) slog.Handler{}, h.ops...), op)}
+}
@@ -0,0 +1,122 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRunContext(t *testing.T) {
+	t.Setenv(slogger.RunIdEnv, "")
+	t.Setenv(slogger.ParentEnv, "")
+	defer slogger.SetRun(slogger.CurrentRun())
+
+	Convey("nested runs should inherit the run id & parent chain", t, func() {
+		os.Unsetenv(slogger.RunIdEnv)
+		top := slogger.StartRun("clog")
+		So(top.ID, ShouldHaveLength, 16)
+		So(top.Depth, ShouldEqual, 0)
+		So(os.Getenv(slogger.ParentEnv), ShouldEqual, top.Span)
+
+		child := slogger.StartRun("clog Log")
+		So(child.ID, ShouldEqual, top.ID)
+		So(child.Parent, ShouldEqual, top.Span)
+		So(child.Depth, ShouldEqual, 1)
+		grandchild := slogger.StartRun("clog Log")
+		So(grandchild.Parent, ShouldEqual, top.Span+"."+child.Span)
+		So(grandchild.Depth, ShouldEqual, 2)
+	})
+
+	Convey("every record should carry the run at the top level", t, func() {
+		os.Unsetenv(slogger.RunIdEnv)
+		info := slogger.StartRun("clog Check")
+		buf := &bytes.Buffer{}
+		logger := slog.New(slogger.NewRunHandler(slog.NewJSONHandler(buf, nil)))
+		logger.WithGroup("g").Info("grouped", "k", "v")
+		line := map[string]any{}
+		So(json.Unmarshal(buf.Bytes(), &line), ShouldBeNil)
+		run := line["run"].(map[string]any)
+		So(run["id"], ShouldEqual, info.ID)
+		So(run["cmd"], ShouldEqual, "clog Check")
+		So(run["depth"], ShouldEqual, 0)
+		So(line["g"], ShouldResemble, map[string]any{"k": "v"})
+
+		buf.Reset()
+		ctx := slogger.ContextWithRun(context.Background(), slogger.RunInfo{ID: "other", Command: "clog Log", Depth: 3})
+		logger.InfoContext(ctx, "from ctx")
+		So(buf.String(), ShouldContainSubstring, `"id":"other"`)
+		So(buf.String(), ShouldContainSubstring, `"depth":3`)
+	})
+
+	Convey("the slogger *Context functions should use the run of the context", t, func() {
+		console := slog.Default()
+		defer slog.SetDefault(console)
+		buf := &bytes.Buffer{}
+		slog.SetDefault(slog.New(slogger.NewRunHandler(slog.NewJSONHandler(buf, nil))))
+		slogger.WarnContext(slogger.ContextWithRun(context.Background(), slogger.RunInfo{ID: "ctx-run"}), "w")
+		So(buf.String(), ShouldContainSubstring, `"run":{"id":"ctx-run"`)
+	})
+
+	Convey("the pretty handler should not show the run", t, func() {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slogger.NewRunHandler(slogger.NewPrettyHandler(buf, &slogger.PrettyHandlerOptions{NoColor: true})))
+		logger.Info("tidy", "run", "mine")
+		So(buf.String(), ShouldNotContainSubstring, "span")
+		So(buf.String(), ShouldContainSubstring, "run=mine")
+	})
+
+	Convey("the pretty handler should show the profile of the run", t, func() {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slogger.NewRunHandler(slogger.NewPrettyHandler(buf, &slogger.PrettyHandlerOptions{NoColor: true})))
+		ctx := slogger.ContextWithRun(context.Background(), slogger.RunInfo{ID: "r", Span: "s", Profile: "prod"})
+		logger.WithGroup("g").InfoContext(ctx, "deploy", "k", "v")
+		So(buf.String(), ShouldContainSubstring, "profile=prod")
+		So(buf.String(), ShouldNotContainSubstring, "g.profile")
+		So(buf.String(), ShouldNotContainSubstring, "span")
+	})
+
+	Convey("the logger attributes & groups should be applied once per run", t, func() {
+		os.Unsetenv(slogger.RunIdEnv)
+		slogger.StartRun("clog Check")
+		buf := &bytes.Buffer{}
+		derived := 0
+		inner := &countingHandler{Handler: slog.NewJSONHandler(buf, nil), derived: &derived}
+		logger := slog.New(slogger.NewRunHandler(inner)).With("k", "v").WithGroup("g")
+		for range 5 {
+			logger.Info("same run")
+		}
+		So(derived, ShouldEqual, 3) // run, k=v & g
+
+		slogger.SetRunCommand("clog Check pre-build")
+		logger.Info("new command")
+		So(derived, ShouldEqual, 6)
+		So(buf.String(), ShouldContainSubstring, `"cmd":"clog Check pre-build"`)
+		So(strings.Count(buf.String(), `"k":"v"`), ShouldEqual, 6)
+	})
+}
+
+// countingHandler counts the handlers derived with WithAttrs & WithGroup
+type countingHandler struct {
+	slog.Handler
+	derived *int
+}
+
+func (h *countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	*h.derived++
+	return &countingHandler{h.Handler.WithAttrs(attrs), h.derived}
+}
+
+func (h *countingHandler) WithGroup(name string) slog.Handler {
+	*h.derived++
+	return &countingHandler{h.Handler.WithGroup(name), h.derived}
+}
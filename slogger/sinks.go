@@ -0,0 +1,291 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Sink is one destination of a tee logger. It is declared in clog.yaml as
+//
+//	clog:
+//	  log:
+//	    style: tee
+//	    sinks:
+//	      - {style: pretty, target: stderr, level: info}
+//	      - {style: json,   target: tmp/clog.json, level: debug, add-source: true}
+//	      - {style: plain,  target: tmp/ci.log, rotate: {max-size: 10MB}}
+//	      - {style: otlp,   target: http://localhost:4318, headers: {x-team: media}}
+//
+//	      - {style: syslog, target: udp://loghost:514, syslog: {facility: local3}}
+//	      - {style: notify, target: "team,oncall", level: error}
+//
+// An otlp sink exports to an OTLP/HTTP collector if its target is a URL,
+// otherwise it writes OTLP JSON lines to the target file. A syslog sink
+// target is udp://, tcp:// or unix:// (empty for the local syslog socket).
+// A notify sink target is a comma separated list of clog.notify targets
+// (empty for the clog.notify routes).
+type Sink struct {
+	Style     string            `json:"style"`      // plain | pretty | json | job | otlp | syslog | notify
+	Target    string            `json:"target"`     // stderr | stdout | a file path | a URL | notify targets
+	Level     string            `json:"level"`      // defaults to clog.log.level (error for notify)
+	AddSource bool              `json:"add-source"` // add the file:line of the log call
+	Rotate    *Rotation         `json:"rotate"`     // rotation & retention of a file target
+	Headers   map[string]string `json:"headers"`    // http headers of an otlp URL
+	Batch     int               `json:"batch"`      // otlp records per export (default 1)
+	Syslog    *SyslogConfig     `json:"syslog"`     // facility & app-name of a syslog sink
+}
+
+// IsFile is true if the sink writes to a file rather than the console, a
+// collector, syslog or a notifier
+func (s Sink) IsFile() bool {
+	if style, _ := ParseStyle(s.Style); style == StyleSyslog || style == StyleNotify {
+		return false
+	}
+	switch strings.ToLower(s.Target) {
+	case "", "stderr", "stdout":
+		return false
+	}
+	return !isOTLPEndpoint(s.Target)
+}
+
+// the files opened by the sinks of the default logger
+var sinkFiles = []io.Closer{}
+var sinkMutex sync.Mutex
+
+// open returns the writer for the sink target. Files are opened for appending
+// and their folders are created.
+func (s Sink) open() (io.Writer, io.Closer, error) {
+	switch strings.ToLower(s.Target) {
+	case "", "stderr":
+		return os.Stderr, nil, nil
+	case "stdout":
+		return os.Stdout, nil, nil
+	}
+	path := os.ExpandEnv(s.Target)
+	if s.Rotate != nil {
+		f, err := OpenRotatingFile(path, *s.Rotate)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, f, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f, nil
+}
+
+// NewSinkHandler creates the handler for a sink. If the sink has no level
+// then defaultLevel is used - if it is a *LevelSet then its component levels
+// apply too. The returned level is the (base) level of the sink. The returned
+// file (if any) should be closed when logging is finished.
+func NewSinkHandler(s Sink, defaultLevel slog.Leveler) (slog.Handler, slog.Level, io.Closer, error) {
+	// a notify sink without a level only sends errors rather than a message
+	// for every record at the log level
+	if style, err := ParseStyle(s.Style); err == nil && style == StyleNotify && len(s.Level) == 0 {
+		s.Level = "error"
+	}
+	leveler, level := defaultLevel, defaultLevel.Level()
+	set, levelled := defaultLevel.(*LevelSet)
+	if levelled {
+		level = set.Base()
+	}
+	if len(s.Level) > 0 {
+		parsed, err := ParseLevel(s.Level)
+		if err != nil {
+			return nil, level, nil, err
+		}
+		leveler, level, levelled = parsed, parsed, false
+	}
+	h, file, err := newSinkHandler(s, leveler)
+	if err != nil {
+		return nil, level, nil, err
+	}
+	if levelled {
+		h = NewLevelsHandler(h, set)
+	}
+	return h, level, file, nil
+}
+
+// newSinkHandler creates the handler for a sink of any style but tee
+func newSinkHandler(s Sink, level slog.Leveler) (slog.Handler, io.Closer, error) {
+	style, err := ParseStyle(s.Style)
+	if err != nil {
+		return nil, nil, err
+	}
+	if style == StyleOTLP && isOTLPEndpoint(s.Target) {
+		headers := map[string]string{}
+		for k, v := range s.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		exporter, err := NewOTLPHTTPExporter(os.ExpandEnv(s.Target), headers)
+		if err != nil {
+			return nil, nil, err
+		}
+		h := NewOTLPHandler(exporter, &OTLPOptions{Level: level, AddSource: s.AddSource, BatchSize: s.Batch})
+		return h, h, nil
+	}
+	if style == StyleSyslog {
+		cfg := SyslogConfig{}
+		if s.Syslog != nil {
+			cfg = *s.Syslog
+		}
+		opts, err := cfg.Options(level, s.AddSource)
+		if err != nil {
+			return nil, nil, err
+		}
+		h, err := NewSyslogHandler(os.ExpandEnv(s.Target), &opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return h, h, nil
+	}
+	if style == StyleNotify {
+		n := DefaultNotifier()
+		if n == nil {
+			return nil, nil, fmt.Errorf("a notify sink needs clog.notify targets")
+		}
+		targets := []string{}
+		for _, t := range strings.Split(s.Target, ",") {
+			if t = strings.TrimSpace(t); len(t) > 0 {
+				if !slices.Contains(n.Targets(), t) {
+					return nil, nil, fmt.Errorf("unknown notify target (%s)", t)
+				}
+				targets = append(targets, t)
+			}
+		}
+		return NewNotifyHandler(n, level, targets...), nil, nil
+	}
+	out, file, err := s.open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var h slog.Handler
+	switch style {
+	case StylePlain:
+		if file != nil {
+			out = NewAnsiStripWriter(out)
+		}
+		h = NewPrettyHandler(out, &PrettyHandlerOptions{Level: level, NoColor: true, AddSource: s.AddSource})
+	case StyleJSON:
+		h = slog.NewJSONHandler(out, &slog.HandlerOptions{Level: level, AddSource: s.AddSource})
+	case StyleJob:
+		h = NewJobHandler(out, &slog.HandlerOptions{Level: level, AddSource: s.AddSource})
+	case StyleOTLP:
+		otlp := NewOTLPHandler(NewOTLPFileExporter(out), &OTLPOptions{Level: level, AddSource: s.AddSource, BatchSize: s.Batch})
+		h = otlp
+		file = flushCloser{otlp, file}
+	case StyleTee:
+		if file != nil {
+			file.Close()
+		}
+		return nil, nil, fmt.Errorf("a sink cannot have style tee")
+	default:
+		// pretty files only get colour if it is forced
+		f, _ := out.(*os.File)
+		h = NewPrettyHandler(out, &PrettyHandlerOptions{
+			Level:     level,
+			AddSource: s.AddSource,
+			NoColor:   !ColorEnabled(f),
+			Theme:     CurrentTheme(),
+		})
+	}
+	return h, file, nil
+}
+
+// flushCloser flushes a buffering handler before its file (if any) is closed
+type flushCloser struct {
+	handler *OTLPHandler
+	file    io.Closer
+}
+
+func (c flushCloser) Close() error {
+	err := c.handler.Flush()
+	if c.file != nil {
+		return errors.Join(err, c.file.Close())
+	}
+	return err
+}
+
+// UseTeeLogger sends every record to all the sinks, each with its own level,
+// style & source setting. Sinks without a level use level as the base of
+// LogLevels() so that component levels apply to them. Sinks that cannot be
+// created are skipped and returned as an error. If no sinks can be created
+// the logger is unchanged.
+func UseTeeLogger(level slog.Level, sinks []Sink) error {
+	previous := logLevels.Base()
+	logLevels.SetBase(level)
+	handlers := []slog.Handler{}
+	files := []io.Closer{}
+	errs := []string{}
+	consoleLevel, fileLevel := LevelEmergency+1, LevelEmergency+1
+	for i, s := range sinks {
+		h, l, file, err := NewSinkHandler(s, logLevels)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("sink #%d (%s): %s", i, s.Target, err.Error()))
+			continue
+		}
+		handlers = append(handlers, h)
+		if file != nil {
+			files = append(files, file)
+			fileLevel = min(fileLevel, l)
+		} else {
+			consoleLevel = min(consoleLevel, l)
+		}
+	}
+	var err error
+	if len(errs) > 0 {
+		err = fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	if len(handlers) == 0 {
+		logLevels.SetBase(previous)
+		return err
+	}
+
+	CloseSinks()
+	sinkMutex.Lock()
+	sinkFiles = files
+	sinkMutex.Unlock()
+
+	setDefaultHandler(NewMultiHandler(handlers...))
+	logLevel = consoleLevel
+	logLevelFile = fileLevel
+	return err
+}
+
+// CloseSinks closes the files opened by UseTeeLogger and flushes any otlp
+// sinks
+func CloseSinks() {
+	sinkMutex.Lock()
+	defer sinkMutex.Unlock()
+	for _, f := range sinkFiles {
+		f.Close()
+	}
+	sinkFiles = []io.Closer{}
+}
+
+// Exit closes the sinks so that buffered records (e.g. a partial otlp batch)
+// are not lost and then exits with code. os.Exit skips deferred calls so a
+// command that used it would drop the last records of a failed run - the
+// ones that matter most. Anything that exits once the sinks may be open
+// should use Exit rather than os.Exit. config.New still uses os.Exit
+// because it runs before the sinks are opened from the config.
+func Exit(code int) {
+	CloseSinks()
+	os.Exit(code)
+}
@@ -0,0 +1,64 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTeeSinks(t *testing.T) {
+
+	Convey("a tee logger should write each sink at its own level & style", t, func() {
+		dir := t.TempDir()
+		jsonPath := filepath.Join(dir, "logs", "clog.json")
+		plainPath := filepath.Join(dir, "ci.log")
+		defer slogger.SaveLoggerState()()
+
+		err := slogger.UseTeeLogger(slogger.LevelInfo, []slogger.Sink{
+			{Style: "json", Target: jsonPath, Level: "debug", AddSource: true},
+			{Style: "plain", Target: plainPath, Level: "warn"},
+			{Style: "loud", Target: plainPath},
+		})
+		So(err, ShouldNotBeNil) // the bad sink is reported, the others are used
+		consoleLevel, fileLevel := slogger.GetLogLevel()
+		So(fileLevel, ShouldEqual, slogger.LevelDebug)
+		So(consoleLevel, ShouldBeGreaterThan, slogger.LevelEmergency)
+
+		slogger.Debug("detail")
+		slogger.Warn("\x1b[31mcareful")
+		slogger.CloseSinks()
+
+		jsonLog, _ := os.ReadFile(jsonPath)
+		So(string(jsonLog), ShouldContainSubstring, `"msg":"detail"`)
+		So(string(jsonLog), ShouldContainSubstring, "sinks_test.go")
+		plainLog, _ := os.ReadFile(plainPath)
+		So(string(plainLog), ShouldNotContainSubstring, "detail")
+		So(string(plainLog), ShouldContainSubstring, "WRN careful")
+	})
+
+	Convey("closing the sinks should export a partial otlp batch", t, func() {
+		path := filepath.Join(t.TempDir(), "otlp.jsonl")
+		defer slogger.SaveLoggerState()()
+
+		err := slogger.UseTeeLogger(slogger.LevelInfo, []slogger.Sink{
+			{Style: "otlp", Target: path, Batch: 10},
+		})
+		So(err, ShouldBeNil)
+		slogger.Info("one")
+		slogger.Info("two")
+		slogger.Info("three")
+		body, _ := os.ReadFile(path)
+		So(body, ShouldBeEmpty)
+
+		slogger.CloseSinks()
+		body, _ = os.ReadFile(path)
+		So(string(body), ShouldContainSubstring, "one")
+		So(string(body), ShouldContainSubstring, "three")
+	})
+}
@@ -5,7 +5,11 @@ package slogger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"runtime"
+	"strings"
+	"time"
 )
 
 const (
@@ -43,111 +47,155 @@ func sloggerReplaceAttr(groups []string, a slog.Attr) slog.Attr {
 
 		// Handle custom level values.
 		level := a.Value.Any().(slog.Level)
-		// This could also look up the name from a map or other structure, but
-		// this demonstrates using a switch statement to rename levels. For
-		// maximum performance, the string values should be constants, but this
-		// example uses the raw strings for readability.
-		switch {
-		case level >= LevelEmergency:
-			a.Value = slog.StringValue(strEmergency)
-		case level >= LevelFatal:
-			a.Value = slog.StringValue(strFatal)
-		case level >= LevelError:
-			a.Value = slog.StringValue(strError)
-		case level >= LevelWarn:
-			a.Value = slog.StringValue(strWarn)
-		case level >= LevelSuccess:
-			a.Value = slog.StringValue(strSuccess)
-		case level >= LevelInfo:
-			a.Value = slog.StringValue(strInfo)
-		case level >= LevelDebug:
-			a.Value = slog.StringValue(strDebug)
-		default:
-			a.Value = slog.StringValue(strTrace)
-		}
+		a.Value = slog.StringValue(LevelName(level))
 	}
 	return a
 }
 
+// LevelName returns the slogger name of a level e.g. SUCCESS. A level
+// between two names gets the name of the level below it.
+func LevelName(level slog.Level) string {
+	switch {
+	case level >= LevelEmergency:
+		return strEmergency
+	case level >= LevelFatal:
+		return strFatal
+	case level >= LevelError:
+		return strError
+	case level >= LevelWarn:
+		return strWarn
+	case level >= LevelSuccess:
+		return strSuccess
+	case level >= LevelInfo:
+		return strInfo
+	case level >= LevelDebug:
+		return strDebug
+	}
+	return strTrace
+}
+
+// ParseLevel converts a level name from a config file or the command line
+// (e.g. "warn", "WRN", "success") into a slog.Level. Numeric levels like
+// "-4" are also accepted.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case strTrace, "TRC":
+		return LevelTrace, nil
+	case strDebug, "DBG":
+		return LevelDebug, nil
+	case strInfo, "INF", "":
+		return LevelInfo, nil
+	case strSuccess, "OK":
+		return LevelSuccess, nil
+	case strWarn, "WRN", "WARNING":
+		return LevelWarn, nil
+	case strError, "ERR":
+		return LevelError, nil
+	case strFatal, "FTL":
+		return LevelFatal, nil
+	case strEmergency, "!!!":
+		return LevelEmergency, nil
+	}
+	var level int
+	if _, err := fmt.Sscanf(name, "%d", &level); err == nil {
+		return slog.Level(level), nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level (%s)", name)
+}
+
+// logAt logs with the source position of the caller of the level function
+// (e.g. Warn) rather than the level function itself.
+func logAt(ctx context.Context, level slog.Level, msg string, args ...any) {
+	l := Default()
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, logAt & the level function
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
 // Default returns the default [Logger].
 func Default() *slog.Logger { return slog.Default() }
 
 // Debug logs at [LevelDebug].
 func Debug(msg string, args ...any) {
-	Default().Log(context.Background(), LevelDebug, msg, args...)
+	logAt(context.Background(), LevelDebug, msg, args...)
 }
 
 // DebugContext logs at [LevelDebug] with the given context.
 func DebugContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelDebug, msg, args...)
+	logAt(ctx, LevelDebug, msg, args...)
 }
 
 // Trace logs at [LevelTrace].
 func Trace(msg string, args ...any) {
-	Default().Log(context.Background(), LevelTrace, msg, args...)
+	logAt(context.Background(), LevelTrace, msg, args...)
 }
 
 // TraceContext logs at [LevelTrace] with the given context.
 func TraceContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelTrace, msg, args...)
+	logAt(ctx, LevelTrace, msg, args...)
 }
 
 // Info logs at [LevelInfo].
 func Info(msg string, args ...any) {
-	Default().Log(context.Background(), LevelInfo, msg, args...)
+	logAt(context.Background(), LevelInfo, msg, args...)
 }
 
 // InfoContext logs at [LevelInfo] with the given context.
 func InfoContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelInfo, msg, args...)
+	logAt(ctx, LevelInfo, msg, args...)
 }
 
 // Success logs at [LevelSuccess].
 func Success(msg string, args ...any) {
-	Default().Log(context.Background(), LevelSuccess, msg, args...)
+	logAt(context.Background(), LevelSuccess, msg, args...)
 }
 
 // SuccessContext logs at [LevelSuccess] with the given context.
 func SuccessContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelSuccess, msg, args...)
+	logAt(ctx, LevelSuccess, msg, args...)
 }
 
 // Warn logs at [LevelWarn].
 func Warn(msg string, args ...any) {
-	Default().Log(context.Background(), LevelWarn, msg, args...)
+	logAt(context.Background(), LevelWarn, msg, args...)
 }
 
 // WarnContext logs at [LevelWarn] with the given context.
 func WarnContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelWarn, msg, args...)
+	logAt(ctx, LevelWarn, msg, args...)
 }
 
 // Error logs at [LevelError].
 func Error(msg string, args ...any) {
-	Default().Log(context.Background(), LevelError, msg, args...)
+	logAt(context.Background(), LevelError, msg, args...)
 }
 
 // ErrorContext logs at [LevelError] with the given context.
 func ErrorContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelError, msg, args...)
+	logAt(ctx, LevelError, msg, args...)
 }
 
 // Fatal logs at [LevelFatal].
 func Fatal(msg string, args ...any) {
-	Default().Log(context.Background(), LevelFatal, msg, args...)
+	logAt(context.Background(), LevelFatal, msg, args...)
 }
 
 // FatalContext logs at [LevelFatal] with the given context.
 func FatalContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelFatal, msg, args...)
+	logAt(ctx, LevelFatal, msg, args...)
 }
 
 // Emergency logs at [LevelEmergency].
 func Emergency(msg string, args ...any) {
-	Default().Log(context.Background(), LevelEmergency, msg, args...)
+	logAt(context.Background(), LevelEmergency, msg, args...)
 }
 
 // EmergencyContext logs at [LevelEmergency] with the given context.
 func EmergencyContext(ctx context.Context, msg string, args ...any) {
-	Default().Log(ctx, LevelEmergency, msg, args...)
+	logAt(ctx, LevelEmergency, msg, args...)
 }
@@ -10,8 +10,10 @@ package slogger
 // in your main.init()
 
 import (
+	"fmt"
 	"log/slog"
 	"runtime"
+	"strings"
 )
 
 type Slogger struct {
@@ -23,7 +25,7 @@ var Logger *slog.Logger
 
 // these will be exported with a get function for clog Log
 var logLevel slog.Level
-var logLevelFile slog.Level
+var logLevelFile slog.Level = LevelEmergency + 1
 
 type SlogStyle int
 
@@ -34,6 +36,9 @@ const (
 	StyleJSON
 	StyleTee
 	StyleJob
+	StyleOTLP
+	StyleSyslog
+	StyleNotify
 )
 
 // add a string function to Sprintf("%s") our new type
@@ -49,10 +54,40 @@ func (s SlogStyle) String() string {
 		return "    job"
 	case StyleTee:
 		return "    tee"
+	case StyleOTLP:
+		return "   otlp"
+	case StyleSyslog:
+		return " syslog"
+	case StyleNotify:
+		return " notify"
 	}
 	return "unknown"
 }
 
+// ParseStyle converts a style name from a config file (e.g. clog.log.style)
+// into a SlogStyle
+func ParseStyle(name string) (SlogStyle, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "plain":
+		return StylePlain, nil
+	case "pretty", "":
+		return StylePretty, nil
+	case "json":
+		return StyleJSON, nil
+	case "job", "st2126":
+		return StyleJob, nil
+	case "tee":
+		return StyleTee, nil
+	case "otlp", "otel":
+		return StyleOTLP, nil
+	case "syslog":
+		return StyleSyslog, nil
+	case "notify":
+		return StyleNotify, nil
+	}
+	return defaultLogStyle, fmt.Errorf("unknown log style (%s)", name)
+}
+
 // the defaults
 // var defaultLogLevel = slog.LevelDebug  //use this for init tracing
 var defaultLogLevel = slog.LevelInfo
@@ -70,14 +105,16 @@ func SetLogger(level slog.Level, style SlogStyle) {
 	case StyleJob:
 		UseJobLogger(level)
 	default:
-		// there is no default Tee logger as it needs a file path
+		// there is no default Tee, OTLP, syslog or notify logger as they need sinks - see
+		// UseTeeLogger
 		SetLogger(level, defaultLogStyle)
 	}
 
 }
 
-// get the active log levels for a split console / cicd logging experience
-func GetLogLevel() (logLevel slog.Level, logLevelFile slog.Level) {
+// get the active log levels for a split console / cicd logging experience.
+// The file level is above LevelEmergency if no file is being logged to.
+func GetLogLevel() (console slog.Level, file slog.Level) {
 	return logLevel, logLevelFile
 }
 
@@ -6,66 +6,88 @@ package slogger
 // package log defines the logger for the app
 
 import (
-	"bufio"
 	"io"
 	"log/slog"
 	"os"
 	"runtime"
 )
 
+// UsePrettyLogger logs to stderr with the current theme. Colour follows
+// the colour policy - see ColorEnabled. The level is the base level of
+// LogLevels() so that component levels also apply.
 func UsePrettyLogger(level slog.Level) {
-	Logger = slog.New(
-		NewPrettyHandler(os.Stderr, &PrettyHandlerOptions{Level: level}))
-	slog.SetDefault(Logger)
+	logLevels.SetBase(level)
+	setLevelledHandler(
+		NewPrettyHandler(os.Stderr, &PrettyHandlerOptions{
+			Level:   logLevels,
+			NoColor: !ColorEnabled(os.Stderr),
+			Theme:   CurrentTheme(),
+		}))
 	logLevel = level
 }
 
 func UsePrettyIoLogger(out io.Writer, level slog.Level) {
-	Logger = slog.New(
-		NewPrettyHandler(out, &PrettyHandlerOptions{Level: level}))
-	slog.SetDefault(Logger)
+	logLevels.SetBase(level)
+	setLevelledHandler(
+		NewPrettyHandler(out, &PrettyHandlerOptions{Level: logLevels, Theme: CurrentTheme()}))
 	logLevel = level
 }
 
 func UsePlainLogger(level slog.Level) {
-	Logger = slog.New(
+	logLevels.SetBase(level)
+	setLevelledHandler(
 		NewPrettyHandler(os.Stderr,
-			&PrettyHandlerOptions{Level: level, NoColor: true}))
-	slog.SetDefault(Logger)
+			&PrettyHandlerOptions{Level: logLevels, NoColor: true}))
 	logLevel = level
 }
 
 // TeeLogger is a no-color version of the PrettyLogger that is created
 // to append to a job log folder. If the file cannot be opened for appending
-// an error is returned
+// an error is returned. Records are written straight to the file (unbuffered)
+// so that other output written to the returned file stays in order. Any ANSI
+// escapes in messages or attributes are stripped.
 func NewTeeLogger(path string, level slog.Level) (*slog.Logger, *os.File, error) {
 	fileHandle, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	writer := bufio.NewWriter(fileHandle)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	newLogger := slog.New(
-		NewPrettyHandler(writer,
-			&PrettyHandlerOptions{Level: level, NoColor: true}))
+	newLogger := slog.New(NewRedactHandler(
+		NewPrettyHandler(NewAnsiStripWriter(fileHandle),
+			&PrettyHandlerOptions{Level: level, NoColor: true})))
 
 	logLevelFile = level
 	return newLogger, fileHandle, err
 }
 
 func UseJSONLogger(level slog.Level) {
-	Logger = slog.New(slog.NewJSONHandler(os.Stderr,
-		&slog.HandlerOptions{Level: level}))
-	slog.SetDefault(Logger)
+	logLevels.SetBase(level)
+	setLevelledHandler(slog.NewJSONHandler(os.Stderr,
+		&slog.HandlerOptions{Level: logLevels}))
 	logLevel = level
 }
 
-// Job logger is currently just a JSON Logger
-// @ToDo - implement the full SMPTE ST 2126 logging
+// Job logger writes SMPTE ST 2126 job records as JSON lines
 func UseJobLogger(level slog.Level) {
-	Logger = slog.New(slog.NewJSONHandler(os.Stderr,
-		&slog.HandlerOptions{Level: level}))
-	slog.SetDefault(Logger)
+	logLevels.SetBase(level)
+	setLevelledHandler(NewJobHandler(os.Stderr,
+		&slog.HandlerOptions{Level: logLevels}))
 	logLevel = level
 }
 
+// setLevelledHandler applies the component levels of LogLevels() to h, which
+// must use LogLevels() as its level, and makes it the default logger
+func setLevelledHandler(h slog.Handler) {
+	setDefaultHandler(NewLevelsHandler(h, logLevels))
+}
+
+// setDefaultHandler makes h, wrapped with a RedactHandler & a RunHandler,
+// the default logger
+func setDefaultHandler(h slog.Handler) {
+	Logger = slog.New(NewRedactHandler(NewRunHandler(h)))
+	slog.SetDefault(Logger)
+}
+
 func init() {
 	// trace init order for sanity
 	_, file, _, _ := runtime.Caller(0)
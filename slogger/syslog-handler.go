@@ -0,0 +1,379 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogHandler writes RFC 5424 messages to a syslog daemon or relay over a
+// unix socket, UDP or TCP. Levels map to the syslog severities:
+//
+//	EMERGENCY emerg(0)  FATAL crit(2)  ERROR err(3)  WARN warning(4)
+//	SUCCESS notice(5)   INFO info(6)   DEBUG & TRACE debug(7)
+//
+// Attributes become the SD-PARAMs of one SD-ELEMENT (default clog@32473).
+// Groups are flattened to dotted names.
+type SyslogHandler struct {
+	opts   SyslogOptions
+	conn   *syslogConn
+	attrs  []groupedAttr
+	groups []string
+}
+
+// SyslogConfig is the clog.log.syslog config (or the syslog of a sink)
+//
+//	clog:
+//	  log:
+//	    syslog: {facility: daemon, app-name: hookhandler}
+type SyslogConfig struct {
+	Facility string `json:"facility"` // kern | user | daemon | ... | local0-7 (default user)
+	AppName  string `json:"app-name"` // default the name of the executable
+	SDID     string `json:"sd-id"`    // default clog@32473
+}
+
+// SyslogOptions configure a SyslogHandler
+type SyslogOptions struct {
+	Level     slog.Leveler // minimum level - default Info
+	AddSource bool         // add a source="file:line" SD-PARAM
+	Facility  int          // syslog facility code - see ParseFacility
+	AppName   string
+	Hostname  string // default os.Hostname()
+	SDID      string
+}
+
+// the facility names of RFC 5424 section 6.2.1
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"ntp": 12, "security": 13, "console": 14, "clock": 15,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// the default SD-ID. 32473 is the enterprise number reserved for
+// documentation & examples (RFC 5612).
+const DefaultSyslogSDID = "clog@32473"
+
+// the local sockets tried by NewSyslogHandler for an empty target
+var syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// ParseFacility converts a facility name (e.g. daemon, local3) or number
+// into a facility code. An empty name is user.
+func ParseFacility(name string) (int, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) == 0 {
+		return syslogFacilities["user"], nil
+	}
+	if f, known := syslogFacilities[name]; known {
+		return f, nil
+	}
+	if f, err := strconv.Atoi(name); err == nil && f >= 0 && f <= 23 {
+		return f, nil
+	}
+	return syslogFacilities["user"], fmt.Errorf("unknown syslog facility (%s)", name)
+}
+
+// SyslogSeverity maps a slog level to the RFC 5424 severity
+func SyslogSeverity(l slog.Level) int {
+	switch {
+	case l >= LevelEmergency:
+		return 0
+	case l >= LevelFatal:
+		return 2
+	case l >= LevelError:
+		return 3
+	case l >= LevelWarn:
+		return 4
+	case l >= LevelSuccess:
+		return 5
+	case l >= LevelInfo:
+		return 6
+	}
+	return 7
+}
+
+// Options converts the config into handler options
+func (c SyslogConfig) Options(level slog.Leveler, addSource bool) (SyslogOptions, error) {
+	facility, err := ParseFacility(c.Facility)
+	return SyslogOptions{
+		Level:     level,
+		AddSource: addSource,
+		Facility:  facility,
+		AppName:   c.AppName,
+		SDID:      c.SDID,
+	}, err
+}
+
+// syslogConn is a connection to a syslog receiver. Stream connections use
+// octet counting (RFC 6587) so that messages may contain newlines. A failed
+// write is retried once on a new connection.
+type syslogConn struct {
+	mu      sync.Mutex
+	network string
+	address string
+	conn    net.Conn
+}
+
+// NewSyslogHandler connects to a syslog receiver. The target is one of
+//
+//	udp://host:514   tcp://host:601   unix:///dev/log
+//
+// or empty for the local syslog socket. A unix socket is tried as a datagram
+// socket first and then as a stream. If opts is nil the level is Info and
+// the facility is user.
+func NewSyslogHandler(target string, opts *SyslogOptions) (*SyslogHandler, error) {
+	if opts == nil {
+		opts = &SyslogOptions{Facility: syslogFacilities["user"]}
+	}
+	c, err := dialSyslogConn(target)
+	if err != nil {
+		return nil, err
+	}
+	h := &SyslogHandler{opts: *opts, conn: c}
+	if len(h.opts.AppName) == 0 {
+		h.opts.AppName = appName()
+	}
+	if len(h.opts.Hostname) == 0 {
+		h.opts.Hostname, _ = os.Hostname()
+	}
+	if len(h.opts.SDID) == 0 {
+		h.opts.SDID = DefaultSyslogSDID
+	}
+	return h, nil
+}
+
+func dialSyslogConn(target string) (*syslogConn, error) {
+	if len(target) == 0 {
+		for _, path := range syslogLocalSockets {
+			if c, err := dialSyslogConn("unix://" + path); err == nil {
+				return c, nil
+			}
+		}
+		return nil, fmt.Errorf("no local syslog socket found (%s)", strings.Join(syslogLocalSockets, ", "))
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	c := &syslogConn{network: u.Scheme, address: u.Host}
+	switch u.Scheme {
+	case "udp", "tcp":
+	case "unix":
+		c.address = u.Path
+		c.network = "unixgram"
+		if err := c.dial(); err == nil {
+			return c, nil
+		}
+		c.network = "unix"
+	default:
+		return nil, fmt.Errorf("syslog target must be udp://, tcp:// or unix:// (%s)", target)
+	}
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *syslogConn) dial() error {
+	conn, err := net.DialTimeout(c.network, c.address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	return nil
+}
+
+func (c *syslogConn) stream() bool {
+	return c.network == "tcp" || c.network == "unix"
+}
+
+// write sends one message
+func (c *syslogConn) write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stream() {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	if c.conn != nil {
+		if _, err := c.conn.Write(msg); err == nil {
+			return nil
+		}
+		c.conn.Close()
+		c.conn = nil
+	}
+	if err := c.dial(); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(msg)
+	return err
+}
+
+// Close closes the connection. It implements io.Closer so that the handler
+// can be closed with the sinks.
+func (h *SyslogHandler) Close() error {
+	h.conn.mu.Lock()
+	defer h.conn.mu.Unlock()
+	if h.conn.conn == nil {
+		return nil
+	}
+	err := h.conn.conn.Close()
+	h.conn.conn = nil
+	return err
+}
+
+var _ slog.Handler = (*SyslogHandler)(nil)
+
+// Enabled implements slog.Handler.
+func (h *SyslogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	min := LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return l >= min
+}
+
+// Handle implements slog.Handler.
+func (h *SyslogHandler) Handle(_ context.Context, rec slog.Record) error {
+	b := strings.Builder{}
+	b.WriteString("<" + strconv.Itoa(h.opts.Facility*8+SyslogSeverity(rec.Level)) + ">1 ")
+	t := rec.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	b.WriteString(t.Format("2006-01-02T15:04:05.000000Z07:00") + " ")
+	b.WriteString(syslogHeaderField(h.opts.Hostname, 255) + " ")
+	b.WriteString(syslogHeaderField(h.opts.AppName, 48) + " ")
+	b.WriteString(strconv.Itoa(os.Getpid()) + " - ")
+
+	params := []string{}
+	for _, ga := range h.attrs {
+		params = appendSDParams(params, ga.groups, ga.attr)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		params = appendSDParams(params, h.groups, a)
+		return true
+	})
+	if h.opts.AddSource && rec.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		params = appendSDParams(params, nil, slog.String("source", frame.File+":"+strconv.Itoa(frame.Line)))
+	}
+	if len(params) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + h.opts.SDID + " " + strings.Join(params, " ") + "]")
+	}
+	if len(rec.Message) > 0 {
+		b.WriteString(" " + rec.Message)
+	}
+	return h.conn.write([]byte(b.String()))
+}
+
+// syslogHeaderField is a header field of printable ASCII or the nil value
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+	if len(s) == 0 {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	return s
+}
+
+// sdParamName is a PARAM-NAME - up to 32 printable ASCII characters except
+// '=', ' ', ']' & '"'
+func sdParamName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > 32 {
+		s = s[:32]
+	}
+	return s
+}
+
+// sdParamValue escapes '"', '\' & ']' as required by RFC 5424 section 6.3.3
+var sdParamValue = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// appendSDParams appends a resolved attribute as name="value" with its name
+// prefixed by the groups
+func appendSDParams(params []string, groups []string, a slog.Attr) []string {
+	value := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return params
+	}
+	if value.Kind() == slog.KindGroup {
+		if len(a.Key) > 0 {
+			groups = append(append([]string{}, groups...), a.Key)
+		}
+		for _, ga := range value.Group() {
+			params = appendSDParams(params, groups, ga)
+		}
+		return params
+	}
+	name := sdParamName(strings.Join(append(append([]string{}, groups...), a.Key), "."))
+	var s string
+	switch value.Kind() {
+	case slog.KindTime:
+		s = value.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			s = err.Error()
+		} else {
+			s = fmt.Sprintf("%+v", value.Any())
+		}
+	default:
+		s = value.String()
+	}
+	return append(params, name+`="`+sdParamValue.Replace(s)+`"`)
+}
+
+// appName is the name of the running executable
+func appName() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "clog"
+	}
+	name := exe[strings.LastIndexAny(exe, `/\`)+1:]
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// WithAttrs implements slog.Handler.
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]groupedAttr{}, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{groups: h.groups, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if len(name) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
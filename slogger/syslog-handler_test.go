@@ -0,0 +1,113 @@
+// Copyright ©2017-2025 Mr MXF   info@mrmxf.com
+// BSD-3-Clause License   https://opensource.org/license/bsd-3-clause/
+
+package slogger_test
+
+import (
+	"bufio"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrmxf/clog/slogger"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// readUDP returns the next datagram or "" after a timeout
+func readUDP(conn net.PacketConn) string {
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		return ""
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogHandler(t *testing.T) {
+
+	Convey("clog levels should map to syslog severities", t, func() {
+		So(slogger.SyslogSeverity(slogger.LevelEmergency), ShouldEqual, 0)
+		So(slogger.SyslogSeverity(slogger.LevelFatal), ShouldEqual, 2)
+		So(slogger.SyslogSeverity(slogger.LevelError), ShouldEqual, 3)
+		So(slogger.SyslogSeverity(slogger.LevelWarn), ShouldEqual, 4)
+		So(slogger.SyslogSeverity(slogger.LevelSuccess), ShouldEqual, 5)
+		So(slogger.SyslogSeverity(slogger.LevelInfo), ShouldEqual, 6)
+		So(slogger.SyslogSeverity(slogger.LevelTrace), ShouldEqual, 7)
+		f, err := slogger.ParseFacility("local3")
+		So(err, ShouldBeNil)
+		So(f, ShouldEqual, 19)
+		_, err = slogger.ParseFacility("kitchen")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("a syslog sink should send RFC 5424 messages over UDP", t, func() {
+		listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		defer listener.Close()
+		defer slogger.SaveLoggerState()()
+
+		err = slogger.UseTeeLogger(slogger.LevelInfo, []slogger.Sink{{
+			Style:  "syslog",
+			Target: "udp://" + listener.LocalAddr().String(),
+			Syslog: &slogger.SyslogConfig{Facility: "daemon", AppName: "hookhandler"},
+		}})
+		So(err, ShouldBeNil)
+		defer slogger.CloseSinks()
+
+		slogger.Logger.WithGroup("hook").Warn("push rejected", "repo", `a "quoted" ]name\`, "code", 409)
+		msg := readUDP(listener)
+		// daemon(3)*8 + warning(4) = 28
+		So(msg, ShouldStartWith, "<28>1 ")
+		fields := strings.SplitN(msg, " ", 7)
+		So(fields, ShouldHaveLength, 7)
+		So(fields[3], ShouldEqual, "hookhandler")
+		So(fields[6], ShouldEqual, `[clog@32473 hook.repo="a \"quoted\" \]name\\" hook.code="409"] push rejected`)
+
+		slogger.Success("deployed")
+		So(readUDP(listener), ShouldEndWith, " - - deployed")
+	})
+
+	Convey("a TCP syslog handler should use octet counting", t, func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		defer listener.Close()
+		received := make(chan string, 1)
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				received <- ""
+				return
+			}
+			defer conn.Close()
+			line, _ := bufio.NewReader(conn).ReadString(']')
+			received <- line
+		}()
+
+		h, err := slogger.NewSyslogHandler("tcp://"+listener.Addr().String(), &slogger.SyslogOptions{
+			Facility: 16,
+			AppName:  "clog",
+			Hostname: "builder",
+		})
+		So(err, ShouldBeNil)
+		defer h.Close()
+		slog.New(h).Error("multi\nline", "err", errors.New("boom"))
+
+		frame := <-received
+		length, msg, found := strings.Cut(frame, " ")
+		So(found, ShouldBeTrue)
+		So(length, ShouldNotBeEmpty)
+		// local0(16)*8 + err(3) = 131
+		So(msg, ShouldStartWith, "<131>1 ")
+		So(msg, ShouldContainSubstring, " builder clog ")
+		So(msg, ShouldEndWith, `[clog@32473 err="boom"]`)
+	})
+
+	Convey("a bad syslog target should be an error", t, func() {
+		_, err := slogger.NewSyslogHandler("http://example.com", nil)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -8,10 +8,11 @@ package snips
 import (
 	"fmt"
 	"log/slog"
-	"os"
 	"runtime"
 
+	"github.com/mrmxf/clog/config"
 	"github.com/mrmxf/clog/scripts"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/spf13/cobra"
 )
 
@@ -75,7 +76,7 @@ func recurseRawMap(parentCmd *cobra.Command, group SnippetGroup, depth int, raw
 					if err != nil {
 						slog.Error("failed to stream snippet "+ident, "error", err)
 					}
-					os.Exit(exitStatus)
+					slogger.Exit(exitStatus)
 				},
 			}
 			parentCmd.AddCommand(cmd)
@@ -96,11 +97,17 @@ func recurseRawMap(parentCmd *cobra.Command, group SnippetGroup, depth int, raw
 				Run: func(cmd *cobra.Command, args []string) {
 					ident := fmt.Sprintf("snippet: %s", cmd.CommandPath())
 					slog.Debug(fmt.Sprintf("snippet: %s\n$ %s\n", ident, skript))
-					exitStatus, err := scripts.AwaitShellSnippet(skript, nil, args)
+					// a secret reference is only resolved when the snippet runs
+					script, err := config.Cfg().ResolveString(skript)
+					if err != nil {
+						slog.Error("cannot resolve "+ident, "error", err)
+						slogger.Exit(1)
+					}
+					exitStatus, err := scripts.AwaitShellSnippet(script, nil, args)
 					if err != nil {
 						slog.Error("failed to stream snippet "+ident, "error", err)
 					}
-					os.Exit(exitStatus)
+					slogger.Exit(exitStatus)
 				},
 			}
 			parentCmd.AddCommand(cmd)
@@ -120,7 +127,7 @@ func recurseRawMap(parentCmd *cobra.Command, group SnippetGroup, depth int, raw
 				},
 				Run: func(cmd *cobra.Command, args []string) {
 					cmd.Help()
-					os.Exit(1)
+					slogger.Exit(1)
 				},
 			}
 			// add this command stub to the tree and descend
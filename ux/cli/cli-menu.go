@@ -41,7 +41,7 @@ func ShowCliMenu(menu *ux.MenuForm) (*ux.MenuForm, error) {
 	opts = append(opts, huh.NewOption("help", "help"))
 	selector := huh.NewSelect[string]()
 	selector.
-		Title(fmt.Sprintf("%s (clog@%s)", parentMenu.Name, vStr)).
+		Title(fmt.Sprintf("%s (clog@%s)%s", parentMenu.Name, vStr, config.ProfileTag())).
 		Options(opts...).
 		Value(&selected)
 
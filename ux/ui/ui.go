@@ -4,11 +4,11 @@ package ui
 import (
 	"fmt"
 	"github.com/mrmxf/clog/config"
+	"github.com/mrmxf/clog/slogger"
 	"github.com/mrmxf/clog/ux"
 	"github.com/mrmxf/clog/ux/cli"
 	"github.com/spf13/cobra"
 	"log/slog"
-	"os"
 )
 
 var mode = ux.CLI
@@ -36,7 +36,7 @@ func matchMenuForm(theCmd *cobra.Command, menu *ux.MenuForm) (*ux.MenuForm, erro
 		_, err := fmt.Sscanf(theCmd.Annotations["menu-id"], "%d", &cmdId)
 		if err != nil {
 			slog.Error("fatal error parsing menus in matchMenuForm")
-			os.Exit(1)
+			slogger.Exit(1)
 		}
 	}
 	if cmdId == menu.Id {